@@ -0,0 +1,452 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Compatible HTTP APIs for captured messages, so test suites written against MailCatcher or
+// MailHog can be pointed at Mailrouter unchanged.
+//
+// MailCatcher:
+//   GET    /messages                      List messages
+//   DELETE /messages                      Delete all messages
+//   GET    /messages/:id.json             Message details
+//   GET    /messages/:id.plain|html|source|eml
+//   GET    /messages/:id/parts/:cid       Download an attachment
+//   DELETE /messages/:id                  Delete a message
+//
+// MailHog:
+//   GET    /api/v1/messages               List messages
+//   DELETE /api/v1/messages               Delete all messages
+//   GET    /api/v1/messages/:id           Message details
+//   DELETE /api/v1/messages/:id           Delete a message
+//   GET    /api/v1/messages/:id/download  Download the raw message
+//   GET    /api/v2/messages?start=&limit= List messages, paged
+//   GET    /api/v2/search?kind=&query=    Search messages by from, to or containing
+
+// Default page size for the MailHog v2 API.
+const MailHogLimit = 50
+
+// A message summary in the MailCatcher message list.
+type CatcherSummary struct {
+	Id         int      `json:"id"`
+	Sender     string   `json:"sender"`
+	Recipients []string `json:"recipients"`
+	Subject    string   `json:"subject"`
+	Size       string   `json:"size"`
+	CreatedAt  string   `json:"created_at"`
+}
+
+// A message in detail in the MailCatcher API.
+type CatcherMessage struct {
+	CatcherSummary
+	Type        string              `json:"type"`
+	Formats     []string            `json:"formats"`
+	Attachments []CatcherAttachment `json:"attachments"`
+}
+
+type CatcherAttachment struct {
+	Cid      string `json:"cid"`
+	Type     string `json:"type"`
+	Filename string `json:"filename"`
+	Size     int    `json:"size"`
+	Href     string `json:"href"`
+}
+
+// A message in the MailHog API.
+type HogMessage struct {
+	ID      string
+	From    *HogPath
+	To      []*HogPath
+	Content *HogContent
+	Created time.Time
+	MIME    *HogMIME
+	Raw     *HogRaw
+}
+
+type HogPath struct {
+	Relays  []string
+	Mailbox string
+	Domain  string
+	Params  string
+}
+
+type HogContent struct {
+	Headers map[string][]string
+	Body    string
+	Size    int
+	MIME    *HogMIME
+}
+
+type HogMIME struct {
+	Parts []*HogContent
+}
+
+type HogRaw struct {
+	From string
+	To   []string
+	Data string
+	Helo string
+}
+
+// A page of messages in the MailHog v2 API.
+type HogMessages struct {
+	Total int          `json:"total"`
+	Count int          `json:"count"`
+	Start int          `json:"start"`
+	Items []HogMessage `json:"items"`
+}
+
+// Handler for the MailCatcher message list at /messages, which has no trailing slash.
+func catcherListHandler(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case "GET":
+		summaries := []CatcherSummary{}
+		messages := captured.Search("")
+		// MailCatcher lists the oldest message first.
+		for i := len(messages) - 1; i >= 0; i-- {
+			summaries = append(summaries, CatcherMessageSummary(messages[i]))
+		}
+		WriteJSON(w, summaries)
+	case "DELETE":
+		captured.Clear()
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// Handler for MailCatcher requests for a single message under /messages/.
+func catcherMessageHandler(w http.ResponseWriter, req *http.Request) {
+	_, idStr, action := ParsePath(req.URL.Path)
+	format := ""
+	if i := strings.LastIndex(idStr, "."); i >= 0 {
+		idStr, format = idStr[:i], idStr[i+1:]
+	}
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.NotFound(w, req)
+		return
+	}
+
+	if req.Method == "DELETE" {
+		if !captured.Delete(id) {
+			http.NotFound(w, req)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	message, ok := captured.Get(id)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+	parsed, _ := message.Parse()
+	// Captured mail is untrusted, so browsers must not guess its content types.
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	if action == "parts" {
+		parts := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+		cid := ""
+		if len(parts) == 4 {
+			cid = parts[3]
+		}
+		for i, attachment := range parsed.Attachments {
+			if AttachmentCid(attachment, i) == cid {
+				filename := attachment.Filename
+				if filename == "" {
+					filename = fmt.Sprintf("attachment-%d", i+1)
+				}
+				w.Header().Set("Content-Type", attachment.ContentType)
+				w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+				w.Write(attachment.Data)
+				return
+			}
+		}
+		http.NotFound(w, req)
+		return
+	}
+
+	switch format {
+	case "json":
+		WriteJSON(w, CatcherMessageDetail(message, parsed))
+	case "plain":
+		if parsed.Text == "" {
+			http.NotFound(w, req)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write([]byte(parsed.Text))
+	case "html":
+		if parsed.HTML == "" {
+			http.NotFound(w, req)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Content-Security-Policy", SandboxPolicy)
+		w.Write([]byte(parsed.HTML))
+	case "source":
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write(message.Data)
+	case "eml":
+		w.Header().Set("Content-Type", "message/rfc822")
+		w.Write(message.Data)
+	default:
+		http.NotFound(w, req)
+	}
+}
+
+// Handler for the MailHog API under /api/.
+func hogHandler(w http.ResponseWriter, req *http.Request) {
+	parts := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	if len(parts) < 3 || parts[0] != "api" {
+		http.NotFound(w, req)
+		return
+	}
+	version, resource, id, action := parts[1], parts[2], "", ""
+	if len(parts) > 3 {
+		id = parts[3]
+	}
+	if len(parts) > 4 {
+		action = parts[4]
+	}
+
+	if version == "v2" && req.Method == "GET" && (resource == "messages" || resource == "search") && id == "" {
+		kind, query := "", ""
+		if resource == "search" {
+			kind, query = req.FormValue("kind"), strings.ToLower(req.FormValue("query"))
+		}
+		var items []HogMessage
+		for _, m := range captured.Search("") {
+			if resource == "search" && !HogMatches(m, kind, query) {
+				continue
+			}
+			items = append(items, HogMessageFrom(m))
+		}
+		start, _ := strconv.Atoi(req.FormValue("start"))
+		limit, err := strconv.Atoi(req.FormValue("limit"))
+		if err != nil || limit <= 0 {
+			limit = MailHogLimit
+		}
+		WriteJSON(w, HogPage(items, start, limit))
+		return
+	}
+
+	if version != "v1" || resource != "messages" {
+		http.NotFound(w, req)
+		return
+	}
+	if id == "" {
+		switch req.Method {
+		case "GET":
+			items := []HogMessage{}
+			for _, m := range captured.Search("") {
+				items = append(items, HogMessageFrom(m))
+			}
+			WriteJSON(w, items)
+		case "DELETE":
+			captured.Clear()
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	n, err := strconv.Atoi(id)
+	if err != nil {
+		http.NotFound(w, req)
+		return
+	}
+	if req.Method == "DELETE" {
+		if !captured.Delete(n) {
+			http.NotFound(w, req)
+		}
+		return
+	}
+	message, ok := captured.Get(n)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+	switch action {
+	case "":
+		WriteJSON(w, HogMessageFrom(message))
+	case "download":
+		w.Header().Set("Content-Type", "message/rfc822")
+		w.Header().Set("Content-Disposition", "attachment; filename=\""+id+".eml\"")
+		w.Write(message.Data)
+	default:
+		http.NotFound(w, req)
+	}
+}
+
+// Summarise a captured message as MailCatcher does.
+func CatcherMessageSummary(m CapturedMessage) CatcherSummary {
+	recipients := make([]string, len(m.To))
+	for i, to := range m.To {
+		recipients[i] = "<" + to + ">"
+	}
+	return CatcherSummary{
+		Id:         m.Id,
+		Sender:     "<" + m.From + ">",
+		Recipients: recipients,
+		Subject:    m.Subject,
+		Size:       strconv.Itoa(len(m.Data)),
+		CreatedAt:  m.Received.UTC().Format(time.RFC3339),
+	}
+}
+
+// Describe a captured message in detail as MailCatcher does.
+func CatcherMessageDetail(m CapturedMessage, parsed ParsedMessage) CatcherMessage {
+	detail := CatcherMessage{
+		CatcherSummary: CatcherMessageSummary(m),
+		Type:           "text/plain",
+		Formats:        []string{"source"},
+		Attachments:    []CatcherAttachment{},
+	}
+	if mediaType, _, err := mime.ParseMediaType(parsed.Header.Get("Content-Type")); err == nil {
+		detail.Type = mediaType
+	}
+	if parsed.HTML != "" {
+		detail.Formats = append(detail.Formats, "html")
+	}
+	if parsed.Text != "" {
+		detail.Formats = append(detail.Formats, "plain")
+	}
+	for i, a := range parsed.Attachments {
+		cid := AttachmentCid(a, i)
+		detail.Attachments = append(detail.Attachments, CatcherAttachment{
+			Cid:      cid,
+			Type:     a.ContentType,
+			Filename: a.Filename,
+			Size:     len(a.Data),
+			Href:     "/messages/" + strconv.Itoa(m.Id) + "/parts/" + cid,
+		})
+	}
+	return detail
+}
+
+// Identify an attachment by its Content-ID, or by its position if it has none.
+func AttachmentCid(a Attachment, index int) string {
+	if a.ContentID != "" {
+		return a.ContentID
+	}
+	return "part-" + strconv.Itoa(index+1)
+}
+
+// Convert a captured message to the MailHog message format.
+func HogMessageFrom(m CapturedMessage) HogMessage {
+	to := make([]*HogPath, len(m.To))
+	for i, addr := range m.To {
+		to[i] = HogPathFrom(addr)
+	}
+	headers, body := SplitMessage(m.Data)
+	content := &HogContent{Headers: map[string][]string{}, Body: string(body), Size: len(m.Data)}
+	for _, field := range headers {
+		name := HeaderName(field)
+		content.Headers[name] = append(content.Headers[name], strings.TrimSpace(HeaderValue(field)))
+	}
+
+	return HogMessage{
+		ID:      strconv.Itoa(m.Id),
+		From:    HogPathFrom(m.From),
+		To:      to,
+		Content: content,
+		Created: m.Received,
+		MIME:    HogParts(content.Headers, body),
+		Raw:     &HogRaw{From: m.From, To: m.To, Data: string(m.Data), Helo: m.Client.Helo},
+	}
+}
+
+// Split an address into the mailbox and domain parts used by MailHog.
+func HogPathFrom(addr string) *HogPath {
+	path := &HogPath{Relays: []string{}, Mailbox: addr}
+	if i := strings.LastIndex(addr, "@"); i >= 0 {
+		path.Mailbox, path.Domain = addr[:i], addr[i+1:]
+	}
+	return path
+}
+
+// Split a multipart body into its top level parts, or return nil if it is not multipart.
+func HogParts(headers map[string][]string, body []byte) *HogMIME {
+	contentType := ""
+	for name, values := range headers {
+		if strings.EqualFold(name, "Content-Type") && len(values) > 0 {
+			contentType = values[0]
+		}
+	}
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || !strings.HasPrefix(mediaType, "multipart/") {
+		return nil
+	}
+
+	parts := &HogMIME{Parts: []*HogContent{}}
+	reader := multipart.NewReader(strings.NewReader(string(body)), params["boundary"])
+	for {
+		part, err := reader.NextRawPart()
+		if err != nil {
+			break
+		}
+		data, _ := io.ReadAll(part)
+		content := &HogContent{Headers: map[string][]string(part.Header), Body: string(data), Size: len(data)}
+		content.MIME = HogParts(content.Headers, data)
+		parts.Parts = append(parts.Parts, content)
+	}
+	return parts
+}
+
+// Report whether a message matches a MailHog search of kind from, to or containing.
+func HogMatches(m CapturedMessage, kind string, query string) bool {
+	switch kind {
+	case "from":
+		return strings.Contains(strings.ToLower(m.From), query) || headerContains(m, "From", query)
+	case "to":
+		return strings.Contains(strings.ToLower(strings.Join(m.To, ",")), query) || headerContains(m, "To", query) || headerContains(m, "Cc", query)
+	case "containing":
+		return m.Matches(query)
+	}
+	return false
+}
+
+func headerContains(m CapturedMessage, name string, query string) bool {
+	msg, err := mail.ReadMessage(strings.NewReader(string(m.Data)))
+	if err != nil {
+		return false
+	}
+	return strings.Contains(strings.ToLower(msg.Header.Get(name)), query)
+}
+
+// Return a page of messages for the MailHog v2 API.
+func HogPage(items []HogMessage, start int, limit int) HogMessages {
+	page := HogMessages{Total: len(items), Start: start, Items: []HogMessage{}}
+	if start < 0 {
+		page.Start, start = 0, 0
+	}
+	if start < len(items) {
+		// Compare with the items left rather than adding to start, which could overflow.
+		end := start + limit
+		if limit > len(items)-start {
+			end = len(items)
+		}
+		page.Items = items[start:end]
+	}
+	page.Count = len(page.Items)
+	return page
+}
+
+// Write a value as a JSON response.
+func WriteJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/mhale/mailrouter/smtpd"
+)
+
+func useTestCapture(t *testing.T) {
+	saved := captured.Messages
+	captured.Messages = nil
+	t.Cleanup(func() { captured.Messages = saved })
+}
+
+func apiRequest(t *testing.T, handler http.HandlerFunc, method string, path string, v interface{}) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest(method, path, nil))
+	if v != nil {
+		if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+			t.Fatalf("%s %s: %v in %q", method, path, err, w.Body.String())
+		}
+	}
+	return w
+}
+
+func TestCatcherAPI(t *testing.T) {
+	useTestCapture(t)
+	first := captured.Add("Capture", Message{From: "a@example.com", To: []string{"b@example.com"}, Subject: "One", Data: []byte("Subject: One\r\n\r\none\r\n")})
+	second := captured.Add("Capture", Message{From: "c@example.com", To: []string{"d@example.com"}, Subject: "Two", Data: []byte(testMultipart)})
+
+	var list []CatcherSummary
+	apiRequest(t, catcherListHandler, "GET", "/messages", &list)
+	if len(list) != 2 || list[0].Id != first.Id || list[1].Sender != "<c@example.com>" || list[1].Recipients[0] != "<d@example.com>" {
+		t.Errorf("GET /messages = %+v, want two messages, oldest first", list)
+	}
+
+	var detail CatcherMessage
+	path := "/messages/" + strconv.Itoa(second.Id)
+	apiRequest(t, messageHandler, "GET", path+".json", &detail)
+	if detail.Type != "multipart/mixed" || len(detail.Formats) != 3 || len(detail.Attachments) != 1 {
+		t.Errorf("GET %s.json = %+v, want multipart/mixed with 3 formats and 1 attachment", path, detail)
+	}
+
+	tests := []struct {
+		path string
+		code int
+		body string
+	}{
+		{path + ".plain", 200, "Café report"},
+		{path + ".html", 200, "<p>Report</p>"},
+		{path + ".source", 200, testMultipart},
+		{path + ".eml", 200, testMultipart},
+		{detail.Attachments[0].Href, 200, "%PDF-1.4\n"},
+		{path + "/parts/missing", 404, ""},
+		{"/messages/" + strconv.Itoa(first.Id) + ".html", 404, ""},
+		{"/messages/999.json", 404, ""},
+	}
+	for _, tt := range tests {
+		w := apiRequest(t, messageHandler, "GET", tt.path, nil)
+		if w.Code != tt.code || (tt.code == 200 && w.Body.String() != tt.body) {
+			t.Errorf("GET %s = %d %q, want %d %q", tt.path, w.Code, w.Body.String(), tt.code, tt.body)
+		}
+		if tt.code == 200 && w.Header().Get("X-Content-Type-Options") != "nosniff" {
+			t.Errorf("GET %s sent no X-Content-Type-Options: nosniff header", tt.path)
+		}
+	}
+	if w := apiRequest(t, messageHandler, "GET", path+".html", nil); w.Header().Get("Content-Security-Policy") != SandboxPolicy {
+		t.Errorf("GET %s.html Content-Security-Policy = %q, want %q", path, w.Header().Get("Content-Security-Policy"), SandboxPolicy)
+	}
+	if w := apiRequest(t, messageHandler, "GET", detail.Attachments[0].Href, nil); !strings.HasPrefix(w.Header().Get("Content-Disposition"), "attachment") {
+		t.Errorf("GET %s Content-Disposition = %q, want an attachment", detail.Attachments[0].Href, w.Header().Get("Content-Disposition"))
+	}
+
+	apiRequest(t, messageHandler, "DELETE", "/messages/"+strconv.Itoa(first.Id), nil)
+	if _, ok := captured.Get(first.Id); ok {
+		t.Errorf("DELETE /messages/%d did not delete the message", first.Id)
+	}
+	apiRequest(t, catcherListHandler, "DELETE", "/messages", nil)
+	if len(captured.Search("")) != 0 {
+		t.Errorf("DELETE /messages left %d messages", len(captured.Search("")))
+	}
+}
+
+func TestHogAPI(t *testing.T) {
+	useTestCapture(t)
+	first := captured.Add("Capture", Message{From: "a@example.com", To: []string{"b@example.com"}, Data: []byte("From: Alice <a@example.com>\r\nSubject: One\r\n\r\nfirst\r\n")})
+	second := captured.Add("Capture", Message{From: "c@example.com", To: []string{"d@example.com"}, Data: []byte(testMultipart), Client: smtpd.ClientInfo{Helo: "client.example.com"}})
+
+	var v1 []HogMessage
+	apiRequest(t, hogHandler, "GET", "/api/v1/messages", &v1)
+	if len(v1) != 2 || v1[0].ID != strconv.Itoa(second.Id) {
+		t.Fatalf("GET /api/v1/messages = %d messages, want 2, newest first", len(v1))
+	}
+	m := v1[0]
+	if m.From.Mailbox != "c" || m.From.Domain != "example.com" || m.To[0].Mailbox != "d" {
+		t.Errorf("message paths = %+v %+v, want c@example.com to d@example.com", m.From, m.To[0])
+	}
+	if m.Raw.Helo != "client.example.com" {
+		t.Errorf("message Raw.Helo = %q, want client.example.com", m.Raw.Helo)
+	}
+	if m.Content.Headers["Subject"][0] != "=?UTF-8?B?UmVwb3J0IOKAkyBKdW5l?=" || m.MIME == nil || len(m.MIME.Parts) != 2 || len(m.MIME.Parts[0].MIME.Parts) != 2 {
+		t.Errorf("message content = %+v, want headers and nested MIME parts", m.Content.Headers)
+	}
+
+	var page HogMessages
+	apiRequest(t, hogHandler, "GET", "/api/v2/messages?start=1&limit=5", &page)
+	if page.Total != 2 || page.Count != 1 || page.Start != 1 || page.Items[0].ID != strconv.Itoa(first.Id) {
+		t.Errorf("GET /api/v2/messages = %+v, want second page of one", page)
+	}
+	apiRequest(t, hogHandler, "GET", "/api/v2/messages?start=1&limit=9223372036854775807", &page)
+	if page.Count != 1 || page.Items[0].ID != strconv.Itoa(first.Id) {
+		t.Errorf("GET /api/v2/messages with the largest limit = %+v, want second page of one", page)
+	}
+
+	searches := []struct {
+		query string
+		ids   []string
+	}{
+		{"kind=from&query=alice", []string{strconv.Itoa(first.Id)}},
+		{"kind=to&query=D@EXAMPLE", []string{strconv.Itoa(second.Id)}},
+		{"kind=containing&query=first", []string{strconv.Itoa(first.Id)}},
+		{"kind=containing&query=nothing", nil},
+	}
+	for _, tt := range searches {
+		apiRequest(t, hogHandler, "GET", "/api/v2/search?"+tt.query, &page)
+		var ids []string
+		for _, item := range page.Items {
+			ids = append(ids, item.ID)
+		}
+		if len(ids) != len(tt.ids) || (len(ids) > 0 && ids[0] != tt.ids[0]) {
+			t.Errorf("GET /api/v2/search?%s = %v, want %v", tt.query, ids, tt.ids)
+		}
+	}
+
+	if w := apiRequest(t, hogHandler, "GET", "/api/v1/messages/"+strconv.Itoa(first.Id)+"/download", nil); w.Code != 200 || w.Header().Get("Content-Type") != "message/rfc822" {
+		t.Errorf("download = %d %s, want 200 message/rfc822", w.Code, w.Header().Get("Content-Type"))
+	}
+	apiRequest(t, hogHandler, "DELETE", "/api/v1/messages/"+strconv.Itoa(first.Id), nil)
+	apiRequest(t, hogHandler, "GET", "/api/v1/messages", &v1)
+	if len(v1) != 1 {
+		t.Errorf("after DELETE one message, %d remain, want 1", len(v1))
+	}
+	apiRequest(t, hogHandler, "DELETE", "/api/v1/messages", nil)
+	if len(captured.Search("")) != 0 {
+		t.Errorf("DELETE /api/v1/messages left %d messages", len(captured.Search("")))
+	}
+}
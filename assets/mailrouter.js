@@ -16,6 +16,36 @@ $("#authentication").change(function() {
 	}
 });
 
+// Shows the form fields relevant to the selected route type. Fields for other types are disabled so they are
+// neither validated nor submitted.
+function showRouteType() {
+	var type = $("#type").val();
+	$(".route-type").each(function() {
+		var types = $(this).attr("data-types").split(" ");
+		var show = $.inArray(type, types) >= 0;
+		$(this).toggleClass("hidden", !show);
+		$(this).find("input, select, textarea").prop("disabled", !show);
+	});
+}
+$("#type").change(showRouteType);
+if ($("#type").length) {
+	showRouteType();
+}
+
+// Shows the route drop-down only for filters that deliver via a route.
+function showFilterAction() {
+	var action = $("#action").val();
+	$(".filter-action").each(function() {
+		var show = $.inArray(action, $(this).attr("data-actions").split(" ")) >= 0;
+		$(this).toggleClass("hidden", !show);
+		$(this).find("input, select").prop("disabled", !show);
+	});
+}
+$("#action").change(showFilterAction);
+if ($("#action").length) {
+	showFilterAction();
+}
+
 // Handles "data-method" on links such as:
 // <a href="/routes/b25f7ee5-b755-11e3-8126-4a5b3b8c74a2" data-method="delete" rel="nofollow" data-confirm="Are you sure?">Delete</a>
 $('[data-method]').click(function() {
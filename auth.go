@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/mail"
+	"strings"
+)
+
+// Authentication results as defined in RFC 8601 section 2.7.
+const (
+	AuthNone      = "none"
+	AuthPass      = "pass"
+	AuthFail      = "fail"
+	AuthSoftfail  = "softfail"
+	AuthNeutral   = "neutral"
+	AuthTempError = "temperror"
+	AuthPermError = "permerror"
+)
+
+// The results of SPF, DKIM and DMARC checks on a message.
+type AuthResults struct {
+	SPF         string
+	SPFDomain   string
+	DKIM        string
+	DKIMResults []DKIMResult
+	DMARC       string
+	DMARCPolicy string
+	FromDomain  string
+}
+
+// Report whether inbound mail should be authenticated.
+func (c *Config) AuthEnabled() bool {
+	return c.Options["VerifyAuthentication"] == "true"
+}
+
+// Run SPF, DKIM and DMARC checks on an incoming message.
+func Authenticate(originIP net.IP, from string, data []byte) AuthResults {
+	var ar AuthResults
+	ar.SPF, ar.SPFDomain = CheckSPF(originIP, from)
+	ar.DKIMResults = VerifyDKIM(data)
+	ar.DKIM = DKIMSummary(ar.DKIMResults)
+
+	// DMARC requires exactly one RFC 5322 From address.
+	headers, _ := SplitMessage(data)
+	var fromFields []string
+	for _, field := range headers {
+		if strings.EqualFold(HeaderName(field), "From") {
+			fromFields = append(fromFields, HeaderValue(field))
+		}
+	}
+	if len(fromFields) != 1 {
+		ar.DMARC = AuthPermError
+		if len(fromFields) == 0 {
+			ar.DMARC = AuthNone
+		}
+		return ar
+	}
+	addrs, err := mail.ParseAddressList(fromFields[0])
+	if err != nil || len(addrs) != 1 {
+		ar.DMARC = AuthPermError
+		return ar
+	}
+	address := addrs[0].Address
+	ar.FromDomain = strings.ToLower(address[strings.LastIndex(address, "@")+1:])
+	ar.DMARC, ar.DMARCPolicy = CheckDMARC(ar.FromDomain, ar.SPF, ar.SPFDomain, ar.DKIMResults)
+	return ar
+}
+
+// Format the results as the value of an Authentication-Results header (RFC 8601).
+func (ar AuthResults) Header(authservID string) string {
+	methods := []string{authservID}
+
+	spf := "spf=" + ar.SPF
+	if ar.SPFDomain != "" {
+		spf += " smtp.mailfrom=" + ar.SPFDomain
+	}
+	methods = append(methods, spf)
+
+	if len(ar.DKIMResults) == 0 {
+		methods = append(methods, "dkim="+AuthNone)
+	}
+	for _, r := range ar.DKIMResults {
+		dkim := "dkim=" + r.Result
+		if r.Reason != "" {
+			dkim += fmt.Sprintf(" (%s)", r.Reason)
+		}
+		if r.Domain != "" {
+			dkim += " header.d=" + r.Domain
+		}
+		if r.Selector != "" {
+			dkim += " header.s=" + r.Selector
+		}
+		methods = append(methods, dkim)
+	}
+
+	dmarc := "dmarc=" + ar.DMARC
+	if ar.DMARCPolicy != "" {
+		dmarc += fmt.Sprintf(" (p=%s)", ar.DMARCPolicy)
+	}
+	if ar.FromDomain != "" {
+		dmarc += " header.from=" + ar.FromDomain
+	}
+	methods = append(methods, dmarc)
+
+	return strings.Join(methods, ";\r\n\t")
+}
+
+// Add an Authentication-Results header to a message, first removing any that claim to be from this host.
+func (ar AuthResults) AddHeader(data []byte) []byte {
+	authservID := AuthservID()
+	headers, body := SplitMessage(data)
+	forged := false
+	kept := headers[:0:0]
+	for _, field := range headers {
+		if strings.EqualFold(HeaderName(field), "Authentication-Results") {
+			id := strings.TrimSpace(strings.SplitN(HeaderValue(field), ";", 2)[0])
+			if strings.EqualFold(id, authservID) {
+				forged = true
+				continue
+			}
+		}
+		kept = append(kept, field)
+	}
+	if forged {
+		data = []byte(strings.Join(kept, "\r\n") + "\r\n\r\n" + string(body))
+	}
+	return PrependHeader(data, "Authentication-Results", ar.Header(authservID))
+}
+
+// The identifier used in Authentication-Results headers added by this host.
+func AuthservID() string {
+	return LocalHostname()
+}
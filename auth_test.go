@@ -0,0 +1,275 @@
+package main
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+)
+
+// A Resolver that answers from fixed records, standing in for a test DNS server.
+type testResolver struct {
+	txt map[string][]string
+	mx  map[string][]*net.MX
+	ip  map[string][]net.IP
+	err map[string]error
+}
+
+func newTestResolver() *testResolver {
+	return &testResolver{
+		txt: map[string][]string{},
+		mx:  map[string][]*net.MX{},
+		ip:  map[string][]net.IP{},
+		err: map[string]error{},
+	}
+}
+
+func (tr *testResolver) lookup(name string, n int) error {
+	if err := tr.err[name]; err != nil {
+		return err
+	}
+	if n == 0 {
+		return &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	return nil
+}
+
+func (tr *testResolver) LookupTXT(name string) ([]string, error) {
+	return tr.txt[name], tr.lookup(name, len(tr.txt[name]))
+}
+
+func (tr *testResolver) LookupMX(name string) ([]*net.MX, error) {
+	return tr.mx[name], tr.lookup(name, len(tr.mx[name]))
+}
+
+func (tr *testResolver) LookupIP(host string) ([]net.IP, error) {
+	host = strings.TrimSuffix(host, ".")
+	return tr.ip[host], tr.lookup(host, len(tr.ip[host]))
+}
+
+// Install a test resolver for the duration of a test.
+func useTestResolver(t *testing.T) *testResolver {
+	tr := newTestResolver()
+	saved := resolver.Get()
+	resolver.Set(tr)
+	t.Cleanup(func() { resolver.Set(saved) })
+	return tr
+}
+
+func TestCheckSPF(t *testing.T) {
+	tr := useTestResolver(t)
+	tr.txt["example.com"] = []string{"v=spf1 ip4:192.0.2.0/24 ip6:2001:db8::/32 include:_spf.example.net a:relay.example.com/30 mx -all"}
+	tr.txt["_spf.example.net"] = []string{"v=spf1 ip4:198.51.100.7 ~all"}
+	tr.ip["relay.example.com"] = []net.IP{net.ParseIP("203.0.113.4")}
+	tr.mx["example.com"] = []*net.MX{{Host: "mx.example.com.", Pref: 10}}
+	tr.ip["mx.example.com"] = []net.IP{net.ParseIP("203.0.113.100")}
+	tr.txt["soft.example.org"] = []string{"v=spf1 ~all"}
+	tr.txt["neutral.example.org"] = []string{"v=spf1 ?all"}
+	tr.txt["empty.example.org"] = []string{"v=spf1"}
+	tr.txt["redirect.example.org"] = []string{"v=spf1 redirect=example.com"}
+	tr.txt["double.example.org"] = []string{"v=spf1 -all", "v=spf1 +all"}
+	tr.txt["other.example.org"] = []string{"google-site-verification=abc"}
+	tr.txt["macro.example.org"] = []string{"v=spf1 exists:%{ir}.%{l1r+-}._spf.%{d} -all"}
+	tr.ip["1.2.0.192.bob._spf.macro.example.org"] = []net.IP{net.ParseIP("127.0.0.2")}
+	tr.err["broken.example.org"] = &net.DNSError{Err: "server misbehaving", Name: "broken.example.org", IsTemporary: true}
+	loop := "v=spf1"
+	for i := 0; i < SPFMaxLookups+1; i++ {
+		loop += fmt.Sprintf(" include:loop%d.example.org", i)
+		tr.txt[fmt.Sprintf("loop%d.example.org", i)] = []string{"v=spf1 ?all"}
+	}
+	tr.txt["loop.example.org"] = []string{loop + " -all"}
+
+	tests := []struct {
+		ip     string
+		sender string
+		out    string
+	}{
+		{"192.0.2.1", "sender@example.com", AuthPass},
+		{"2001:db8::1", "sender@example.com", AuthPass},
+		{"198.51.100.7", "sender@example.com", AuthPass},
+		{"203.0.113.5", "sender@example.com", AuthPass},
+		{"203.0.113.100", "sender@example.com", AuthPass},
+		{"203.0.113.99", "sender@example.com", AuthFail},
+		{"192.0.2.1", "sender@soft.example.org", AuthSoftfail},
+		{"192.0.2.1", "sender@neutral.example.org", AuthNeutral},
+		{"192.0.2.1", "sender@empty.example.org", AuthNeutral},
+		{"192.0.2.1", "sender@redirect.example.org", AuthPass},
+		{"10.0.0.1", "sender@redirect.example.org", AuthFail},
+		{"192.0.2.1", "sender@double.example.org", AuthPermError},
+		{"192.0.2.1", "sender@other.example.org", AuthNone},
+		{"192.0.2.1", "sender@missing.example.org", AuthNone},
+		{"192.0.2.1", "bob@macro.example.org", AuthPass},
+		{"192.0.2.1", "alice@macro.example.org", AuthFail},
+		{"192.0.2.1", "sender@broken.example.org", AuthTempError},
+		{"192.0.2.1", "sender@loop.example.org", AuthPermError},
+		{"192.0.2.1", "", AuthNone},
+	}
+	for _, tt := range tests {
+		if x, _ := CheckSPF(net.ParseIP(tt.ip), tt.sender); x != tt.out {
+			t.Errorf("CheckSPF(%s, %s) = %s, want %s", tt.ip, tt.sender, x, tt.out)
+		}
+	}
+}
+
+// Sign a message with the given key, adding a DKIM-Signature header.
+func signDKIM(t *testing.T, message string, key crypto.Signer, algorithm string, canon string, domain string, selector string) string {
+	parts := strings.SplitN(canon, "/", 2)
+	headers, body := SplitMessage([]byte(message))
+	bh := sha256.Sum256(CanonicalBody(body, parts[1]))
+	field := fmt.Sprintf("DKIM-Signature: v=1; a=%s; c=%s; d=%s; s=%s;\r\n\th=From:To:Subject; bh=%s; b=",
+		algorithm, canon, domain, selector, base64.StdEncoding.EncodeToString(bh[:]))
+
+	h := sha256.New()
+	for _, name := range []string{"From", "To", "Subject"} {
+		for i := len(headers) - 1; i >= 0; i-- {
+			if HeaderName(headers[i]) == name {
+				h.Write([]byte(CanonicalHeader(headers[i], parts[0]) + "\r\n"))
+				break
+			}
+		}
+	}
+	h.Write([]byte(CanonicalHeader(field, parts[0])))
+	hashed := h.Sum(nil)
+
+	var sig []byte
+	var err error
+	if algorithm == "ed25519-sha256" {
+		sig, err = key.Sign(rand.Reader, hashed, crypto.Hash(0))
+	} else {
+		sig, err = key.Sign(rand.Reader, hashed, crypto.SHA256)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return field + base64.StdEncoding.EncodeToString(sig) + "\r\n" + message
+}
+
+const testMessage = "From: Sender <sender@example.com>\r\n" +
+	"To: recipient@example.net\r\n" +
+	"Subject:  Lorem   ipsum\r\n" +
+	"\r\n" +
+	"Lorem ipsum dolor sit amet,  \r\n" +
+	"consectetur adipiscing elit.\r\n" +
+	"\r\n"
+
+func TestVerifyDKIM(t *testing.T) {
+	tr := useTestResolver(t)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaPub, _ := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	tr.txt["rsa._domainkey.example.com"] = []string{"v=DKIM1; k=rsa; p=" + base64.StdEncoding.EncodeToString(rsaPub)}
+
+	edPub, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	tr.txt["ed._domainkey.example.com"] = []string{"v=DKIM1; k=ed25519; p=" + base64.StdEncoding.EncodeToString(edPub)}
+	tr.txt["revoked._domainkey.example.com"] = []string{"v=DKIM1; p="}
+
+	tests := []struct {
+		name    string
+		message string
+		out     string
+	}{
+		{"unsigned", testMessage, AuthNone},
+		{"rsa relaxed", signDKIM(t, testMessage, rsaKey, "rsa-sha256", "relaxed/relaxed", "example.com", "rsa"), AuthPass},
+		{"rsa simple", signDKIM(t, testMessage, rsaKey, "rsa-sha256", "simple/simple", "example.com", "rsa"), AuthPass},
+		{"ed25519", signDKIM(t, testMessage, edKey, "ed25519-sha256", "relaxed/simple", "example.com", "ed"), AuthPass},
+		{"bare LF", strings.Replace(signDKIM(t, testMessage, rsaKey, "rsa-sha256", "relaxed/relaxed", "example.com", "rsa"), "\r\n", "\n", -1), AuthPass},
+		{"relaxed whitespace", strings.Replace(signDKIM(t, testMessage, rsaKey, "rsa-sha256", "relaxed/relaxed", "example.com", "rsa"), "dolor sit", "dolor   sit", 1), AuthPass},
+		{"simple whitespace", strings.Replace(signDKIM(t, testMessage, rsaKey, "rsa-sha256", "simple/simple", "example.com", "rsa"), "dolor sit", "dolor   sit", 1), AuthFail},
+		{"body changed", strings.Replace(signDKIM(t, testMessage, rsaKey, "rsa-sha256", "relaxed/relaxed", "example.com", "rsa"), "elit", "elite", 1), AuthFail},
+		{"header changed", strings.Replace(signDKIM(t, testMessage, rsaKey, "rsa-sha256", "relaxed/relaxed", "example.com", "rsa"), "Lorem   ipsum\r\n", "Dolor\r\n", 1), AuthFail},
+		{"wrong key", signDKIM(t, testMessage, rsaKey, "rsa-sha256", "relaxed/relaxed", "example.com", "ed"), AuthPermError},
+		{"revoked key", signDKIM(t, testMessage, rsaKey, "rsa-sha256", "relaxed/relaxed", "example.com", "revoked"), AuthPermError},
+		{"missing key", signDKIM(t, testMessage, rsaKey, "rsa-sha256", "relaxed/relaxed", "example.org", "rsa"), AuthPermError},
+	}
+	for _, tt := range tests {
+		results := VerifyDKIM([]byte(tt.message))
+		if x := DKIMSummary(results); x != tt.out {
+			t.Errorf("VerifyDKIM(%s) = %s (%v), want %s", tt.name, x, results, tt.out)
+		}
+	}
+}
+
+func TestCheckDMARC(t *testing.T) {
+	tr := useTestResolver(t)
+	tr.txt["_dmarc.example.com"] = []string{"v=DMARC1; p=reject; sp=quarantine"}
+	tr.txt["_dmarc.strict.example.org"] = []string{"v=DMARC1; p=none; aspf=s; adkim=s"}
+	tr.txt["_dmarc.bad.example.org"] = []string{"v=DMARC1; p=maybe"}
+
+	pass := []DKIMResult{{Domain: "mail.example.com", Result: AuthPass}}
+	failed := []DKIMResult{{Domain: "example.com", Result: AuthFail}}
+	tests := []struct {
+		fromDomain string
+		spf        string
+		spfDomain  string
+		dkim       []DKIMResult
+		out        string
+		policy     string
+	}{
+		{"example.com", AuthPass, "example.com", nil, AuthPass, "reject"},
+		{"example.com", AuthPass, "bounces.example.com", nil, AuthPass, "reject"},
+		{"example.com", AuthPass, "example.net", nil, AuthFail, "reject"},
+		{"example.com", AuthFail, "example.com", pass, AuthPass, "reject"},
+		{"example.com", AuthFail, "example.com", failed, AuthFail, "reject"},
+		{"news.example.com", AuthNone, "", nil, AuthFail, "quarantine"},
+		{"strict.example.org", AuthPass, "example.org", nil, AuthFail, "none"},
+		{"strict.example.org", AuthPass, "strict.example.org", nil, AuthPass, "none"},
+		{"bad.example.org", AuthPass, "bad.example.org", nil, AuthPermError, ""},
+		{"example.net", AuthPass, "example.net", nil, AuthNone, ""},
+	}
+	for _, tt := range tests {
+		if x, policy := CheckDMARC(tt.fromDomain, tt.spf, tt.spfDomain, tt.dkim); x != tt.out || policy != tt.policy {
+			t.Errorf("CheckDMARC(%s, %s, %s, %v) = %s, %s, want %s, %s", tt.fromDomain, tt.spf, tt.spfDomain, tt.dkim, x, policy, tt.out, tt.policy)
+		}
+	}
+}
+
+func TestOrganizationalDomain(t *testing.T) {
+	tests := []struct {
+		domain string
+		out    string
+	}{
+		{"example.com", "example.com"},
+		{"mail.example.com", "example.com"},
+		{"a.b.example.com.", "example.com"},
+		{"mail.example.co.uk", "example.co.uk"},
+		{"example.com.au", "example.com.au"},
+		{"localhost", "localhost"},
+	}
+	for _, tt := range tests {
+		if x := OrganizationalDomain(tt.domain); x != tt.out {
+			t.Errorf("OrganizationalDomain(%s) = %s, want %s", tt.domain, x, tt.out)
+		}
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	tr := useTestResolver(t)
+	tr.txt["example.com"] = []string{"v=spf1 ip4:192.0.2.1 -all"}
+	tr.txt["_dmarc.example.com"] = []string{"v=DMARC1; p=reject"}
+
+	ar := Authenticate(net.ParseIP("192.0.2.1"), "bounce@example.com", []byte(testMessage))
+	if ar.SPF != AuthPass || ar.DKIM != AuthNone || ar.DMARC != AuthPass || ar.FromDomain != "example.com" {
+		t.Errorf("Authenticate() = %+v, want spf=pass dkim=none dmarc=pass", ar)
+	}
+
+	// Forged results claiming to be from this host are replaced.
+	forged := "Authentication-Results: " + AuthservID() + "; spf=pass\r\n" + testMessage
+	data := ar.AddHeader([]byte(forged))
+	if n := strings.Count(string(data), "Authentication-Results:"); n != 1 {
+		t.Errorf("AddHeader() left %d Authentication-Results headers, want 1", n)
+	}
+	want := "Authentication-Results: " + AuthservID() + ";\r\n\tspf=pass smtp.mailfrom=example.com;\r\n\tdkim=none;\r\n\tdmarc=pass (p=reject) header.from=example.com\r\n"
+	if !strings.HasPrefix(string(data), want) {
+		t.Errorf("AddHeader() = %q, want prefix %q", data, want)
+	}
+}
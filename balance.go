@@ -0,0 +1,105 @@
+package main
+
+import (
+	"sync"
+)
+
+// Delivery counts for a member of a route group.
+type MemberCounts struct {
+	Sent        int
+	Failed      int
+	Outstanding int // Deliveries in progress
+	current     int // Running weight for smooth weighted round robin
+}
+
+// Delivery counts for the members of all route groups, keyed by group and member route ID.
+type GroupStats struct {
+	sync.Mutex
+	members map[string]*MemberCounts
+}
+
+var groupStats = GroupStats{members: map[string]*MemberCounts{}}
+
+// Return the counts for a member, creating them if needed. The caller must hold the lock.
+func (gs *GroupStats) counts(groupId string, memberId string) *MemberCounts {
+	key := groupId + "/" + memberId
+	counts, exists := gs.members[key]
+	if !exists {
+		counts = &MemberCounts{}
+		gs.members[key] = counts
+	}
+	return counts
+}
+
+// Return a copy of the counts for a member.
+func (gs *GroupStats) Get(groupId string, memberId string) MemberCounts {
+	gs.Lock()
+	defer gs.Unlock()
+	return *gs.counts(groupId, memberId)
+}
+
+// Record the start of a delivery attempt via a member.
+func (gs *GroupStats) Start(groupId string, memberId string) {
+	gs.Lock()
+	defer gs.Unlock()
+	gs.counts(groupId, memberId).Outstanding++
+}
+
+// Record the outcome of a delivery attempt via a member.
+func (gs *GroupStats) Finish(groupId string, memberId string, err error) {
+	gs.Lock()
+	defer gs.Unlock()
+	counts := gs.counts(groupId, memberId)
+	counts.Outstanding--
+	if err != nil {
+		counts.Failed++
+	} else {
+		counts.Sent++
+	}
+}
+
+// Choose the next member of a balance group, skipping members already tried and members with zero weight.
+func (gs *GroupStats) Pick(group Route, tried map[string]bool) (RouteMember, bool) {
+	gs.Lock()
+	defer gs.Unlock()
+
+	var candidates []RouteMember
+	for _, member := range group.SortedMembers() {
+		if member.Weight > 0 && !tried[member.RouteId] {
+			candidates = append(candidates, member)
+		}
+	}
+	if len(candidates) == 0 {
+		return RouteMember{}, false
+	}
+
+	if group.Strategy == BalanceLeastOutstanding {
+		// Pick the member with the fewest deliveries in progress relative to its weight.
+		// Compare outstanding/weight by cross-multiplying to avoid division.
+		best := candidates[0]
+		bestCounts := gs.counts(group.Id, best.RouteId)
+		for _, member := range candidates[1:] {
+			counts := gs.counts(group.Id, member.RouteId)
+			if counts.Outstanding*best.Weight < bestCounts.Outstanding*member.Weight {
+				best, bestCounts = member, counts
+			}
+		}
+		return best, true
+	}
+
+	// Smooth weighted round robin: every member gains its weight, the leader is picked and
+	// pays back the total, which interleaves members in proportion to their weights.
+	total := 0
+	var best RouteMember
+	var bestCounts *MemberCounts
+	for _, member := range candidates {
+		counts := gs.counts(group.Id, member.RouteId)
+		counts.current += member.Weight
+		total += member.Weight
+		if bestCounts == nil || counts.current > bestCounts.current {
+			best, bestCounts = member, counts
+		}
+	}
+	bestCounts.current -= total
+	return best, true
+}
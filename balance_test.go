@@ -0,0 +1,97 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestGroupStatsPickRoundRobin(t *testing.T) {
+	gs := GroupStats{members: map[string]*MemberCounts{}}
+	group := Route{Id: "group", Type: RouteBalance, Strategy: BalanceRoundRobin, Members: []RouteMember{
+		{RouteId: "a", Order: 1, Weight: 3},
+		{RouteId: "b", Order: 2, Weight: 1},
+		{RouteId: "c", Order: 3, Weight: 0},
+	}}
+
+	picks := map[string]int{}
+	sequence := ""
+	for i := 0; i < 8; i++ {
+		member, ok := gs.Pick(group, nil)
+		if !ok {
+			t.Fatalf("Pick() found no member")
+		}
+		picks[member.RouteId]++
+		sequence += member.RouteId
+	}
+	if picks["a"] != 6 || picks["b"] != 2 || picks["c"] != 0 {
+		t.Errorf("Pick() distribution = %v, want a:6 b:2 c:0", picks)
+	}
+	// Smooth weighted round robin interleaves rather than sending bursts to one member.
+	if sequence != "aabaaaba" {
+		t.Errorf("Pick() sequence = %s, want aabaaaba", sequence)
+	}
+
+	// Members already tried are skipped, and a group with no eligible members yields nothing.
+	if member, _ := gs.Pick(group, map[string]bool{"a": true}); member.RouteId != "b" {
+		t.Errorf("Pick() with a tried = %s, want b", member.RouteId)
+	}
+	if _, ok := gs.Pick(group, map[string]bool{"a": true, "b": true}); ok {
+		t.Errorf("Pick() with all tried found a member, want none")
+	}
+}
+
+func TestGroupStatsPickLeastOutstanding(t *testing.T) {
+	gs := GroupStats{members: map[string]*MemberCounts{}}
+	group := Route{Id: "group", Type: RouteBalance, Strategy: BalanceLeastOutstanding, Members: []RouteMember{
+		{RouteId: "a", Order: 1, Weight: 2},
+		{RouteId: "b", Order: 2, Weight: 1},
+	}}
+
+	tests := []struct {
+		outstandingA int
+		outstandingB int
+		out          string
+	}{
+		{0, 0, "a"},
+		{1, 0, "b"},
+		{1, 1, "a"},
+		{2, 1, "a"},
+		{3, 1, "b"},
+	}
+	for _, tt := range tests {
+		gs.counts("group", "a").Outstanding = tt.outstandingA
+		gs.counts("group", "b").Outstanding = tt.outstandingB
+		if member, _ := gs.Pick(group, nil); member.RouteId != tt.out {
+			t.Errorf("Pick() with outstanding a:%d b:%d = %s, want %s", tt.outstandingA, tt.outstandingB, member.RouteId, tt.out)
+		}
+	}
+}
+
+func TestDeliverBalance(t *testing.T) {
+	a := newTestSMTPServer(t, "250 2.0.0 queued")
+	b := newTestSMTPServer(t, "250 2.0.0 queued")
+	drained := newTestSMTPServer(t, "250 2.0.0 queued")
+	busy := newTestSMTPServer(t, "421 4.3.2 shutting down")
+	useTestRoutes(t, a.route("a"), b.route("b"), drained.route("drained"), busy.route("busy"))
+
+	group := Route{Id: "balance-test", Name: "balance", Type: RouteBalance, Members: []RouteMember{
+		{RouteId: "a", Weight: 1},
+		{RouteId: "b", Weight: 1},
+		{RouteId: "drained", Weight: 0},
+		{RouteId: "busy", Weight: 1},
+	}}
+	data := []byte("Subject: test\r\n\r\ntest\r\n")
+	for i := 0; i < 6; i++ {
+		if _, err := Deliver(group, Message{From: "sender@example.com", To: []string{"recipient@example.com"}, Data: data}); err != nil {
+			t.Errorf("Deliver() = %v, want success", err)
+		}
+	}
+	if a.received() != 3 || b.received() != 3 || drained.received() != 0 {
+		t.Errorf("received a:%d b:%d drained:%d, want a:3 b:3 drained:0", a.received(), b.received(), drained.received())
+	}
+	if counts := groupStats.Get(group.Id, "busy"); counts.Failed != 2 || counts.Outstanding != 0 {
+		t.Errorf("busy member counts = %+v, want 2 failed, 0 outstanding", counts)
+	}
+	if counts := groupStats.Get(group.Id, "a"); counts.Sent != 3 {
+		t.Errorf("a member counts = %+v, want 3 sent", counts)
+	}
+}
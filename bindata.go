@@ -7,6 +7,8 @@
 // assets/mailrouter.js
 // views/filters.html
 // views/index.html
+// views/messages.html
+// views/quarantine.html
 // views/routes.html
 // DO NOT EDIT!
 
@@ -135,7 +137,7 @@ func assetsJqueryMinJs() (*asset, error) {
 	return a, nil
 }
 
-var _assetsMailrouterCss = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x6c\x52\xcd\x8e\xd3\x40\x0c\xbe\xe7\x29\x2c\x55\x48\x50\x91\x6d\x17\x76\x01\x85\x0b\x2c\x07\x4e\x2b\x21\xf5\x09\x9c\x8c\x93\x58\x4c\xe2\x68\xc6\xe9\x8f\xd0\xbe\x3b\x9e\xb4\x25\x29\xda\xdb\xc8\xf3\xfd\xf9\x67\xb3\xce\x60\x0d\x4f\x18\x09\xa2\x86\xb1\xd2\x31\x10\xe4\x50\xc9\xc0\xe4\x80\x7a\xe5\x40\xfe\x04\x75\x90\x0e\x9e\x44\xd4\x40\x38\xc0\x77\xd7\x71\x0f\xda\x52\x47\x46\xdf\x64\xd9\x66\x0d\xcf\xb2\x27\x70\x72\xe8\x8d\xdc\xab\x31\xa1\xa4\x0a\x47\x13\x3e\x10\xb4\x68\x9f\x08\x35\x1f\x4d\xb5\xc7\x7d\x89\xc1\xe8\xa8\xc0\x11\x1e\xb7\xc3\x11\x14\xbd\x4f\x4a\xa5\xb8\x13\xfc\xc9\x00\x06\x74\x8e\xfb\x26\x57\x19\x8a\x09\xf2\x35\x7b\xc9\x92\x51\xca\xfb\xd3\x4b\x89\x1e\x0c\x92\x4b\x1f\xcf\x11\xee\xe2\x58\xe6\x2d\xa1\xa3\x70\x23\x50\x8a\xaa\x74\x05\xdc\x4f\x1a\x00\xa5\x04\x83\xcc\x65\x33\x8f\xe2\xd9\xc1\x8a\x88\x96\x26\xcf\xc8\xff\x7a\xb9\x58\x74\xa9\xb4\x10\x2f\xe0\xc3\x25\xd9\xb7\x8e\x1c\x23\xbc\xb5\xb9\xe4\x07\x76\xda\x16\xf0\xf9\xd3\x97\xe1\xf8\x6e\x82\xcf\xc4\x39\x57\xe0\xa6\xd5\x02\x1e\x2e\xb1\xe6\x0f\x4f\xf5\xa2\xfe\x62\xe2\x67\xfa\xdd\x80\x0d\x2d\x3b\xec\x30\x34\x66\x37\x4d\x68\xbb\x4c\xfe\xcb\x63\x45\xad\xf8\x04\x74\x18\xdb\x52\x30\x38\x60\x47\x78\x9d\xd5\x30\x23\xe2\x52\xeb\x3a\x95\x8f\x17\x77\xa5\xa3\xe6\xe8\xb9\xe9\x0b\xa8\x6c\x0e\x14\x92\xcf\x2d\xbd\x7d\x78\x4d\x61\xfb\x3f\xf0\x35\xd0\x75\x7a\x37\x38\xee\x9a\x09\xeb\x38\x5a\xf9\x54\x00\xf7\x9e\x7b\xca\x4b\x2f\xd5\xef\xc5\x06\x03\x3a\x1e\x63\x3a\x8e\x37\x53\xf3\x76\x83\x3b\xc5\xa0\x20\x75\xda\x9d\x0f\x32\x5a\x5e\x88\x03\x55\x5c\x73\x05\x3f\x76\xbb\xd4\xfc\xaa\x66\xaf\x29\xb7\xba\xf7\xb0\x9a\x40\xe9\x3d\x59\xee\x29\x28\x57\xe8\xaf\x2d\x77\xec\x9c\xa7\xe4\xd9\xd2\x79\x5d\x8f\xf7\x97\x4b\x5c\x0d\x62\x56\x89\x74\x5d\xb7\xf5\x62\x1f\x7f\x03\x00\x00\xff\xff\x70\x61\x70\x7e\x51\x03\x00\x00")
+var _assetsMailrouterCss = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x02\xff\x6c\x53\xcb\x6e\xdb\x40\x0c\xbc\xeb\x2b\x08\x18\x01\x5a\x23\x6b\x2b\x6d\xd2\x16\xea\xa5\x4d\x0f\x3d\x05\x28\x90\x2f\xa0\xb4\x94\x45\x74\x5f\xd8\xa5\x2c\x05\x85\xff\xbd\x58\x59\x8a\xe5\x36\xb7\x05\x35\x9c\x19\x72\xa8\xfd\xb6\x80\x2d\x3c\x62\x22\x48\x12\xfb\x46\xfa\x48\xa0\xa0\xf1\x81\x49\x03\x39\xe1\x48\xe6\x05\xda\xe8\x2d\x3c\x7a\x2f\x49\x22\x06\xf8\xae\x2d\x3b\x90\x8e\x2c\x15\xb0\xdd\x17\xc5\x7e\x0b\x4f\xfe\x48\xa0\xfd\xe0\xa0\xf1\x4e\xc8\x09\xd4\xd4\x60\x9f\x08\x06\x82\x0e\x8f\x04\x08\x2d\x8f\xa4\xc1\xe1\xb1\xc6\x08\xd2\xa1\x00\x27\x78\x28\xc3\x08\x82\xc6\x64\xa6\xda\xeb\x17\xf8\x53\x00\x04\xd4\x9a\xdd\x41\x89\x0f\xd5\x04\xf9\x5a\x9c\x8a\x2c\x94\xfd\xfe\x34\xbe\x46\x03\xa8\xb5\xf2\x2e\x9d\x2d\xec\x52\x5f\xab\x8e\x50\x53\xbc\x22\xa8\xbd\x88\xb7\x15\xdc\x4d\x1c\x00\xb5\x8f\x9a\xe2\xa5\x1c\x46\x48\xde\xb0\x86\x0d\x11\xad\x45\x9e\x90\x5f\x67\x99\x25\x6c\x2e\xad\xc8\x2b\xf8\x30\x3b\xfb\x66\x49\x33\xc2\x3b\xcb\x4e\x0d\xac\xa5\xab\xe0\xf3\xa7\x2f\x61\x7c\x3f\xc1\x2f\x8d\x17\x5f\x91\x0f\x9d\x54\x70\x3f\xdb\xba\x7c\x30\xd4\xae\xea\xa7\xe2\x34\xeb\xee\x02\x1e\x68\x3d\xa1\xc5\x78\x60\x77\xde\x50\xb9\x76\xfe\xcb\x60\x43\x9d\x37\x19\xa8\x31\x75\xb5\xc7\xa8\x81\x35\xe1\xb2\xab\x70\x41\xa4\x35\xd7\xb2\x95\x8f\xb3\xba\xd0\x28\x0a\x0d\x1f\x5c\x05\x0d\x39\xa1\x98\x75\xae\xdb\xbb\xfb\xb7\x18\xca\x7f\x81\x6f\x81\x96\xed\x5d\xe1\xd8\x1e\x26\xac\xe6\x14\x0c\xbe\x54\xc0\xce\xb0\x23\x55\x1b\xdf\xfc\x5e\x25\x18\x51\x73\x9f\xf2\x71\xdc\x4c\xc3\xef\xb7\xf0\x2c\x18\x05\x7c\x9b\xb3\x33\xd1\xf7\x42\x11\x52\xa0\x86\x5b\x6e\xe0\xc7\xf3\x73\x1e\x7e\xd3\xb2\x91\xec\x5b\xf4\x2d\x6c\x26\x50\x7e\x4f\x92\x47\x8a\xc2\x0d\x9a\x65\x64\xcb\x5a\x1b\xca\x9a\x1d\x9d\xe3\x7a\xb8\x9b\x2f\x71\x13\x7c\x94\x5b\xd8\xd8\x51\xcd\x2f\x63\x25\x4c\xef\x89\x6a\x39\x82\x32\x8c\x19\xbe\xb3\x94\xd2\x94\x9f\x58\xb3\x06\xdc\x95\xe5\xcd\x95\x40\x79\x75\xa7\x4b\xb2\xaf\xfd\xaf\x3f\xc8\x3a\xfd\xe5\xb6\x2d\x8e\xea\x3f\x22\x7f\xa4\xd8\x1a\x3f\x54\x80\xbd\xf8\x5c\x19\x3a\x16\x52\x29\x60\x43\x15\x84\x48\x6a\x88\x18\xce\x53\xcd\x32\xb0\x13\xac\xd5\xf2\x23\xbf\x2d\x77\x2a\xfe\x0e\x00\xfd\xd3\x2c\x97\x3d\x04\x00\x00")
 
 func assetsMailrouterCssBytes() ([]byte, error) {
 	return bindataRead(
@@ -150,12 +152,12 @@ func assetsMailrouterCss() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "assets/mailrouter.css", size: 849, mode: os.FileMode(420), modTime: time.Unix(1428322835, 0)}
+	info := bindataFileInfo{name: "assets/mailrouter.css", size: 1085, mode: os.FileMode(420), modTime: time.Unix(1792380350, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
 
-var _assetsMailrouterJs = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\xc4\x54\x4d\x6f\xdb\x38\x10\x3d\xc7\xbf\x62\xc0\x0d\x20\x0a\x1b\x5b\xc8\x87\x37\x41\x62\x79\x51\x34\x87\xf6\x56\x20\xbd\x15\x41\x41\x89\x23\x8b\x08\x45\x0a\x24\x95\xd4\x08\xfc\xdf\x3b\x94\xac\xc4\x09\x9c\xb6\x39\xf5\x64\x63\xf4\xe6\xbd\xf9\x7a\xcc\x32\xb8\xa9\xed\x83\x07\xeb\xa0\x56\x12\x3d\x84\x1a\xa1\xf3\xe8\x8c\x68\x10\x84\x91\xd0\x0a\xef\x1f\xac\x93\x50\x29\xd4\xd2\xc3\x43\x8d\x06\x44\x47\x38\x13\x54\x29\x82\xb2\x06\xc2\xba\x45\x50\x1e\xca\x5a\x98\x15\xca\xd9\xe4\x90\xb3\x7f\x5e\x62\x58\x3a\x1b\xbe\xf2\xaa\x33\x65\x8c\xf0\x14\x1e\x27\x07\xaa\x02\x7e\xc8\x43\xad\x7c\x3a\xbb\x17\x9a\x82\x79\x0e\xac\x74\xa2\x69\xe4\x9c\xf5\x90\x83\xc8\x36\x96\x41\x3c\x22\x04\xc7\x59\xab\x45\x89\xb5\xd5\x12\x1d\x3b\x02\xe6\xb1\x74\x18\x58\x7a\xf5\x0a\x3f\xd5\xa2\x40\x4d\x59\x01\x7f\x04\xce\x6e\xde\x82\xad\x9c\xed\x5a\x82\x39\x6c\xec\x3d\x7e\xd4\x14\xe7\x8c\x46\x22\x31\x96\x2e\xa4\xdc\x86\x3c\x8d\xeb\x39\x7d\x9c\xd4\xfb\xd3\x37\x80\xda\xd3\xd0\xf6\xb6\x4f\xbd\x29\xf3\x8e\xe6\x9f\xbf\xff\xa6\xfd\x2f\x6f\x03\xff\xd2\x00\x5e\xb7\xb8\x9f\x68\xc8\xda\xa1\x19\x99\xff\xac\x8e\x5f\xa5\x6f\x26\x1b\xfa\x99\x90\x13\x3e\xd1\xb9\x6b\xb2\x00\x93\x22\x88\x69\x83\xa1\xb6\x92\x01\x9d\xb7\x56\xe6\xce\x83\xef\xca\x1a\x84\xbf\x8c\xd0\x85\x80\xda\x61\x95\xb3\x8c\xe4\x02\xfa\xac\x38\x99\x57\xe7\x88\xf3\x69\x71\x3e\x9f\x4f\x8f\x8f\xf1\x74\x7a\x71\x7c\xf2\xdf\xf4\x4c\xcc\x8b\xd3\xe2\xa2\x3c\x3f\x13\x27\x0c\x76\x88\x73\x26\x51\x63\x40\x06\x0e\x75\xce\x8c\xad\xac\xd6\x54\xe5\x80\x29\xad\xa9\x94\x6b\x72\xf6\xc1\x21\xac\x6d\x47\xe2\x0e\xff\x67\xcb\xeb\x3e\x67\x91\x89\x25\x59\x2c\xf9\xb6\xc3\x77\x9b\x90\xc1\xb4\x2a\xef\xf6\xf8\x6b\xcb\xf6\x74\x68\xfd\x0d\x25\xbb\x42\x49\x9a\x0e\xd7\x76\x2f\x1c\x54\xd6\x35\x90\x03\x09\x2c\xfa\xbf\x63\xc1\xad\xf5\x81\x81\xe8\xc9\x73\x96\xc0\xbf\xf0\x92\x30\x4e\x24\x49\x29\x9c\xb0\xe5\x22\x8b\xa9\xcb\xa4\x5f\x50\x24\x25\x12\x11\x15\x3f\x9b\xb6\x0b\xc4\x9e\x2c\x54\xff\x2f\x2e\x2d\x67\xdf\xc7\x69\x93\x07\x3a\xdc\x47\xbe\xd3\xea\x56\xa3\x7f\x75\xf2\x71\x95\x90\x2d\x93\xa8\x15\x65\x67\xf1\x29\xe3\x94\xd9\xb6\x68\x24\x7f\x21\x3d\x46\xbf\x5a\x9e\x14\x56\xae\x87\x0a\xfb\x2c\xdf\x15\x8d\x0a\x7c\x38\x8a\x03\x7a\x26\x3a\x67\xa0\x12\x74\xa5\x57\x4f\x37\x72\xad\x3c\x79\x6f\x0d\xc1\x5a\x1d\x54\xeb\xfb\xa7\x6e\xe6\x83\x08\x9d\x8f\x2e\x1b\xc2\xfc\xd1\xd3\xa2\xca\x60\xdd\x25\x6c\xb7\x14\xec\x6a\xa5\xa9\xdc\x2d\x82\xdd\x26\x47\x40\xc3\x0f\x64\x74\x24\x14\x8b\xb5\xb0\x4d\x3a\xf9\x19\x00\x00\xff\xff\x9a\xd1\xde\x9c\x92\x05\x00\x00")
+var _assetsMailrouterJs = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x02\xff\xc4\x56\x41\x6f\xe3\x36\x13\x3d\x47\xbf\x62\x3e\x7e\x01\x44\x62\x6d\xb9\xc9\xae\x9b\x45\x12\xb9\x08\xba\x58\xb4\xb7\xa2\xbb\xb7\x62\x51\x8c\xc5\x51\x44\x2c\x4d\x0a\x24\x65\xd7\x08\xf2\xdf\x0b\x8a\x92\x23\xbb\x49\x9b\x05\x0a\xf4\xe6\x90\x6f\xde\x0c\xdf\x9b\x99\x68\xb1\x80\x4f\x8d\xdd\x79\xb0\x0e\x1a\x25\xc9\x43\x68\x08\x3a\x4f\xce\xe0\x86\x00\x8d\x84\x16\xbd\xdf\x59\x27\xa1\x56\xa4\xa5\x87\x5d\x43\x06\xb0\x0b\x0d\x99\xa0\x2a\x0c\xca\x1a\x08\xfb\x96\x40\x79\xa8\x1a\x34\xf7\x24\x8b\xec\x9c\xb3\xff\x1f\x63\x98\x28\xd2\x2d\xaf\x3b\x53\xc5\x13\x2e\xe0\x21\x3b\x53\x35\xf0\x73\x1e\x1a\xe5\x45\xb1\x45\xcd\x05\x94\x25\xb0\xca\xe1\x66\x23\x97\xac\x87\x9c\x45\xb6\xb1\x0c\x26\x0a\x0c\xc1\x71\xd6\x6a\xac\xa8\xb1\x5a\x92\x63\x33\x60\x9e\x2a\x47\x81\x89\x9b\x13\xfc\x5c\xe3\x9a\x34\x13\x45\xa0\x3f\x02\x67\x9f\x5e\x82\xdd\x3b\xdb\xb5\x4c\x14\x8e\x36\x76\x4b\x3f\x6a\xf4\x9e\xb3\x46\x49\x49\xb1\x74\x94\x72\x38\xf2\x8d\xdd\x3d\x85\x8f\x4a\x7d\x7b\xf8\x23\x90\xf6\x04\xcf\x3f\xbf\xd5\xa8\xcc\x37\x3c\xfe\xe9\xfe\x1f\x9e\xff\xcb\xcb\xc0\xff\x48\x80\x87\x57\xd5\x91\xa2\x26\x34\x23\xf3\xeb\xea\xf8\xbb\xf0\xc7\xec\x51\xdc\x64\xd9\x61\x12\xe2\x00\xd4\xd6\x6d\xc6\x7e\x77\xa4\x69\x8b\x26\x40\xb0\xfd\x9d\x27\x4d\x55\x20\x09\xce\x76\x81\xfa\xd6\x2f\xe0\x63\xc2\xd6\xd6\x81\x0d\x0d\xb9\xfe\xd8\x03\x3a\x02\xa9\x3c\xae\x35\x49\xf0\x7d\xfc\x3e\x1e\xc6\x6c\x86\x54\x8f\xdc\xa2\x56\x12\x23\xa1\xb1\x0e\x7c\xb7\xde\xa8\x10\xe2\x0c\x8d\x73\x02\xb1\xfa\x5f\x63\xb2\xcf\xfb\x96\xd2\xd4\x6c\x31\xa5\x80\x12\xe2\xeb\xe3\x4f\x36\x34\xd0\x4d\x16\x05\x29\xfa\xea\xe6\xc3\x05\x61\xd5\x9c\xcc\xdd\x81\xc2\xf7\x1c\xa9\x03\x53\x73\x49\x0c\xd8\x47\x7a\x26\x0a\xdf\x6a\x15\x38\x83\x24\x75\x0c\x8a\xe5\xc4\x98\x42\x99\x3b\xe7\x70\xcf\x23\x74\x96\xb8\x04\xac\x4a\xf8\x2e\x99\x92\x28\x83\xbd\xbf\xd7\x27\xfd\x30\x83\xff\x45\x12\x31\xc5\xd5\xca\x48\xce\x94\x69\xbb\x30\x1b\x34\x9e\x41\x6c\x5a\x74\x84\x4c\x14\xad\xb3\x2d\x67\xa3\x98\x53\x8a\x68\xdf\x63\x36\x91\x61\xd8\x33\x47\xb2\x89\x9b\x2c\x8d\xda\x01\xa5\xc9\xdc\x87\xa6\xd7\xe2\x44\xe0\x48\x77\xdc\x0f\xc9\x6a\xe9\x6c\x3b\x97\x76\x67\xc0\x1a\xbd\xef\xcd\xae\x95\x0e\xe4\x22\x08\x03\x48\xd2\x6a\x1b\x1d\x55\x08\x98\x62\x4e\x5c\xfc\xd8\xc3\xef\x26\x36\x44\x41\x31\x01\x92\x95\xe9\x8f\x13\x33\x53\x9a\xf9\xe1\xee\x25\x3f\xff\x62\x4d\x8a\x98\x3d\x67\x70\xba\x3a\xb6\xf8\x5f\xb5\xef\x95\xa6\x1d\x1e\x35\xb1\x6d\xaa\xd3\xc4\xb9\x03\xf4\xc4\xbb\x63\x59\x47\xfb\x7e\x42\x23\x35\x79\x48\xcf\xdd\x50\x68\xac\x64\x60\x0d\x68\x65\xbe\x7a\xf0\x5d\xd5\x00\xfa\xeb\x08\xbd\x45\x68\x1c\xd5\x25\x5b\xf4\xae\xf9\xc5\xfa\x72\x59\x5f\x11\x2d\xe7\xeb\xab\xe5\x72\x7e\x71\x41\x6f\xe7\xef\x2f\x2e\xbf\x9f\xbf\xc3\xe5\xfa\xed\xfa\x7d\x75\xf5\x0e\x2f\x19\x4c\x88\x4b\x26\x49\x53\x20\x16\xf7\x45\xc9\x8c\xad\xad\xd6\x76\x37\x60\x2a\x6b\x6a\xe5\x36\x25\xbb\x73\x04\x7b\xdb\x81\xef\x1c\xfd\xc0\x56\x1f\xfa\x98\xdb\x05\xae\xb2\x73\x9e\xff\x36\xe1\xfb\x92\x8b\xa2\xd2\xaa\xfa\xfa\xcc\xbf\xcb\x81\x8d\x1f\x99\x9a\x4f\x13\xe5\x42\x3c\x35\x45\xbf\xcc\x62\x73\xe5\xb7\xfd\xcf\xb1\xe0\xd6\xfa\xc0\x86\xe6\x2b\x59\x0e\x6f\x8e\xbb\x24\x8f\x8a\xe4\x02\xde\x40\xce\x56\xb7\x8b\x18\xba\xca\x0f\x4b\x60\x43\x01\x63\xc6\x9f\xa3\xe3\x50\x42\x7e\xdb\x7b\x0f\x71\x07\x97\xec\xf7\x51\xed\x2d\xea\x8e\x9e\x23\x9f\x3c\x75\xc8\xd1\x2f\x90\x72\xec\x31\x58\xac\xf2\x98\x2b\xa6\x2d\xe2\x97\x09\x17\x05\xb6\x2d\x19\xc9\x8f\x52\x8f\xa7\x9f\x2d\xcf\xd7\x56\xee\x73\x71\x88\x4a\xab\x94\xa7\x1d\x7f\xe6\x28\x74\xce\x40\x8d\xda\xd3\xcd\x61\xe5\x7f\x50\xbe\xd5\xb8\x87\x60\xad\x0e\xaa\xf5\xfd\x97\x4b\xe1\x03\x86\x2e\x4e\xc6\x70\xcc\x1f\x52\x47\x5b\x77\x0d\x83\x4b\x69\x2e\x4a\x36\x20\xd8\x97\x7c\x06\x95\x35\x01\x95\x21\x77\x0d\x2c\xd6\xc2\x1e\x45\xf6\xe7\x00\x02\x57\xbd\x78\x61\x09\x00\x00")
 
 func assetsMailrouterJsBytes() ([]byte, error) {
 	return bindataRead(
@@ -170,12 +172,12 @@ func assetsMailrouterJs() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "assets/mailrouter.js", size: 1426, mode: os.FileMode(420), modTime: time.Unix(1464208149, 0)}
+	info := bindataFileInfo{name: "assets/mailrouter.js", size: 2401, mode: os.FileMode(420), modTime: time.Unix(1792380552, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
 
-var _viewsFiltersHtml = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\xbc\x59\x7b\x6f\xdc\xb8\x11\xff\x3b\xfe\x14\x8c\x9a\x02\x2d\x10\x49\x71\x1e\x6d\x12\x48\xdb\xa6\xb1\x83\x1a\x68\x9a\x20\x71\x80\x16\x41\x70\xe0\x8a\xd4\x8a\x39\x4a\x54\x48\x6a\x6d\x9f\xb1\xdf\xfd\x86\x0f\xbd\x77\xbd\x76\xce\x36\x82\xac\xf8\x98\x19\xce\xfc\x38\x33\x1c\xd2\xc9\xc3\xa3\x0f\x6f\x4f\xff\xff\xf1\x18\x15\xba\xe4\x8b\x83\xc4\x7c\x10\xc7\xd5\x2a\x0d\x68\x15\x2c\x0e\x1e\x24\x05\xc5\x04\xbe\x0f\x92\x92\x6a\x8c\xb2\x02\x4b\x45\x75\x1a\x34\x3a\x0f\x5f\x06\xfd\x44\xa1\x75\x1d\xd2\x1f\x0d\x5b\xa7\xc1\xff\xc2\x2f\x6f\xc2\xb7\xa2\xac\xb1\x66\x4b\x4e\x03\x94\x89\x4a\xd3\x0a\xb8\x4e\x8e\x53\x4a\x56\x74\xc0\x57\xe1\x92\xa6\xc1\x9a\xd1\xb3\x5a\x48\x3d\x20\x3d\x63\x44\x17\x29\xa1\x6b\x96\xd1\xd0\x76\x1e\x23\x56\x31\xcd\x30\x0f\x55\x86\x39\x4d\x0f\x67\x62\x08\x55\x99\x64\xb5\x66\xa2\x1a\x48\x9a\x91\xe1\x46\x17\x42\xce\x28\x38\xab\x7e\x45\x92\xf2\x34\x50\x30\xad\xb3\x46\x23\x96\x19\x49\x85\xa4\x79\x1a\xc4\x58\x81\xe5\x2a\xce\xf1\xda\x0c\x47\xf0\xe3\xf8\x34\xd3\x9c\x2e\xde\x63\xc6\xa5\x68\x34\x95\x49\xec\x46\x3a\x99\x63\xfe\xa5\x10\x5a\x69\x89\xeb\xa8\x64\x55\x94\x29\x15\xf8\x45\xf5\x05\xa7\xaa\xa0\x54\x07\xbb\x58\xcb\x6e\x8d\x2b\xf8\x1e\x86\x21\xfa\xf7\xe9\xfb\xff\xbc\x40\xaa\x60\x25\xc2\x15\x41\x9f\xa8\xaa\x45\x45\xa2\xef\x0a\x9d\x1c\xbf\x44\xaa\xa9\x0d\xd8\x48\xe4\x9e\x90\x72\x5a\x02\x10\xca\x12\x97\x94\x30\x8c\x7e\x34\x54\x32\xaa\x50\x18\xb6\x42\xbf\xb2\x1c\x71\x0d\x02\xd0\xab\x6f\x76\xcc\x61\x8d\x94\xcc\xd2\xc0\x6c\xbf\x7a\x1d\xc7\x42\xa9\xa8\xc4\xe7\x19\x01\xcb\x44\x19\x73\xb6\x54\xb1\xf1\xa9\x17\xa0\xcb\x3a\x7e\x16\xfd\x3d\x7a\xd2\xf7\x41\x9f\x60\x91\xc4\x4e\xce\x8d\x44\xca\xce\xa0\xf8\x30\x7a\x1e\x3d\xed\x06\x0c\xa4\x33\xa9\x0f\xbf\xd2\x8a\xb0\xfc\x9b\xb5\x25\x89\xbd\x47\x27\x4b\x41\x2e\x16\x07\x86\x80\xb0\x35\xca\x38\x80\x9c\x06\x15\x5e\x2f\xb1\x44\xee\x13\xb2\x6a\x4d\xc1\xdf\xdb\x6e\xce\xce\x29\x09\xb5\xa8\x01\x7a\x01\x1e\x68\xa8\xd9\x0a\x5b\x7f\x33\x2b\x8d\x24\x19\xef\xc2\xac\xa2\xc0\xc6\x1b\x46\x1c\xc1\x96\xb5\x42\xa3\x0f\x95\x7e\x1e\xd4\x6a\xb4\x16\x15\xd2\x17\x35\x2c\xe0\x3a\xc1\x84\x43\x8b\xd5\xca\xc4\x15\xc1\x1a\xfb\x8e\x59\x8f\x73\x5c\xab\x6e\x18\xcb\x95\x09\xd4\xc8\xf3\x74\xd3\x7e\x1d\x00\xbb\xc6\x55\x2b\x58\xc9\x50\x54\xfc\x22\x58\x9c\x5a\x69\xa8\x37\x0c\x90\x04\xba\xad\x4c\x26\x0c\x42\x90\x6d\xe1\xbe\x0b\xa2\x24\x76\xf6\xb7\x5d\x3c\xc1\x61\x29\xc1\x61\xdb\xf8\xfc\x53\x30\x8a\x41\xec\xf1\x8e\x01\xf0\x9d\xd0\xb7\xa0\xa0\x29\x3a\x49\xc3\x07\xa4\xed\xfe\x0f\x9a\x9c\xe6\xba\x87\x92\xb3\x05\x28\xe7\x83\x35\x58\x1c\x61\x55\x2c\x05\x96\xc4\xa8\x91\x80\xcb\x6e\x27\xcc\x19\x07\x55\x15\x30\xbc\x73\xad\xab\xc9\xad\x65\x86\xfa\x93\x6d\x4c\x88\x93\xb8\xe1\x53\x93\xbb\x96\x6f\x1c\x5c\xc3\x43\x87\x04\x52\x9c\x6d\x71\x5b\xc8\x42\x55\x87\x53\x71\xd8\x0e\xd7\x78\x45\x3b\x5f\xee\x0c\x2a\x0e\x3d\xe5\xe5\x25\xe4\x8f\x88\x55\xb9\xd8\x6c\x86\xd2\x20\x93\x43\x2a\xb2\xbf\xa1\x99\x0d\x16\x97\x97\x2d\x99\xd5\xfa\xf2\x12\xa2\x77\xb3\x19\x4a\xa1\x52\x0a\xb9\x5b\x0c\x81\xf3\xcb\x28\x01\x82\x5a\xca\xb9\xa4\x21\xf3\x19\xe5\xbc\xdf\xcc\x5c\xc8\xb2\x9d\x31\xed\x10\x4e\x03\xf6\x9b\xc1\x8a\xb7\x71\x6f\x86\x03\xc4\x08\xb4\xac\xa1\xa1\x1b\xc0\x59\x46\x6b\x1d\x76\x87\xe4\x97\xd3\x77\x70\x48\x42\x46\x85\xf3\x06\x68\x6b\xa1\xb4\x21\x32\x61\x35\xd8\x7e\x63\x2f\xa8\xd5\x29\xf0\x20\x61\x55\x0d\x87\x8f\x3b\xac\x7e\x71\xdc\x01\x5a\x63\xde\x40\x5f\xe1\x35\x84\xb8\xcb\x0e\x05\x23\x04\x8e\x69\x14\xf7\xac\x9c\xae\xc0\xc8\x85\xc7\x89\x30\xbd\xd9\x1c\xc3\x2f\x98\xce\x15\xdd\x6c\xde\x10\xe2\x51\x40\x6e\x8b\xc0\x83\x1c\x47\x27\x61\xb6\xff\xed\x8c\x3b\x5a\xfe\x45\x57\xac\x42\x16\x23\x13\x03\x26\x72\x9a\xb2\xf2\xe7\xc4\x5c\x04\x4c\x87\xaa\x0c\xff\xd6\x5b\x37\x9e\xb7\x00\xaf\xc0\xb5\xeb\x21\x05\xd8\x81\x97\x94\x9b\x65\xd2\x40\x48\xe3\x52\x13\x81\xcf\xec\xe9\x0d\xbb\x11\x5a\xca\x60\xf1\xc1\x50\x81\x35\xa6\x37\x92\x34\x57\xe6\xd5\x68\xa9\x16\x6e\x07\x69\xd5\x94\xcb\xc1\x6a\x56\x3d\xbf\x52\xe0\x77\xc4\xeb\x63\x76\xdf\x37\xfd\xd6\x18\x7f\x03\xac\x23\xab\x0a\x6c\x28\xaa\x39\xce\x68\x21\x38\x74\xd3\xe0\x70\x6c\x60\x9f\x97\xb6\xf7\x6f\x86\x91\xd1\x6c\x2f\x44\xff\x05\xa2\x3f\x8e\x90\xa6\xe7\xfa\x4a\x7c\x9c\x5f\x3b\x8d\xfa\x10\x71\xfd\x09\x52\x46\xa3\x19\x50\xc7\xe7\xb8\xac\xe1\x0c\x72\x7c\xa6\xca\x81\xb2\x52\x52\x82\xb0\x64\x38\x6c\x7b\xa0\x88\x6c\xe8\x5d\x62\x2a\x69\xc6\x6a\x06\x75\xd1\x5e\x60\xdf\x49\x51\xde\x07\xb0\xb0\x8c\x87\xd4\xb6\x26\x60\x1a\x2d\x66\x60\x2a\x88\x6d\x2a\xff\x49\x1d\xa6\xa6\x82\xba\x4b\xc8\x0a\xc8\x70\xd7\x72\xc5\x53\x71\x0f\x78\x69\xe1\xd0\x32\xdf\x09\x56\xa7\x62\x86\x54\xb7\xdd\x3f\x0b\xd6\xb4\x6b\xb2\xe5\x31\x14\xd4\x5b\x73\xe5\xce\xa4\x2a\xd9\xaa\xb8\xcd\xac\x6a\x11\x50\xcd\xf2\x3b\xcd\xf4\x9e\xdd\xf3\x54\x7b\x37\xef\xb3\xa3\xbb\x87\x1d\xec\x34\x1a\x18\x31\xdb\x4b\xaf\xce\xce\x3c\xd2\xf2\xdd\x82\xdf\xfb\xa4\xcf\x60\xb7\xf6\x1e\x5a\x86\xe8\x1a\xa7\x96\x21\x83\x42\xbb\x5a\xa1\x93\x8f\xf7\x80\x68\xab\x57\x6f\xc8\x96\xe3\xcb\x0c\xcf\xcf\xaf\x27\x91\xf9\x77\x18\x3f\x7d\x7e\x6b\x50\xda\x92\x36\x64\x64\x5f\x26\xf6\x64\x7b\xe1\xb4\x95\xf1\xcf\xa1\xa8\xe0\x12\x9c\xe9\xab\xa0\xeb\xb5\x18\xaa\x3e\x16\x03\x05\xea\x23\x46\xd0\xeb\x14\xd5\x92\x55\x3a\x47\xc1\x9f\xe1\x9e\xee\x70\xb5\xca\x9d\x74\x05\x68\xc7\x20\x4d\xb9\x8a\x1e\x31\x48\xd4\xe7\x8f\xd1\x23\x2b\xd8\x48\x88\x5c\xc1\x3f\xa1\x4f\x84\x7d\xdf\xe8\x37\xcd\x31\x44\x46\x70\x60\xcb\x3e\xfa\x03\x75\x63\x20\xd6\xd4\x7a\xce\x38\xda\xd6\x7e\x8b\x8e\xcb\x1d\xc0\x96\xad\xe5\x51\x47\x34\xc7\x0d\x87\x78\x42\x7f\x21\xae\xf9\x57\xcf\x97\xc4\x6e\xf1\xa9\xc9\x83\xb2\xba\xf5\x01\xb7\xe2\x6d\x65\xcf\x59\x52\x3c\xd8\xca\xb5\xfd\xee\xb2\xc3\x01\x6e\x5c\x92\xce\x45\x88\x3c\x87\x3a\xdf\xba\xa1\xf3\xc7\x89\x4f\x8d\xae\xf2\x90\x86\x4a\xd6\x07\xe7\x52\x57\x08\xfe\x87\xe0\x27\x25\x96\x70\xf3\xfe\x0c\x85\xfd\xe4\xc2\x7b\x73\xdc\x46\xbd\x24\x36\xa6\x74\xf7\xc3\x7e\x66\x68\x8a\xc6\x4b\x4e\x43\xf7\x7e\xa2\xd8\x7a\xf0\x3e\x60\x67\x46\x64\xc8\x11\x2b\x2d\x59\x4d\xc9\xb0\xba\x53\x83\xdb\x8b\x6e\x9f\x0c\xdb\xbe\x1c\x6a\xaf\x8b\xb6\x54\x87\xd6\x78\xdc\xd5\xa7\xb3\xe1\xf7\x58\x67\x05\xfa\x50\x6d\x99\xf2\xf1\x3e\x1b\x1f\x0f\x41\x4f\x0e\x10\x1a\xeb\x97\xe8\x5c\x08\xbd\x5b\x5d\x62\x84\x91\xbb\x19\x9a\x2a\x36\xd2\x24\xd1\xee\x81\xaa\x25\x9e\x25\x0a\x87\xbc\xcd\x14\x9c\x29\x3d\x88\xc0\xb9\x0d\x10\xf0\x8e\xbc\xbd\x9c\x6c\xd1\xad\xa7\x71\x59\xe1\x4a\x92\xcf\x4d\x69\xdc\x76\x0f\x95\xdd\x9f\x9d\xd2\x86\x4e\x3e\x7b\x14\xe9\x85\x98\xcc\x16\x9b\x0c\xda\xde\xbe\x27\x8f\x62\x6d\x24\xf9\x64\x15\x2c\xcc\x85\xb7\x7b\x00\xba\x9e\xfc\x7d\xa2\xdd\x8b\x82\x7b\x59\x83\x63\x21\x67\xb2\x4c\x83\x23\xc8\x71\xf6\xe4\xf6\x3b\x31\x05\xf0\x31\xdc\x58\x28\xba\x10\x0d\x94\x20\x92\xfe\xc3\xb3\xb7\xaf\x01\xc4\x70\x53\xff\x88\x5b\x89\x5c\x70\x6e\x52\x96\x15\x4a\xc7\xea\x5f\xe1\x35\xd3\xec\x0b\x93\x43\xb7\x81\xae\x09\xd9\x79\x0e\xb8\xfa\x91\x68\xf8\x0e\xdb\x3e\x3e\x7f\x37\x4f\xc2\x17\xdb\x5f\x58\xb7\xd1\x8f\xdf\xb9\xaf\xc5\x32\x78\xdf\x9e\xd0\x43\x62\xb4\x56\x25\xb1\xfb\x43\xc5\xc1\xef\x01\x00\x00\xff\xff\x35\x56\xac\x60\xba\x18\x00\x00")
+var _viewsFiltersHtml = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x02\xff\xbc\x5a\x7b\x6f\xdc\xb8\x11\xff\xdb\xfe\x14\x0c\x9b\x02\x2d\x10\x49\xf6\x5d\xd2\xcb\x05\x5a\xb5\x69\xec\xa0\x41\xcf\x4d\x9a\x38\x40\x8b\xc3\xa1\xe0\x8a\xa3\x15\x13\x8a\x54\x48\x6a\xe3\xed\x62\xbf\x7b\xc1\x87\xb4\x7a\xec\xc3\x49\x6d\xc3\x80\x97\xa4\x7e\x33\x1c\xfe\x66\x38\x14\x49\xa5\x8f\x2e\xde\xbe\xba\xfe\xf7\xbb\x4b\x54\x9a\x8a\x67\xa7\xa9\xfd\x41\x9c\x88\xc5\x0c\x83\xc0\xd9\xe9\x49\x5a\x02\xa1\xd9\xe9\xc9\x49\x5a\x81\x21\x28\x2f\x89\xd2\x60\x66\xb8\x31\x45\xf4\x1c\x6f\x1f\x94\xc6\xd4\x11\x7c\x69\xd8\x72\x86\xff\x15\x7d\x7c\x19\xbd\x92\x55\x4d\x0c\x9b\x73\xc0\x28\x97\xc2\x80\x30\x33\xfc\xe6\x72\x06\x74\x01\x3d\x39\x41\x2a\x98\xe1\x25\x83\xaf\xb5\x54\xa6\x07\xfd\xca\xa8\x29\x67\x14\x96\x2c\x87\xc8\x55\x9e\x20\x26\x98\x61\x84\x47\x3a\x27\x1c\x66\xe7\x13\x35\x14\x74\xae\x58\x6d\x98\x14\x3d\x4d\x13\x18\x69\x4c\x29\xd5\x04\xc1\x99\xf8\x8c\x14\xf0\x19\xd6\xa5\x54\x26\x6f\x0c\x62\xb9\xd5\x54\x2a\x28\x66\x38\x21\x5a\x83\xd1\x49\x41\x96\xb6\x39\x66\xb9\xf4\x72\x86\x19\x0e\xd9\x15\x61\x5c\xc9\xc6\x80\x4a\x13\xdf\xd2\xe9\x1c\xca\xcf\xa5\x34\xda\x28\x52\xc7\x15\x13\x71\xae\x35\x0e\x9d\x9a\x15\x07\x5d\x02\x18\xbc\x4f\xb4\xea\xfa\x38\x20\xf7\x28\x8a\xd0\xdf\xae\xaf\x7e\x79\x86\x74\xc9\x2a\x44\x04\x45\xef\x41\xd7\x52\xd0\xf8\x93\x46\x6f\x2e\x9f\x23\xdd\xd4\x96\x6c\x24\x8b\x00\x04\x0e\x15\x08\xa3\x1d\xb8\x02\xca\x08\xfa\xd2\x80\x62\xa0\x51\x14\xb5\x4a\x7f\x65\x05\xe2\x06\xbd\xb9\x44\x3f\xff\xe6\xda\x3c\xd7\x48\xab\x7c\x86\xad\xfb\xf5\x8b\x24\x91\x5a\xc7\x15\xb9\xc9\xa9\x88\x73\x59\x25\x9c\xcd\x75\x62\x63\xea\x99\x2e\xd9\x32\xf9\x31\xfe\x29\x3e\xdb\xd6\xe3\x4f\x1a\x67\x69\xe2\xf5\x7c\x93\x4a\xd5\x0d\x28\x39\x8f\x9f\xc6\x3f\x74\x0d\x96\xd2\x89\xd6\x47\xbf\x82\xa0\xac\xf8\xcd\x8d\x25\x4d\x42\x44\xa7\x73\x49\x57\xd9\xa9\x05\x50\xb6\x44\x39\x27\x5a\xcf\xb0\x20\xcb\x39\x51\xc8\xff\x44\x4c\x2c\x41\x69\x68\xab\x05\xbb\x01\x1a\x19\x59\x63\xa4\x24\x07\x87\x66\x0b\xe2\xe2\xcd\xf6\x34\xd0\x64\xa3\x8b\x30\x01\x2a\x2a\x78\xc3\xa8\x07\xec\xe8\x2b\xb2\xf6\x80\x0a\xcf\x4f\xd2\x79\x63\x8c\x14\xc8\xac\x6a\x98\x61\x5f\xc1\x23\x09\x23\x17\x0b\x3b\xaf\x28\x31\x24\x54\x6c\x7f\x9c\x93\x5a\x77\xcd\x44\x2d\xec\x44\x8d\x83\x4c\xf7\x38\xf4\x73\x92\xea\x9a\x88\x56\xb1\x56\x91\x14\x7c\x85\xb3\x6b\xa7\x0d\x6d\x07\x96\x26\x16\xb7\x53\xc8\x4e\x83\x68\x4e\x14\xce\xee\x09\x94\x26\x7e\xfc\x6d\x95\x8c\x78\x98\x2b\x22\x68\x3b\x3f\x7f\x87\x07\x73\x90\x04\xbe\x13\xca\x96\x7b\xa9\x6f\x49\x41\x63\x76\xd2\x86\xf7\xa0\xad\xff\x7b\x45\x0e\x85\xd9\x52\xc9\x59\x96\x92\x76\xb2\xe2\xec\x82\xe8\x72\x2e\x89\xa2\xd6\x8c\x34\xe1\x6c\x37\xb0\x60\xdc\x80\xd2\x09\xce\x5e\xfb\xd2\x61\xb8\x1b\x99\x45\xbf\x77\x85\xc3\xe0\x0a\xb4\x26\x0b\x07\xbf\x0a\xc5\xc3\x02\x5f\x1a\xa2\x88\x30\x4c\x40\x82\xb3\x7f\x76\x95\x91\x50\x9a\x34\x7c\x4c\x6c\x57\x0a\x85\xd3\x5b\xcc\x83\x3e\x40\xc9\xaf\x3b\x26\x47\x45\x98\xe8\xbc\x51\x9e\xb7\xcd\x35\x59\x40\x37\x63\x3a\xda\xca\xf3\x80\x5c\xaf\x59\x81\x62\x26\x0a\xb9\xd9\xf4\xb5\x11\x0e\xca\x20\xf7\x3f\xb2\x4f\x71\xb6\x5e\xb7\x30\x67\xf5\x7a\x0d\x82\x6e\x36\x7d\x2d\xa0\x94\x54\xfb\xd5\x50\x22\x16\xd6\x88\xf5\xba\x43\x4e\x35\xf5\x85\xbf\x02\xe7\xdb\x90\x29\xa4\xaa\xda\x27\xb6\x1c\x95\x52\xb1\xff\x5a\xae\x78\x9b\x5d\x6c\x33\x46\x8c\xce\xb0\x8f\x94\xc8\x37\x90\x3c\x87\xda\x44\xdd\x52\xfc\xf1\xfa\x75\xf4\x1c\xa3\x0a\x4c\x29\xe9\x0c\xd7\x52\x1b\x0b\xb2\x93\xb7\x17\x64\x76\xbc\x74\xb3\xe9\x0c\x38\x49\x99\xa8\x1b\x13\x96\xc4\xff\x78\x69\x8c\x96\x84\x37\x30\xc3\x9a\x2c\x01\x87\x1c\x54\x32\x4a\x41\x60\x94\x6c\x45\x39\x2c\x40\xd0\x2c\xf0\x44\x99\xd9\x6c\x2e\x29\x33\xeb\x35\x70\x0d\x9b\xcd\x4b\x4a\x03\x0b\xc8\xbb\x28\x4d\x82\x44\xa7\x61\xe2\xff\xf6\x89\x5f\xc0\xfe\x0a\x0b\x26\x90\xe3\xc8\xce\x34\x3b\x3f\x9b\x4a\x84\xd5\x68\xaa\x22\x97\x3c\xd2\x55\xf4\xa7\xed\xe8\x86\xcf\x1d\xc1\x0b\x25\x9b\xba\x8f\x38\x49\x39\x99\x03\xb7\xdd\xcc\xb0\x54\x36\xa4\x46\x0a\x7f\x74\xef\x08\x4a\xf2\xc8\x21\x71\xf6\xd6\xa2\xd2\xc4\xd5\x06\x9a\xa6\xc6\xfc\x3c\xe8\xaa\xa5\xdb\x53\x2a\x9a\x6a\xde\xeb\xcd\x99\x17\x7a\xc2\xc1\x23\xc1\x1e\x46\xbb\x62\x70\x8d\x8d\x37\xca\x4c\xec\x4c\xd9\x6c\x30\xaa\x39\xc9\xa1\x94\x9c\x82\x9a\xe1\xf3\xe1\x00\xb7\xd9\x6f\x77\xfd\xdb\x38\xb2\x96\x1d\xa5\xe8\x1f\xa4\x82\xff\x9f\x21\x03\x37\xe6\x20\x3f\x3e\xae\xbd\x45\x8c\x0e\xeb\x23\xa6\xac\x45\x13\xa2\x2e\x6f\x48\x55\x73\x40\x5e\xce\xbe\x4b\x7d\x69\x98\x02\x8a\x88\x62\x24\x6a\x6b\x33\x6c\x54\x03\xf7\xc9\xa9\x82\x9c\xd5\x0c\x84\x39\x4a\xec\x6b\x25\xab\x87\x20\x56\xc9\x36\xeb\xb8\xd2\x88\x4c\x6b\xc5\x84\x4c\x0d\x82\x82\xfa\x0b\x78\x4e\xed\x7b\xda\x7d\x52\x56\x4a\x6d\x6e\x15\x8a\xd7\xf2\x01\xf8\x32\xd2\xb3\x65\xe4\x84\xab\x6b\x39\x61\xaa\x73\xf7\xf7\x92\x35\xae\xda\x6c\x79\x29\xe8\xee\x5c\xb9\x37\xa9\x2a\xb6\x28\xef\x32\xab\x3a\x06\x74\x33\xff\x04\xb9\x39\xe2\xbd\x80\x3a\xea\xbc\x0f\x1e\xf7\x00\x1e\xec\x2c\xea\x0d\x62\xe2\xcb\x60\xce\xde\x3c\xd2\xca\xdd\x41\xdc\x87\xa4\xcf\x16\x4c\x1c\x5d\xb4\x2c\xe8\x16\xab\x96\x85\x3d\x00\x93\xad\x3d\xdb\x01\xec\x58\xb6\x6c\xf3\x74\xdd\x3a\x8b\xed\xdf\x79\xf2\xc3\xd3\x27\x88\xcb\x9c\x70\x24\x15\x6a\x18\x7d\x71\x7e\x76\x76\x76\x67\xac\xea\xba\x38\x16\x9e\x75\x71\x3c\x34\xdf\xbd\x7e\x88\xb0\xac\x8b\xce\xe8\x69\x38\xbe\x7b\x3d\xe1\xb0\x20\x8c\x3f\xd1\xb2\x30\xb6\x70\x67\x94\xd1\xcf\xec\x58\x42\xb6\x90\xa3\xa4\x5d\xfc\xfd\xcd\xd5\x03\xb0\xe6\x6d\x69\x0d\x9f\xf0\x66\xad\xd8\x4d\x9c\x90\x02\xee\x8e\xb4\x8a\xa8\xfc\x18\x6b\x16\x73\x9c\xb6\xab\x97\xef\x5f\x3d\x04\x6f\xde\x9a\xce\xf8\x29\x73\xd6\x90\x9d\xd4\xdd\x19\x6b\x9c\x69\x03\x76\xa7\x78\x98\xb8\x16\x76\x94\xbb\x5f\x02\xf0\xfb\xe8\xd3\xc0\x21\x37\x87\x38\xdb\x1a\xd2\xb7\x7e\xa8\xe6\x24\x95\xee\x40\xb2\xa5\x13\x67\x2f\xc5\x2a\x4d\x7c\xe3\x10\xb9\x5e\x2b\xbb\xa7\x44\x71\xab\x49\xb7\xdb\xc8\xdd\xaa\xd6\xeb\x78\xb3\xc1\x6e\x13\xf6\x38\xec\xc2\x5c\x05\xbe\x84\x7a\xdc\x12\x80\xe2\xcd\x06\xf9\xf1\x40\xbb\x35\x0b\x3f\x99\xd3\xb2\xcf\xa0\xde\x56\xb6\x75\xa7\x57\x73\x57\x2e\xf7\xfb\xd4\x23\x0e\xf7\xa0\xa3\xee\x7e\x99\xfb\x03\xab\xfb\x71\x76\x6b\xc4\xd6\xea\xc3\x8e\x76\xc7\x35\xb8\xbf\x45\x6e\x9d\xe3\x7d\xe3\xad\x45\x18\xa3\x00\xdd\xef\xa2\x0b\xe0\x6c\x09\x0a\x2d\x19\x41\x0e\xbb\xdb\x5d\x23\x03\xb6\x27\x3a\xb7\xb0\xa2\x07\xde\x6f\x47\xff\x58\xe8\x16\x06\x28\x70\x6f\x44\xc7\x3b\x0f\xc0\xfd\x1d\xbf\x07\xff\x2a\xb8\xa3\xd3\x5d\x01\x39\x3c\x5c\x2c\x81\xd7\xd1\x9c\xcb\xfc\x73\xd8\xec\x45\xad\x2b\xdd\x41\xa9\xaf\xe8\x01\x5f\xd9\x15\x31\x79\xc9\xc4\x02\xd9\x23\x77\xc4\x34\x2a\x81\x53\x64\x8f\x65\x4b\x40\x5b\x1a\x90\x3d\x8e\x42\x8d\x30\x16\x64\x2c\x4e\x01\x07\xa2\x81\xda\xb7\x17\xca\x74\x4e\x14\x05\x1a\x0f\x8f\x3e\xbf\x7f\xce\x8c\x07\x60\x63\x51\x41\xcd\x57\xed\x94\x1a\x8e\x28\xf0\xba\x7f\xeb\x59\xf3\xd5\xd1\x59\xf5\xde\xa2\x1e\x60\x01\x0a\xd6\x74\x43\x9a\x2c\x40\xce\x90\xc9\x02\xf4\xec\xd9\x19\x7a\x16\xff\x14\x9f\xa3\xb7\x8d\x99\xcb\x46\x50\xef\x33\xca\x34\x99\x73\xa0\x88\x59\xa7\x31\x8d\x40\x2c\x99\x92\xc2\x5e\x74\xe0\xdb\x04\xcb\x8e\x20\x50\x50\x34\xd6\xb7\x5f\x99\x29\xbd\xd2\x0f\x57\xd7\xef\x90\x33\x37\x46\x1f\x35\x20\x82\x9e\xde\xdc\xa0\x5c\x52\xb0\x14\x23\x82\x0c\x54\xb5\x54\x44\xad\x90\x77\x06\x93\xe2\x7e\xa3\xc1\xa6\x87\x88\xd1\x3d\x01\x61\x9f\xee\x8f\x87\x20\x7b\x3c\x24\x7c\x0e\xba\x9f\x3c\xbb\xb5\xa2\x3f\x1e\x3c\x5e\x99\x1e\x33\x8a\x5e\xcc\x50\xad\x98\x30\x05\xc2\xbf\xd7\x38\x24\x15\x67\xdc\x9b\xd1\xb2\xd5\xad\xad\x8f\x99\xa0\x70\xf3\x04\x3d\x76\x8a\xad\x86\xd8\x95\x8e\x2e\xb5\x5e\x20\xb6\x8a\x71\xb7\xca\xb6\x6d\xe8\x31\xa3\xd3\xec\x95\x75\x52\xfe\x04\xca\xaf\xd4\x41\x46\x5f\x40\x41\x1a\x6e\x36\x1b\xf4\x07\xea\x8b\x7f\x0c\x72\xf7\xb9\x18\x1f\x3c\x3e\x98\x9c\x0a\x9c\xee\x94\xda\x7d\x78\x7f\x57\x67\xb2\x53\x15\xb2\x28\x34\x18\x17\x86\x3e\x1e\x47\x31\x35\xb8\x31\xd3\xcd\xbc\x62\xdb\x4c\x33\x37\x02\xcd\x8d\x88\x6a\xc5\x2a\xa2\x56\x38\xfb\x40\x96\x30\xba\x57\xfa\x76\xde\x06\xb5\x34\xb1\x43\xc9\x4e\x27\x4f\xfa\x43\x31\x36\x19\x45\xfe\x9a\x52\xb3\x65\xef\x1a\xce\x3d\x19\xc0\x90\x07\x6b\xa3\x58\x0d\xb4\x7f\xbc\xa9\xb7\x23\x4f\x4d\x7b\x33\xdf\xd6\x55\xdf\x7a\x53\xb6\x67\xd5\xa6\x1c\xb5\xfb\x03\xda\x49\xb3\xcb\x76\xe8\xad\xd8\xf1\x28\xcc\xf7\x49\xfb\xb0\x29\x4d\x7a\x26\xa4\xc9\xd0\xbe\xd4\x14\x52\x9a\xfd\xe6\x52\xab\x8c\xde\x4f\xd3\xd8\xb0\x81\x25\xa9\xf1\xf7\xc0\x7b\x13\x85\x67\xde\x65\x0a\xfb\x5e\xbe\xd9\x1c\x18\xc3\x7a\x1d\xe0\xed\xe9\xfc\x0e\xdb\xb6\x18\x9f\x15\x0e\x42\x3e\x34\x95\x0d\xdb\x23\x28\xe7\x9f\xbd\xda\xfa\x41\x3e\xb9\x7b\xdc\x2a\xb1\x99\x2d\xb1\x19\xb4\xbd\x7e\x1a\xdd\x3d\xb7\x33\x29\x24\x2b\x9c\xd9\x1b\x9f\xee\x9e\xf5\x76\xfa\x8f\xa9\xf6\x57\x6a\x7e\xd1\xca\xa5\x28\x98\xaa\x66\xf8\x02\x38\x18\xbb\x0c\x07\x4f\x8c\x09\x7c\x82\x88\x02\xb4\x92\x0d\xd2\x8d\x82\x3f\x07\xf1\xf6\x3a\x8c\x5a\x69\x08\xdf\x4a\x08\x59\x48\xce\x6d\xca\x72\x4a\x61\x68\xfe\x81\xa8\x19\x67\xdf\x34\x19\x84\x4d\x9a\xb8\x29\x3b\xcd\x01\x87\x6f\x49\xfb\x9f\x3b\xb4\xdf\x78\x7c\xb2\x5f\x5e\xac\x76\x7f\xc8\xb0\x0b\x3f\xfc\x9c\xe4\x56\x22\xbd\xcf\x48\x46\xf8\x34\xf1\xa3\x4a\x13\xff\x3d\xd0\xe9\xff\x06\x00\xb0\x5d\x57\x1d\x21\x24\x00\x00")
 
 func viewsFiltersHtmlBytes() ([]byte, error) {
 	return bindataRead(
@@ -190,12 +192,12 @@ func viewsFiltersHtml() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "views/filters.html", size: 6330, mode: os.FileMode(420), modTime: time.Unix(1428322835, 0)}
+	info := bindataFileInfo{name: "views/filters.html", size: 9249, mode: os.FileMode(420), modTime: time.Unix(1792383873, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
 
-var _viewsIndexHtml = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\xb4\x57\x5f\x6f\xdb\x36\x10\x7f\x4e\x3e\x05\xc3\xf6\xb1\x92\x96\x2c\xdd\xba\x42\x32\x30\x34\x09\x56\x20\xc1\x86\x34\x03\x36\x14\x7d\xa0\xc5\xb3\xc5\x8c\x12\x15\x92\x72\x62\x18\xf9\xee\x3b\x52\xff\x6d\xc5\x4b\x86\xe6\xc5\x16\x79\xbf\xfb\xf7\xbb\x23\x75\x8a\x8f\xce\x7e\xff\x74\xf3\xf7\x1f\xe7\x24\xb3\xb9\x9c\x1d\xc6\xee\x8f\x48\x56\x2c\x13\x0a\x05\x9d\x1d\x1e\xc4\x19\x30\x8e\xff\x07\x71\x0e\x96\x91\x34\x63\xda\x80\x4d\x68\x65\x17\xc1\x07\xda\x0b\x32\x6b\xcb\x00\xee\x2a\xb1\x4a\xe8\x5f\xc1\x9f\xbf\x06\x9f\x54\x5e\x32\x2b\xe6\x12\x28\x49\x55\x61\xa1\x40\xad\xcf\xe7\x09\xf0\x25\x0c\xf4\x0a\x96\x43\x42\x57\x02\xee\x4b\xa5\xed\x00\x7a\x2f\xb8\xcd\x12\x0e\x2b\x91\x42\xe0\x17\xef\x88\x28\x84\x15\x4c\x06\x26\x65\x12\x92\xe3\x1d\x33\x1c\x4c\xaa\x45\x69\x85\x2a\x06\x96\x76\x60\xac\xb2\x99\xd2\x3b\x08\x29\x8a\x7f\x88\x06\x99\x50\x83\x62\x9b\x56\x96\x88\xd4\x59\xca\x34\x2c\x12\x1a\x31\x83\x99\x9b\x68\xc1\x56\x6e\x3b\xc4\x9f\x5a\xcf\x0a\x2b\x61\x76\xc5\x84\xd4\xaa\xb2\xa0\xe3\xa8\xde\xe9\x6c\x8e\xf5\xe7\x4a\x59\x63\x35\x2b\xc3\x5c\x14\x61\x6a\x0c\x6d\x9c\xda\xb5\x04\x93\x01\x58\xfa\x94\x6a\xde\xf9\xd8\xa3\x77\x14\x04\xe4\xb7\x9b\xab\xcb\xf7\xc4\x64\x22\x27\xac\xe0\xe4\x1a\x4c\xa9\x0a\x1e\xde\x1a\xf2\xf9\xfc\x03\x31\x55\xe9\xc8\x26\x6a\xd1\x00\x41\x42\x8e\x44\x18\x0f\xce\x81\x0b\x46\xee\x2a\xd0\x02\x0c\x09\x82\xd6\xe8\x57\xb1\x20\xd2\xa2\x01\xf2\xcb\x37\xbf\x57\x73\x4d\x8c\x4e\x13\xea\xca\x6f\x3e\x46\x91\x32\x26\xcc\xd9\x43\xca\x31\x33\x95\x47\x52\xcc\x4d\xe4\x7a\xea\x3d\xc6\xb2\x8a\x7e\x0c\x7f\x0e\x7f\xe8\xd7\x18\x0f\x9d\xc5\x51\x6d\xe7\x45\x26\x75\x97\x50\x74\x1c\x9e\x86\x27\xdd\x86\xa3\x74\xc7\xea\xd1\x57\x28\xb8\x58\x7c\xf3\xb9\xc4\x51\xd3\xd1\xf1\x5c\xf1\xf5\xec\xd0\x01\xb8\x58\x91\x54\x22\xc9\x09\x2d\xd8\x6a\xce\x34\xa9\xff\x02\x51\xac\x00\xfb\xbd\x5d\x2e\xc4\x03\xf0\xc0\xaa\x12\xa9\x57\xd8\x81\x0e\x2d\x96\xcc\xf7\x9b\xf3\x34\xb2\xe4\xba\x8b\x89\x02\x50\x4d\x56\x82\xd7\x80\x09\x5f\x81\x8b\x07\x74\x23\xc7\xb0\x2a\x6b\x55\x41\xec\xba\x44\x07\xf5\x82\x6e\x69\x58\xb5\x5c\xba\x73\xc5\x99\x65\xcd\xc2\xf9\x93\x92\x95\xa6\xdb\x66\x7a\xe9\x0e\x6a\xd8\xe8\x74\xe2\xc6\x0f\x92\x5d\xb2\xa2\x35\x6c\x74\xa0\x0a\xb9\xa6\xb3\x1b\x6f\x8d\xf4\x89\x21\x93\x88\x9b\x54\x72\xc7\x20\x40\xdb\x9e\xee\xd7\x00\xc5\x51\x9d\x7f\xbb\x64\x5b\x3c\xcc\x35\x36\x6c\x7b\x3e\xdf\xd0\xd1\x19\x64\x0d\xdf\x11\x12\xfe\x24\xf5\x2d\x29\x64\x9b\x9d\xb8\x92\x03\x68\x5b\xff\xc1\xa3\x84\x85\xed\xa9\x94\x62\x86\xc1\x35\x87\x95\xce\xce\x98\xc9\xe6\x8a\x69\xee\xc2\x88\xb1\x65\xa7\x81\x0b\x21\x31\x54\x83\x0a\x17\xf5\xd3\x7e\xb8\xcf\xcc\xa1\xaf\xfd\xc3\x16\x38\x8e\x2a\xb9\x9d\x72\xf7\xd4\x3c\x1c\x3e\xa3\x43\x87\x00\xad\xee\x27\xda\x16\x6f\xa1\xa2\xe3\x29\x3b\x6e\xb7\x4b\xb6\x84\xae\x97\x07\x0c\x64\xc7\xde\xf1\xae\x69\x52\x4a\x96\x42\xa6\x24\x6a\x98\x9e\xcb\x51\x80\x32\x78\x30\xc1\x4f\xae\x3c\x81\xc9\x83\x93\xa1\x4a\xa7\x81\x41\x9c\xce\x36\x9b\xd0\x58\x66\x4d\x78\x65\x96\xe6\x0b\x5e\x66\x8f\x8f\xe8\xfa\xb4\xc7\x0c\xdb\xcd\xc2\x83\x0d\x72\x24\x11\x73\xbe\x02\x63\x30\x72\x43\x0c\x2a\x6d\xb5\x68\xdf\x3b\xdf\x27\xae\x33\xad\xca\x12\xf8\x8b\x43\xe3\xb5\xde\x2b\x47\x77\x81\xa7\xe7\x7f\x04\xb7\xf0\x6a\xaf\x18\xdb\x19\x5e\x67\x75\x45\xc9\x7c\xed\x1b\xff\x39\xf1\x39\xad\xd7\xae\xa9\xf3\xd1\xd5\xf4\xc5\xc1\xbd\x7e\x55\x9d\x9b\xb6\xaa\x2f\x0e\xef\xbf\xea\xda\xdf\x29\xde\xed\x49\xf7\x2a\xa9\xe6\xdd\x35\x70\xc9\x8c\x25\x18\x0e\xbe\xbf\x2f\xd5\xd2\x60\x14\x79\xd3\x36\x18\xc8\xc9\x6c\xf7\x5a\xb0\x0c\x07\xc6\xa0\x7e\x9b\x1b\xb1\x1a\xbc\xad\xbc\x64\x04\x23\x35\x18\x67\x29\x81\x34\x52\x22\x78\x42\x25\x7a\x19\x90\x61\xdb\xe9\xb5\x5d\xeb\x7e\xe1\xa4\xb3\x6b\x48\x01\xdd\x60\x9a\xb8\x18\x8b\x2e\xb4\xca\x27\xb6\x6f\xd4\xc4\xe6\x97\x6a\x7e\x0b\xa9\x9d\xb2\xe2\x6f\xf6\x09\x81\xbf\xc4\xa7\x4c\x61\xe5\x2a\x33\x16\xe0\xaa\x0f\xdc\x89\x86\x59\xc5\xb6\x1e\x64\x5a\xf0\x66\x83\x6f\xc5\x25\x90\xb7\xa2\xe0\xf0\xf0\x8e\xbc\x45\x4e\xc8\xc7\x84\x84\xd2\x57\xe0\x49\x32\x38\xf6\x8d\xc3\x86\x2d\x27\xee\x26\xb0\x7c\x1a\xe3\xc8\xd9\x27\xbf\x51\xfb\xa4\x0d\x5d\x7b\x1d\x78\xde\xf6\x21\x3c\x81\x3b\x80\xcd\x06\xc7\x54\xb8\xf3\x59\x87\xe7\x5a\x2b\x4d\x28\x45\xd4\xc0\xb9\x27\xb8\x51\xdc\x6c\x70\x34\x1c\x90\x52\xeb\x17\x30\xa1\xdf\xf5\xb7\xd7\xa7\xfd\x8b\xf9\xcd\xd6\x20\x66\x95\x92\x56\xe0\x8c\xe8\x3f\x03\x12\xda\xf8\xf5\xc6\x1e\x1f\xe9\x6e\x1c\xee\x3d\x3e\x11\xcb\xa8\xea\x07\x5b\x52\x14\x0e\xcb\x8e\x4b\x77\x18\xc6\x47\x73\xfc\x38\x35\x0c\x0c\xe7\xed\xf6\x23\xe3\xd6\x8d\xfe\xeb\xe9\x49\x7a\x0a\x3f\xfe\x9e\x79\x96\xca\xe0\x3b\x66\x0b\x8f\x13\x9f\xcf\x2a\x8e\xea\x0f\xd2\xc3\x7f\x03\x00\x00\xff\xff\xec\xd3\xc0\x57\xa2\x0e\x00\x00")
+var _viewsIndexHtml = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x02\xff\xb4\x58\x6f\x6f\xdb\xbe\x11\x7e\xed\x7c\x0a\x96\xed\x8b\x16\xa8\xa4\x24\x4b\xba\x2e\x90\x04\x0c\x4d\x82\x05\x48\xd0\x2d\xcd\x80\x0d\x45\x5f\xd0\xe2\xd9\x62\x46\x91\x0a\x79\x72\x13\x08\xfe\xee\x03\xa9\xbf\xb6\x15\xff\x9a\x1f\x9a\x57\x16\x79\xcf\x73\x3c\x3e\x77\x47\x53\x8a\xdf\x9c\x7f\xfd\x72\xf7\xdf\x7f\x5e\x90\x1c\x0b\x99\x1e\xc4\xee\x87\x48\xa6\x96\x09\x05\x45\xd3\x83\x59\x9c\x03\xe3\xe9\xc1\x6c\x16\x17\x80\x8c\x64\x39\x33\x16\x30\xa1\x15\x2e\x82\xcf\x74\x30\xe4\x88\x65\x00\x0f\x95\x58\x25\xf4\x3f\xc1\xbf\xff\x1e\x7c\xd1\x45\xc9\x50\xcc\x25\x50\x92\x69\x85\xa0\x30\xa1\x57\x17\x09\xf0\x25\x8c\x78\x8a\x15\x90\xd0\x95\x80\x9f\xa5\x36\x38\x82\xfe\x14\x1c\xf3\x84\xc3\x4a\x64\x10\xf8\xc1\x47\x22\x94\x40\xc1\x64\x60\x33\x26\x21\x39\xda\x71\xc3\xc1\x66\x46\x94\x28\xb4\x1a\x79\xda\x81\xb1\x0a\x73\x6d\x76\x10\x52\xa8\xff\x11\x03\x32\xa1\x36\xd7\x06\xb3\x0a\x89\xc8\x9c\xa7\xdc\xc0\x22\xa1\x11\xb3\x16\xd0\x46\x0b\xb6\x72\xd3\xa1\xc8\x74\xc3\x43\x81\x12\xd2\x1b\x26\xa4\xd1\x15\x82\x89\xa3\x66\xa6\xf7\xb9\xc9\x9f\x6b\x8d\x16\x0d\x2b\xc3\x42\xa8\x30\xb3\x96\xb6\x8b\xe2\x93\x04\x9b\x03\x20\x7d\x8e\x5a\xf4\x6b\xec\xe1\xbd\x09\x02\xf2\x8f\xbb\x9b\xeb\x53\x62\x73\x51\x10\xa6\x38\xb9\x05\x5b\x6a\xc5\xc3\x7b\x4b\xae\x2e\x3e\x13\x5b\x95\x4e\x6c\xa2\x17\x2d\x10\x24\x14\xa0\xd0\x7a\x70\x01\x5c\x30\xf2\x50\x81\x11\x60\x49\x10\x74\x4e\xbf\x8b\x05\x91\x48\xae\x2e\xc8\xdf\x7e\xf8\xb9\x46\x6b\x62\x4d\x96\x50\x97\x7e\x7b\x16\x45\xda\xda\xb0\x60\x8f\x19\x57\x61\xa6\x8b\x48\x8a\xb9\x8d\x5c\x4d\x9d\xda\x5c\xac\xa2\xbf\x84\x7f\x0d\x0f\x87\x71\x78\x6f\x69\x1a\x47\x8d\x9f\x17\xb9\x34\xfd\x86\xa2\xa3\xf0\x24\x3c\xee\x27\x9c\xa4\x3b\x5e\xdf\x7c\x07\xc5\xc5\xe2\x87\xdf\x4b\x1c\xb5\x15\x1d\xcf\x35\x7f\x4a\x0f\x1c\x80\x8b\x15\xc9\x24\xb3\x36\xa1\x8a\xad\xe6\xcc\x90\xe6\x27\x10\x6a\x05\xc6\x42\x37\x5c\x88\x47\xe0\x01\xea\x92\x12\xa3\x25\x78\xb4\x58\x32\x5f\x6f\x6e\xa5\x0d\x4f\xae\xba\x98\x50\x60\x82\x85\xac\x04\x6f\x00\x13\x6b\x05\x2e\x1e\x30\xad\x7d\x16\xcf\x2b\x44\xad\x08\x3e\x95\x90\xd0\x66\x40\xb7\x18\xa8\x97\x4b\xd7\x57\x9c\x21\x6b\x07\x6e\x3d\x29\x59\x69\xfb\x69\x66\x96\xae\x51\xc3\x96\xd3\x9b\xdb\x75\x66\xb1\x2d\x99\xea\x1c\x5b\x13\x68\x25\x9f\x68\x7a\xe7\xbd\x91\x61\x63\x71\xe4\x70\x93\x24\xd7\x06\xc1\x9c\x19\x9a\xbe\x12\x28\x8e\x9a\xfd\x77\x43\xb6\xa5\xc3\xdc\x30\xc5\xbb\xfe\x7c\x4b\x37\x7a\x90\xb5\x7a\x47\x5c\xac\x9e\x95\xbe\x13\x85\x6c\xab\x13\x57\x72\x04\xed\xf2\x3f\x7a\x94\xb0\xc0\x41\x4a\x29\xd2\x98\x75\xcd\x4a\xd3\x73\x66\xf3\xb9\x66\x86\xbb\x30\xe2\x48\x8a\x69\xe0\x42\x48\x04\x63\x23\x9a\x5e\x36\x4f\xfb\xe1\x7e\x67\x0e\x7d\xeb\x1f\xf6\x83\x0b\xb0\x96\x2d\x3d\xfc\xa6\x7d\xdc\x4f\x78\xa8\x98\x61\x0a\x85\x82\x88\xa6\xff\xea\x07\x5b\xa4\x38\xaa\xe4\xb6\xb0\xfd\x53\xfb\x70\xf0\x0b\x7d\x30\x06\x18\xfd\x73\xa2\x39\x0a\x26\x54\x9f\x8d\xfc\xa8\x9b\x2e\xd9\x12\xfa\x8e\x19\xe9\x9c\x1f\xa5\x07\x2d\x78\xd3\x35\x29\x25\xcb\x20\xd7\x92\x83\xb1\x43\xc6\x36\x02\x94\xc1\xa3\x0d\x3e\xb9\x22\x08\x6c\x11\x1c\x8f\x29\x3d\x63\x16\xe7\x27\x69\x5d\x87\x16\x19\xda\xf0\xc6\x2e\xed\x37\x50\xb8\x5e\xc7\x51\x7e\x32\x60\xc6\x45\x8d\xf0\x88\x41\x51\x21\xf0\x21\x07\xc4\x82\xc2\xad\x46\x18\x2a\xf4\xf7\xc4\x75\x6e\x74\x59\x02\x7f\x71\x68\xbc\xe1\xbd\x72\x74\x97\x4c\xc8\x3f\x11\xdc\xc2\xd3\x5e\x31\xb6\x73\x86\xac\xc9\x28\x99\x3f\xf9\xf6\xfa\x95\xf8\x1c\xeb\xb5\x73\xea\xd6\xe8\x73\xfa\xe2\xe0\x5e\x3f\xab\x6e\x99\x2e\xab\x2f\x0e\xef\x8f\xf2\x3a\x9c\x29\xb3\xd9\xac\xae\xc5\x82\x84\x98\x1b\x8d\xe8\x97\xeb\x8e\x87\xe3\xce\xbf\xad\xe6\xfd\xe9\x70\xd7\xe1\x48\x26\x05\x28\x74\x61\x1d\xa7\xbb\x87\x04\xb2\xb9\x84\xa0\xb9\x41\x58\xb1\x1a\xfd\x43\x7a\xcb\x06\x8c\x34\x60\x8b\x46\x94\xc0\x29\x11\x3c\xa1\x7d\x3c\x23\x7d\xb0\xbb\x36\x77\x63\x33\x0c\x9c\x35\xfd\xe2\x43\x8a\x23\xcc\xb7\x0c\xb7\xc0\xac\x56\x93\x86\x45\x65\x81\x4f\x58\xbe\x09\x95\xc1\xc4\xfc\x35\xb3\x48\xcc\x14\x2d\x8e\x46\x01\xc5\xd1\x66\xb4\x31\x36\x37\xa3\x0e\x5c\xd7\x86\xa9\x25\x4c\x08\x3f\xb1\x33\xee\xea\xa2\xd9\x9c\xeb\x71\xe4\xbb\xd6\x66\x87\xcf\x5b\x7d\xbc\xcf\x99\xfd\x5e\xc3\x4b\x6d\x0a\x86\x84\x1e\x1f\x1e\x7e\x0a\x0e\x8f\x82\xc3\x63\x72\x74\x7a\x76\x78\x72\x76\x78\x4a\x9f\x63\x3a\x35\x5e\x44\xdc\x10\x69\x56\xd7\xa0\x46\x1b\x8f\xa3\x0d\x95\xe2\xc8\x17\xc6\x66\xd1\x6e\xf0\xf6\x96\xaa\xcf\x54\x5d\xbb\x7b\xee\xb5\x5e\xda\xf5\x9a\x14\xfd\x9f\xf6\xab\xd4\xac\xd4\x4b\xfb\x92\x72\xbd\x85\x0c\xc4\x6a\xb2\xfa\xae\x85\x45\x50\x60\x26\x4c\x97\x46\x17\x13\xd3\x77\x7a\xaa\x88\xab\xf9\x3d\x64\x53\x1d\xd1\x5c\x8e\xa6\x3a\xc2\xdd\x83\xa6\x5c\x21\xc3\xca\xfe\x86\x8a\x7f\x27\x14\x87\xc7\x8f\xe4\x9d\xd4\x4b\x72\x96\x90\x50\xfa\xe4\x8c\x75\xea\xd4\x0c\xea\xda\xa1\xc2\x2b\xbe\x5e\xd3\x9d\xe2\xf3\xa6\x4e\xc3\x67\x0a\xd4\x63\x3a\x31\xf7\x61\x9c\xaa\xfb\xec\x77\x7a\x9f\xb5\xd5\x79\xef\x02\x5e\xf0\x7d\x08\xaf\xfc\x7a\xed\xcf\x63\x3f\x71\x03\xc5\xdc\x51\xc8\xfb\xba\xde\x98\xf8\xd0\xd6\xff\x00\xfd\x6a\xc4\x52\x28\x26\xd7\x6b\xd2\xdf\x3e\xdf\x8e\x14\x1c\xec\xfd\x6b\x8f\x2d\x98\x94\x94\xf8\xd7\xea\x84\xde\x82\x04\x66\x81\x93\x85\xd1\x05\x19\xee\xad\x34\x7d\x6f\x5a\xd3\x07\x77\x6d\x6d\x57\xde\xda\x85\x0f\x04\x1e\x9a\x58\x2e\x8c\xd1\x86\x50\xd7\xfa\x23\x85\x7c\xf9\xb4\xc4\xad\xb6\x6f\xf9\x0a\x26\xf8\x7d\xb4\x9e\x4f\x87\xbb\xf5\xdb\xad\x37\x35\xd4\x5a\xa2\x28\xfb\x0d\xd5\xf5\xe0\xcc\x55\xcf\x4e\x1c\x2c\x9d\x8c\xe5\xb7\x1c\x50\xfb\xef\xf1\xe3\x17\xf2\xee\x2b\xc4\xbd\xfb\x36\xf0\x34\xfd\xaa\x3d\x85\xdf\xfc\xe0\xf1\x4b\x94\xd1\x87\x8e\x2d\x7c\x1c\x35\xbb\x8a\xa3\xe6\x8b\xd5\xc1\xff\x07\x00\x34\x31\xeb\x7d\xc3\x12\x00\x00")
 
 func viewsIndexHtmlBytes() ([]byte, error) {
 	return bindataRead(
@@ -210,12 +212,52 @@ func viewsIndexHtml() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "views/index.html", size: 3746, mode: os.FileMode(420), modTime: time.Unix(1455488163, 0)}
+	info := bindataFileInfo{name: "views/index.html", size: 4803, mode: os.FileMode(420), modTime: time.Unix(1792383686, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
 
-var _viewsRoutesHtml = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\xc4\x59\xeb\x73\xdc\xb6\x11\xff\x6c\xfd\x15\x30\x9b\x8f\xe6\xb1\x8e\xab\x34\xf1\xf0\xae\x55\x2d\x65\xa2\x99\xc8\xd1\x38\xf2\x4c\x3b\x99\x4c\x07\x47\xec\x1d\x91\x80\x04\x0d\x80\x27\xa9\x37\xfa\xdf\xbb\x20\xc0\x37\xef\x55\xc5\xee\x07\xe9\xf0\x58\xec\xe3\xb7\xbb\x78\x2c\xe3\x97\x97\x3f\xbd\xbb\xfb\xd7\xed\x15\x49\x4d\x26\x16\x67\xb1\xfd\x21\x82\xe6\xeb\x79\x00\x79\xb0\x38\x7b\x11\xa7\x40\x19\xfe\xbe\x88\x33\x30\x94\x24\x29\x55\x1a\xcc\x3c\x28\xcd\x2a\xfc\x36\x68\x27\x52\x63\x8a\x10\x3e\x95\x7c\x33\x0f\xfe\x19\x7e\xbc\x08\xdf\xc9\xac\xa0\x86\x2f\x05\x04\x24\x91\xb9\x81\x1c\x57\x5d\x5f\xcd\x81\xad\xa1\xb3\x2e\xa7\x19\xcc\x83\x0d\x87\xfb\x42\x2a\xd3\x21\xbd\xe7\xcc\xa4\x73\x06\x1b\x9e\x40\x58\x75\x5e\x11\x9e\x73\xc3\xa9\x08\x75\x42\x05\xcc\x5f\x8f\xd8\x30\xd0\x89\xe2\x85\xe1\x32\xef\x70\x1a\x91\xd1\xd2\xa4\x52\x8d\x28\x04\xcf\x7f\x27\x0a\xc4\x3c\xd0\x38\x6d\x92\xd2\x10\x9e\x58\x4e\xa9\x82\xd5\x3c\x88\xa8\x46\xcb\x75\xb4\xa2\x1b\x3b\x3c\xc3\x7f\x6e\x9d\xe1\x46\xc0\xe2\x86\x72\xa1\x64\x69\x40\xc5\x91\x1b\x69\x78\xf6\xd7\x2f\xa5\x34\xda\x28\x5a\xcc\x32\x9e\xcf\x12\xad\x03\x2f\xd4\x3c\x0a\xd0\x29\x80\x09\x76\x2d\xcd\x1a\x19\x7b\xd6\xbd\x0c\x43\xf2\xc3\xdd\xcd\x8f\xe7\x44\xa7\x3c\x23\x34\x67\xe4\x03\xe8\x42\xe6\x6c\xf6\x9b\x26\xd7\x57\xdf\x12\x5d\x16\x16\x6c\x22\x57\x9e\x10\x04\x64\x08\x84\xae\x88\x33\x60\x9c\x92\x4f\x25\x28\x0e\x9a\x84\x61\xcd\xf4\x17\xbe\x22\xc2\x20\x03\xf2\xdd\xaf\xd5\x98\xc3\x9a\x68\x95\xcc\x03\xeb\x7e\xfd\x36\x8a\xa4\xd6\xb3\x8c\x3e\x24\x0c\x2d\x93\x59\x24\xf8\x52\x47\x36\xa6\xce\x51\x97\x4d\xf4\x66\xf6\xd7\xd9\x9f\xdb\x3e\xea\x13\x2c\xe2\xc8\xf1\x39\x89\xa5\x6a\x0c\x8a\x5e\xcf\xfe\x32\xfb\xba\x19\xb0\x90\x8e\xb8\xbe\xfc\x05\x72\xc6\x57\xbf\x56\xb6\xc4\x91\x8f\xe8\x78\x29\xd9\xe3\xe2\xcc\x12\x30\xbe\x21\x89\x40\x90\xe7\x41\x4e\x37\x4b\xaa\x88\xfb\x09\x79\xbe\x01\x8c\xf7\xba\xbb\xe2\x0f\xc0\x42\x23\x0b\x84\x5e\x62\x04\x5a\x6a\xbe\xa6\x55\xbc\x59\x49\x3d\x4e\x36\xba\x28\xcf\x01\x97\x89\x92\x33\x47\x30\x21\x2b\xb4\xfa\x80\xf2\xf3\xa8\x56\x69\x8c\xcc\x89\x79\x2c\x50\x80\xeb\x04\x83\x15\x46\xae\xd7\x36\xaf\x18\x35\xd4\x77\xac\x3c\x21\x68\xa1\x9b\x61\xaa\xd6\x36\x51\x67\x7e\x4d\x33\xed\xe5\x20\xd8\x05\xcd\x6b\xc6\x5a\x85\x32\x17\x8f\xc1\xe2\xae\xe2\x46\x5a\xc3\x10\x49\xa4\x9b\x5c\x64\xd3\x20\x44\xde\x15\xdc\x9f\x83\x28\x8e\x9c\xfd\x75\x97\x0e\x70\x58\x2a\x0c\xd8\x3a\x3f\xff\x14\xf4\x72\x90\x7a\xbc\x23\x04\x7c\x27\xf4\x35\x28\x64\x88\x4e\x5c\x8a\x0e\x69\xed\xff\x4e\x53\xc0\xca\xb4\x50\x0a\xbe\x40\xe5\x7c\xb2\x06\x8b\x4b\xaa\xd3\xa5\xa4\x8a\x59\x35\x62\x0c\xd9\x69\xc2\x15\x17\xa8\xaa\xc6\x05\xdf\xbb\xd6\x7e\xf2\xca\x32\x4b\xfd\xa1\x6a\x0c\x88\xe3\xa8\x14\x43\x93\x9b\x96\x6f\x9c\x1d\x11\xa1\x5d\x02\x25\xef\x27\xc2\x16\x77\xa1\xbc\xc1\x29\x7d\x5d\x0f\x17\x74\x0d\x4d\x2c\xd7\x2a\xa6\xaf\x3d\xe1\x76\x8b\xdb\xc7\x8c\xe7\x2b\xf9\xf4\xd4\x65\x86\x1b\x39\xee\x44\xd5\xff\xd0\xce\x06\x8b\xed\xb6\x26\xab\x94\xde\x6e\x31\x79\x9f\x9e\xba\x5c\x40\x29\xa9\x76\xb3\x61\x78\x7c\x59\x1d\x90\x51\x4d\x39\xe6\xd4\x5d\x7c\x0f\x42\xb4\xbe\x5c\x49\x95\xd5\x33\xb6\x1d\xe2\x61\xc0\xff\x63\xa1\x12\x75\xda\xdb\xe1\x80\x70\x66\x11\x42\x3b\x43\xd7\xa7\x49\x02\x85\x09\x9b\x23\xf2\xe3\xdd\xf7\x78\x44\xe2\x7e\x8a\xa7\x0d\x92\x16\x52\x1b\x4b\x64\x93\xaa\xf5\xa6\xb5\x16\x95\x6a\xc4\xbf\x88\x79\x5e\xe0\xc9\xe3\x4e\xaa\x7f\xbb\xc5\x01\xd9\x50\x51\x62\x5f\xd3\x0d\xe6\xb7\xdb\x1a\x52\xce\x18\x9e\xd1\x24\x9a\x5e\xca\x35\x83\x15\x2d\x85\x69\x16\x5b\x3c\x18\x37\xb3\x6b\x7d\xe9\x66\x50\xec\x6e\x5e\x02\xd6\x08\xd7\xc2\x23\x8e\xeb\x9e\x9e\xae\xf0\x3f\x82\x28\x34\x3c\x3d\x5d\x30\xe6\xf1\x24\x95\xaf\x31\x12\xdd\x82\x86\xc1\x28\x8e\xea\x19\x77\x44\xfd\x03\xd6\x3c\x27\x15\xd8\x36\x97\x6c\x06\x96\x59\xee\xcf\x9b\x31\x0b\x9c\x0e\x75\x16\x7e\xd3\x02\xd5\x9f\xaf\x3c\xb5\x46\x50\x8b\x2e\x05\x9a\x41\x97\x20\xac\x18\x9b\xc8\x19\x04\x03\x7e\x6f\xaa\x4b\x00\x7a\x35\xac\x08\x83\xc5\x7b\x24\x42\x5b\x6c\xa7\xc7\x67\xac\xca\x77\x3d\x41\x35\xf8\x0e\x4f\x03\x0f\x26\xe8\xa9\xe6\xc5\x04\xde\x3b\x95\xf7\x9d\x42\x4d\x18\xb9\xee\xc0\x59\x56\x1f\xeb\xa7\x42\xd0\x04\x52\x29\x30\xbb\xe6\xc1\xd5\x03\xcd\x0a\xdc\xa5\xab\x65\xf6\x1a\x80\xf7\x2e\x05\x8c\x50\xc5\x69\x58\xf7\x50\x0b\x55\x42\x1f\x8d\x76\x33\x9c\xee\x9f\x06\xa8\x91\x07\xe1\xbc\x93\xcf\x07\x13\xec\xb5\x67\x2f\x9a\x56\x11\x0b\xa3\xfd\x1d\xe0\x77\x27\x47\xe8\x29\x48\x78\xc1\xf1\xbe\xf3\x77\x70\x38\xda\x7b\xc5\xe7\xc4\x29\xc5\xcc\x3f\x2a\xf8\x7e\xf0\x84\x5f\x20\x00\x5b\x9d\x2c\x70\x6d\x6f\x00\x5f\xad\xd1\x08\x44\xeb\x93\x59\x17\xbf\xff\x5b\x14\xfa\x77\xc3\x7e\x64\x6f\x91\xe8\xf9\xa8\xe6\x65\xb6\x04\xb5\x17\x57\xa7\x0d\x67\x75\x6b\x80\xa7\xd5\x63\x84\xe5\xd7\xe7\x07\xd0\x23\x78\xad\x75\x64\x78\x11\x9e\x07\xdf\x9c\x9f\xbf\x39\x3f\x05\xd2\x61\xd7\xee\xbf\x57\x78\xd5\x9f\xdc\x7d\x77\x6e\xd3\x8a\xaf\xd3\x2f\xb1\x4f\xdb\xe7\x19\x66\x27\x4f\xa8\x7f\xc8\xed\x77\xed\x45\x8f\xfc\x7f\x73\xb2\xc6\x17\x50\x62\xf6\xf9\x75\xa8\x94\xf5\xf0\x60\xac\xc7\xf2\x45\x2c\xab\x77\x68\x1d\x00\xb9\xcc\x21\xe8\x1e\xa6\x55\x1b\x3e\xb9\xee\xcc\x1a\x71\x87\x31\x46\x1c\x21\x9e\xaa\x4e\x25\x1b\x02\x75\x2b\xf0\x07\xae\xff\x59\xbc\x47\xca\x38\x72\x62\xf6\xca\xc6\x60\xc3\x1b\xdb\x31\xc2\x1d\xe5\x51\xd2\x6f\x7f\xbc\xb8\x7e\x7f\x94\xf8\x44\xd1\x2c\x63\xe7\x47\x29\x50\xd3\x1e\xa5\xc2\xbb\x0f\x17\x37\xe1\xcd\xe5\xf9\xa4\x16\xf8\x90\xa8\x96\x2d\xc8\xb3\xb7\x9e\x53\xdc\xe6\x6e\x52\xad\xa2\xd5\x65\xa9\x3f\xea\x82\xa7\xd4\xa0\x6c\x68\x1d\x48\x86\x9a\xec\x60\x1a\x7c\xf4\x84\x5f\xe0\xec\x68\x75\xea\x1a\x32\xda\xeb\x6a\x8d\x46\xfb\x5d\xb3\xe2\xf9\xa7\xc2\x67\x71\x4d\x81\x42\xee\xa5\x62\x87\x0e\x1e\x4f\x76\xc8\x35\x7d\x9e\xde\x5d\xa7\x25\xc3\xcf\x90\x28\x68\xee\xde\xb7\x9e\xd7\xc0\x94\xc1\xf0\xf3\x03\x61\x64\xe0\xe4\x81\xd7\x10\x75\xcd\x1c\x1f\x7c\x7e\x62\x14\x0c\xa7\x01\xa1\x7b\x40\x14\xd3\x40\x0c\x86\xff\xb0\x93\x72\x74\x00\x9e\x4d\xae\x9a\x7e\x41\xef\xc0\xff\xe4\x83\x72\xcc\x42\xae\x56\xf8\xde\xac\xc2\xce\xc5\xdf\xc0\xa5\xbd\x82\x92\x2e\x97\x19\x6f\xd3\x7b\x69\x72\x82\x7f\x61\xa1\x78\x46\xd5\x63\xb0\xf8\x19\x5f\x98\x83\xb2\xcb\xe9\xb8\xf5\x7a\x71\x64\x4d\x69\xaa\x14\xed\x4c\xd7\x14\x43\x97\x02\x42\x57\xc5\xd3\x7c\xd3\xa9\x52\x55\x33\x3d\x32\xe2\x88\xb5\x51\xbc\x00\xd6\x79\x40\xe9\xce\x2b\xda\xd4\x75\xeb\xba\xaf\xba\xca\x9b\xd4\x3f\xf4\xb0\xd1\x1f\xb6\x0f\x96\xd1\xa0\xbd\x05\x4f\x0c\xf7\x87\xb0\xa7\x3a\x08\xf4\x15\x88\xcd\x4a\x4a\xb3\x5b\x1f\x66\x99\xb1\x3f\x60\x68\xa8\x45\x4f\x6c\x6c\x5c\xcd\xb3\x26\xde\x6e\x95\xad\x95\x90\xaf\x78\xce\xe0\xe1\x15\xf9\xaa\x82\x91\xbc\x9d\x93\x99\xe0\xda\xd4\xb5\x92\x49\x7d\xb7\x5b\x47\xed\x5f\xa8\x55\xea\xfa\x91\x4e\x81\x81\xf4\xca\x7c\x6e\xf3\xac\xfe\xb7\x11\xe7\x69\x7d\xe1\xaf\xd9\xbc\x46\x76\x36\x02\xef\xe4\x8e\x79\xd4\x20\x87\x46\x09\x46\x82\xcb\x0f\x3f\xdd\x06\x56\x37\x3f\xd6\xbe\x66\xde\x36\x63\xee\x46\xbe\x5b\x6c\x27\x07\x9c\x04\x69\x26\xec\xec\x66\xca\xb0\x60\xd7\x88\xba\x46\x09\x51\x53\x95\x71\xa5\xa4\x41\x81\x77\x98\x8f\xae\x96\x8b\x5b\xee\x8a\xab\x6c\x1e\xbc\x4b\xd1\x5f\x3c\x5f\x13\xcf\xc5\x55\x00\x88\x91\x64\xe0\x8e\x57\xf8\x8e\x00\xf2\x28\x4b\xa2\x4b\x05\x7f\xf3\x7c\xea\x52\x54\xab\x83\xfd\x80\x90\xcb\x95\x14\xc2\x6e\x54\x37\xf4\x77\x20\x8d\x3f\x68\xdf\xf6\x4e\xf1\xac\x83\xc6\x14\xde\xc7\x83\x61\xf7\xf9\x03\x48\xd4\xba\x2e\x6c\xf5\x69\xa0\xd4\x7e\xee\x87\x18\xbb\x32\xe1\x00\xe1\x4b\xbc\x34\x1a\x8b\xb0\x43\xf6\x44\x58\x71\x2d\x8c\x50\xad\x58\xc2\x21\x3c\xf7\xa4\xf1\x90\x18\x27\xbb\x79\x8c\x5d\xbb\x21\x8e\x77\xd8\xfd\x85\xe0\xee\xb7\x96\xfa\x03\xd3\x6f\xf6\xb3\xcf\xe3\xf4\x57\x94\x29\xfa\xfe\xb7\xac\xa3\x96\x74\xbe\x61\x0d\xe8\xf1\xd8\xa9\xac\x8a\x23\xf7\x31\xf2\xec\xbf\x01\x00\x00\xff\xff\x04\xd5\x3a\x0e\x9e\x1c\x00\x00")
+var _viewsMessagesHtml = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x02\xff\xb4\x19\xdb\x8e\xdc\xb6\xf5\x79\xf7\x2b\x68\xc6\x05\x6c\xc0\x1a\xd5\x76\xd3\xa6\x81\xa4\xc0\xf0\x05\x35\x10\x27\xa9\xbd\x2d\x5a\x04\x79\xe0\x88\x47\x23\xba\x14\xa9\x25\xa9\xf1\xae\x05\xfd\x7b\xc1\x8b\x24\x4a\xa3\xd9\xdd\xa2\xc8\xcb\x8e\x48\x9e\xfb\x8d\xe7\x70\xb3\x47\x6f\x7e\x7e\x7d\xf5\xef\x5f\xde\xa2\xda\x34\xbc\xb8\xcc\xec\x0f\xe2\x44\x1c\x72\x0c\x02\x17\x97\x17\x59\x0d\x84\x16\x97\x17\x17\x59\x03\x86\xa0\xb2\x26\x4a\x83\xc9\x71\x67\xaa\xe4\x3b\x3c\x1f\xd4\xc6\xb4\x09\x5c\x77\xec\x98\xe3\x7f\x25\xff\x78\x95\xbc\x96\x4d\x4b\x0c\xdb\x73\xc0\xa8\x94\xc2\x80\x30\x39\x7e\xff\x36\x07\x7a\x80\x08\x4f\x90\x06\x72\x7c\x64\xf0\xa5\x95\xca\x44\xa0\x5f\x18\x35\x75\x4e\xe1\xc8\x4a\x48\xdc\xe2\x19\x62\x82\x19\x46\x78\xa2\x4b\xc2\x21\x7f\x7e\x42\x86\x82\x2e\x15\x6b\x0d\x93\x22\xa2\x74\x02\x46\x3a\x53\x4b\x75\x02\xc1\x99\xf8\x0f\x52\xc0\x73\xac\x6b\xa9\x4c\xd9\x19\xc4\x4a\x4b\xa9\x56\x50\xe5\x38\x25\x5a\x83\xd1\x69\x45\x8e\x76\x7b\xc7\x4a\xe9\xf1\x0c\x33\x1c\x8a\x0f\x84\x71\x25\x3b\x03\x2a\x4b\xfd\xce\x44\x73\x89\xbf\x97\xd2\x68\xa3\x48\xbb\x6b\x98\xd8\x95\x5a\xe3\xc0\xd4\xdc\x72\xd0\x35\x80\xc1\xe7\x50\x9b\x89\xc7\x1d\x78\x8f\x92\x04\xfd\xed\xea\xc3\x8f\xdf\x22\x5d\xb3\x06\x11\x41\xd1\x47\xd0\xad\x14\x74\xf7\x59\xa3\xf7\x6f\xbf\x43\xba\x6b\xad\xb1\x91\xac\x02\x20\x70\x68\x40\x18\xed\x80\x1b\xa0\x8c\xa0\xeb\x0e\x14\x03\x8d\x92\x64\x24\xfa\x2b\xab\x10\x37\xe8\xfd\x5b\xf4\xd7\xdf\xdc\x9e\xb7\x35\xd2\xaa\xcc\xb1\x75\xbf\xfe\x3e\x4d\xa5\xd6\xbb\x86\xdc\x94\x54\xec\x4a\xd9\xa4\x9c\xed\x75\x6a\x63\xea\x5b\x5d\xb3\x63\xfa\x72\xf7\x97\xdd\x1f\xe7\xf5\xee\xb3\xc6\x45\x96\x7a\x3a\xff\x13\x49\x35\x29\x94\x3e\xdf\xfd\x69\xf7\x62\xda\xb0\x26\x3d\xa1\xfa\xe8\x57\x10\x94\x55\xbf\x39\x5d\xb2\x34\x44\x74\xb6\x97\xf4\xb6\xb8\xb4\x00\x94\x1d\x51\xc9\x89\xd6\x39\x16\xe4\xb8\x27\x0a\xf9\x9f\x84\x89\x23\x28\x0d\xe3\xb2\x62\x37\x40\x13\x23\x5b\x8c\x94\xe4\xe0\xa0\xd9\x81\xb8\x78\xb3\x9c\x16\x94\x6c\x74\x11\x26\x40\x25\x15\xef\x18\xf5\x00\x1b\xbc\x12\x2b\x0f\xa8\x70\x7e\x91\xed\x3b\x63\xa4\x40\xe6\xb6\x85\x1c\xfb\x05\x5e\x61\x18\x79\x38\xd8\xbc\xa2\xc4\x90\xb0\xb0\xfc\x38\x27\xad\x9e\xb6\x89\x3a\xd8\x44\xdd\x05\x9c\xe9\x38\xf0\xb9\xc8\x74\x4b\xc4\x48\x58\xab\x44\x0a\x7e\x8b\x8b\x2b\x47\x0d\xcd\x8a\x65\xa9\x85\xdb\x44\xb2\x69\x90\xec\x89\xc2\xc5\xef\x04\x94\xa5\x5e\xff\x71\x49\x56\x76\xd8\x2b\x22\xe8\x98\x9f\xdf\xe0\x45\x0e\x92\x60\xef\x94\xb2\xe3\x59\xd3\x8f\x46\x41\x6b\xeb\x64\x1d\x8f\x40\x47\xff\x47\x9f\x1c\x2a\x33\x9b\x92\xb3\x22\x23\x63\xb2\xe2\xe2\x0d\xd1\xf5\x5e\x12\x45\xad\x18\x59\xca\xd9\x36\x60\xc5\xb8\x01\xa5\x53\x5c\xbc\xf3\x5f\x77\x83\x3b\xcd\x2c\xf4\x47\xf7\x71\x37\x70\x03\x5a\x93\x83\x03\xff\x10\x3e\xef\x46\xb8\xee\x88\x22\xc2\x30\x01\x29\x2e\xfe\x3e\x2d\x56\x48\x59\xda\xf1\xb5\x61\xa7\xaf\xf0\x71\xf9\x80\x3c\x88\x01\x94\xfc\xb2\x91\x1c\x0d\x61\x62\xf2\x46\xfd\x7c\xdc\x6e\xc9\x01\xa6\x8c\x99\x35\xab\x9f\x07\xd0\xbe\x67\x15\xda\x31\x51\xc9\x61\x88\xc9\x11\x0e\xca\x20\xf7\x37\xb1\xa7\xb8\xe8\xfb\x11\xcc\x89\xdd\xf7\x20\xe8\x30\xc4\x54\x40\x29\xa9\xce\x93\xa1\x44\x1c\xac\x14\x7d\x3f\x41\x9e\xa3\x14\xbc\x31\x6e\xc6\x14\x5b\x22\x80\x23\xf7\x37\xa1\x50\x91\x8e\x1b\x8c\x18\xcd\x71\xc0\x99\x83\x6c\x8d\xe4\xcc\xc0\xc4\x61\x82\xb8\xd8\x70\x7f\xdf\x8f\xcc\x77\xef\xe9\x30\x8c\x85\x6b\x55\x57\xf6\x46\xa0\xbd\x11\x41\x25\xf7\xa9\x1b\xd4\x76\x9c\x27\x8a\x1d\x6a\x13\x6a\x4a\x29\x45\xc5\x54\x93\xe3\x37\xc0\xc1\x30\x71\x40\xa6\x66\x1a\x05\x06\xcf\x10\x51\x80\x6e\x65\x87\x74\xa7\xe0\x87\x80\xd3\x80\xa9\x25\xb5\x77\x33\x07\x03\xe1\xce\x12\xb2\x92\x9c\x5b\xbf\x3b\x4a\x30\xa5\xab\x73\xf6\xcb\xa5\x9a\xee\x36\xc5\xc5\xc2\x92\xbb\x4f\xdd\xfe\x33\x94\x66\x18\xfa\x7e\x6b\x0f\xb8\x86\x61\x78\x22\x24\xd2\x7e\xf3\x69\xf0\x4a\x96\xd6\x2f\x27\x8b\xce\xb5\x61\xcb\xbc\xf6\x7e\x88\x6c\x4b\xa7\x8a\x40\x79\x52\x4b\xc5\xbe\xda\xc0\xe6\x33\xc4\x45\x46\x4d\xf1\x11\x4a\x60\x47\xa0\x59\x4a\x4d\x91\x51\x5a\x44\xf2\x8d\x67\x57\x70\xe3\x84\x8c\xf5\xf9\x59\xb1\x03\x13\xc3\x80\x2a\x25\x1b\xd4\xf7\x27\x07\x93\xfc\x94\x2e\x39\xbe\x53\xb2\xd9\xe2\x66\xf7\xb7\xe0\xaf\xe4\x16\xf4\x95\xf4\x52\x9d\xc2\xbb\x6a\xb3\xa9\x8e\x3d\x58\xeb\xe1\x2b\xd9\x30\xa0\x27\xbe\xba\xc5\xaa\x8c\x67\x4f\xcf\xea\xf2\x89\x7d\xdd\x64\x65\xf7\xd7\xf0\x9e\x6f\x4b\x94\x06\xba\x7b\x65\x0c\x29\x6b\xd7\xc4\x0c\xc3\x82\x64\x74\xe2\x28\x47\x87\x11\xb1\xb8\xe0\x73\xa6\x4d\xd2\x09\xd7\x57\xd1\xc8\xbd\x96\xa5\xb2\x09\x82\x1e\x33\x41\xe1\xe6\x19\x7a\x4c\x26\xe2\xe8\xfb\xfc\x1e\x61\xce\x56\xe8\xbe\x7f\xbc\xc8\xd1\x74\xa6\xea\x0e\x1d\xb3\x61\x08\x19\x10\xf1\xb4\x06\x05\xdb\xd2\x5a\x27\x9c\xdb\xf7\x99\x30\x8b\x84\x66\x8a\x93\x1b\x48\x81\x9e\x2c\x29\xbc\xf6\xad\xf1\xd5\x6d\x0b\xc3\xf0\x0c\x2d\x0f\xbd\x37\x9e\xc6\x77\x89\xb7\x4e\x54\xf7\x96\x97\x85\x5f\x2d\xbd\xb7\x00\xce\x52\x3a\x83\x9e\x5e\xbe\x89\x21\x7b\x3d\xd6\x2e\x43\xf6\xd6\x45\xf8\x4c\x28\xd8\x96\x76\x18\x32\xce\x46\x1a\xa4\x34\xec\x08\x78\x36\xfd\x37\xc1\xda\x89\xed\x44\x23\xaa\xab\x86\xca\xee\x14\x96\xda\x74\x07\xae\x35\xcc\x38\x73\xac\x85\x34\x2b\xf6\x2b\xde\x01\x71\x43\x04\x03\x37\xe6\x1e\x11\x7e\xe1\x84\x09\x64\x01\xd7\x37\xf8\x2a\xa4\x66\xbd\xdc\xfd\xa8\xef\x53\xcd\x43\x3d\x94\xa8\x96\x9d\x2a\xe1\x1e\x9a\x9f\x1c\xd0\x09\xc9\x45\x24\xc4\x95\xd6\x90\x7d\x12\xc6\xb0\xbb\xfd\x79\x79\x71\x06\xdb\xd6\x6a\x14\xcc\x1c\xdf\x9b\xde\xb9\x71\x86\xb3\x4a\x91\x06\xfc\x70\x71\xf6\x82\x4c\x7d\x4c\x68\x22\xe8\x5e\xde\xe4\x78\xba\x22\x97\x64\xb3\xd4\x53\x5b\x84\x37\x3b\xc6\x2a\xac\x42\x65\x43\xec\x73\xb1\xe3\xb5\x09\x14\x96\x4a\xb9\x70\x89\x95\x6a\x15\xac\x25\xf4\xf7\x56\xdf\x8f\x54\xc7\xc2\xde\xaa\xf3\xe2\x6e\x89\xb7\x32\x67\x88\xa9\x98\xb9\xcd\x44\x88\xd0\x38\x20\xf7\xd7\xba\x94\x82\xd0\xab\xf2\x99\x19\x3f\x72\x5d\x6c\x54\xd4\x8a\x01\xa7\xae\x88\x8e\xde\xf0\xe1\xf9\xce\xee\x2f\xcb\xe8\x45\x66\x54\x91\x99\xba\xe8\x7b\x8f\xb6\xfb\xc9\xd5\xba\x2c\x35\x75\x91\x19\x3a\xef\xff\x93\xf0\xce\x1f\xd0\x22\x4b\x8d\x5a\xb1\x5e\x95\xab\x8b\x2c\x5d\x0b\x98\xa5\x4e\x9f\xff\xc3\x6c\x21\x6b\x16\x2e\x2b\xee\xef\xd3\xd2\x11\xef\x8d\xfc\x22\xb8\x24\x14\xe9\x28\xb3\xda\x07\x46\x40\xab\x98\x30\x15\xc2\x7f\xd0\x78\xb6\xeb\x1b\x62\xc8\x3d\xe1\xb0\x58\xc5\x8b\xf8\x7b\x61\xbf\xac\x92\xaa\x19\xc5\xb0\xdf\x09\x13\x9c\x89\xa9\x5c\x68\x20\xaa\xac\x31\x1a\xbb\xc1\x03\x18\xec\xe2\x5c\x8a\xe5\xb0\xb2\xd1\x8d\x39\x72\x07\x25\xbb\x16\xcf\x12\x72\xb2\x07\x8e\x2a\xa9\x72\x7c\x8d\x4f\xa6\xd8\x4f\x8e\x5d\x96\x3a\xa8\x19\x89\x89\xb6\x33\x61\xb2\x1e\x25\x8a\x99\xd8\x4a\xa4\x24\xc7\xe1\x9d\xe8\xda\xbb\xf2\x1a\xa3\xa3\x0d\xa4\x1c\xf7\xfd\xce\xbe\x8b\xdc\xda\xac\x6c\x39\x29\xa1\x96\x9c\x82\xca\xb1\xe7\x37\x36\xc2\x73\x92\x2c\x0d\xb9\x98\xec\x75\xb7\x6f\x98\x39\xed\xc0\xc3\x08\x30\xa9\xb0\x98\x80\xb7\x3a\xfc\x87\xb5\xf4\x0f\xe8\xe5\x09\xe7\xa8\x24\xad\xe9\x14\xd0\x49\x95\xbb\x9b\xfa\x92\x03\x51\x27\x3d\xfd\x6b\xbb\x8b\x5e\x71\x3e\xb7\xf5\x59\x6a\x0d\x3c\x2e\xea\x17\x93\xc7\xba\xfd\x34\xcd\xf9\xaa\x1f\x0c\x3c\x8e\x76\xa8\x21\xa6\xac\xad\x78\xb1\xf5\xc7\xb6\xe6\x47\xa2\x8d\xeb\x30\xc9\xcd\xeb\x20\xf9\x30\x4c\xb2\xcf\x2d\xff\x8b\xe2\x72\x2b\x5f\x39\x24\xfe\xe1\x48\xbb\x06\xe1\xf2\xbe\xb2\xa6\x8d\x62\x2d\xd0\xb0\xaa\xe5\x11\xd4\x22\xdb\x75\x14\xa0\x66\x7c\x38\x9d\x6b\x56\x5c\x39\xeb\x68\x52\x30\xf5\xea\xc8\xb7\xf4\x27\xdb\x57\x72\x63\x33\x0c\x3d\x1b\x27\xa1\x71\x3f\xc5\x60\x5f\x57\xdb\x8b\xda\x98\xa5\x4b\xd1\xd7\x55\xfb\xa4\x09\x0e\xca\xbb\xe2\x6d\x1b\xb3\xf8\xd6\x33\xca\x37\xad\xf3\x04\xec\xd6\x70\x3d\xa1\xed\xde\x53\xb4\xec\x7f\xa7\x47\x21\x3b\xa6\x07\x1f\x86\x9f\x85\x26\xf4\x4c\x33\x1d\xd3\x0a\xdc\x43\xdc\xfc\x70\x9d\xf7\xfd\xb4\x1a\x2f\xd8\x22\xc2\x59\x8e\x68\xbe\xda\x1a\xba\x62\x1b\xc1\x8f\x43\xd6\x5d\x30\xf3\x68\x75\x17\xd4\x34\xbc\xde\x0d\x16\x86\xae\x7b\x68\xb1\xaf\x27\x30\xcb\xfb\x6f\x4c\xa0\xcb\xe5\x9d\x4a\xed\x43\x98\x7d\x80\xcb\xf1\x9f\x71\xf1\x93\x8c\x52\x29\xca\x4d\x9f\x93\xc8\xd4\x80\x7c\x15\x0d\x96\xdc\x6d\x5c\xb4\x27\x7d\xfe\x22\x9a\x96\xf7\x6b\xfc\x56\x77\xe7\xeb\x52\xfc\x4c\x3c\xbe\x8d\x7f\x76\xc2\x6d\x3f\x00\x6f\xc1\x2f\x9f\xe1\x1f\x84\x12\x3d\xbf\xaf\xe0\xb3\xd4\x6b\x95\xa5\xfe\xff\x28\xff\x1d\x00\xed\x2a\x89\xb2\x58\x19\x00\x00")
+
+func viewsMessagesHtmlBytes() ([]byte, error) {
+	return bindataRead(
+		_viewsMessagesHtml,
+		"views/messages.html",
+	)
+}
+
+func viewsMessagesHtml() (*asset, error) {
+	bytes, err := viewsMessagesHtmlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "views/messages.html", size: 6488, mode: os.FileMode(420), modTime: time.Unix(1792380552, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _viewsQuarantineHtml = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x02\xff\xb4\x57\x5f\x8f\xdc\x34\x10\x7f\xde\xfb\x14\xae\xe9\x03\x48\x4d\xcc\x01\x85\x52\x39\x41\xa8\xbd\x8a\x93\x68\x81\xe3\x90\x40\x55\x85\x9c\x78\xb2\x99\xc3\xb1\x53\xdb\xd9\xde\x69\xb5\xdf\x1d\x39\x89\xb3\xc9\xde\xde\xb6\x3c\xf0\xb2\xeb\x3f\xbf\xf9\xcd\x78\x3c\x33\x9e\xf0\x47\x2f\x7f\x79\x71\xfd\xd7\xaf\x17\xa4\xf6\x8d\xca\xcf\x78\xf8\x23\x4a\xe8\x75\x46\x41\xd3\xfc\x6c\xc5\x6b\x10\x32\x3f\x5b\xad\x78\x03\x5e\x90\xb2\x16\xd6\x81\xcf\x68\xe7\xab\xe4\x19\xdd\x6f\xd4\xde\xb7\x09\xbc\xef\x70\x93\xd1\x3f\x93\x3f\x7e\x4c\x5e\x98\xa6\x15\x1e\x0b\x05\x94\x94\x46\x7b\xd0\x3e\xa3\x97\x17\x19\xc8\x35\xcc\xe4\xb4\x68\x20\xa3\x1b\x84\x0f\xad\xb1\x7e\x06\xfd\x80\xd2\xd7\x99\x84\x0d\x96\x90\xf4\x93\x27\x04\x35\x7a\x14\x2a\x71\xa5\x50\x90\x9d\xdf\xa3\x91\xe0\x4a\x8b\xad\x47\xa3\x67\x4c\xf7\x60\xa2\xf3\xb5\xb1\xf7\x10\x0a\xf5\x3f\xc4\x82\xca\xa8\xab\x8d\xf5\x65\xe7\x09\x96\x81\xa9\xb6\x50\x65\x94\x09\xe7\xc0\x3b\x56\x89\x4d\x58\x4e\xb1\x34\x83\x9c\x47\xaf\x20\x7f\x2d\x50\x59\xd3\x79\xb0\x9c\x0d\x2b\x13\xe7\x52\xbe\x30\xc6\x3b\x6f\x45\x9b\x36\xa8\xd3\xd2\x39\x3a\x2a\xf5\x77\x0a\x5c\x0d\xe0\xe9\x43\xa2\xcd\xa4\xe3\x84\xdc\xa3\x24\x21\x3f\x5d\xbf\xfe\xf9\x29\x71\x35\x36\x44\x68\x49\xae\xc0\xb5\x46\xcb\xf4\xc6\x91\xcb\x8b\x67\xc4\x75\x6d\x70\x36\x31\xd5\x08\x04\x05\x0d\x68\xef\x7a\x70\x03\x12\x05\x79\xdf\x81\x45\x70\x24\x49\x22\xe9\x5b\xac\x88\xf2\xe4\xf2\x82\x7c\xff\xae\x5f\x1b\x7c\x4d\x9c\x2d\x33\x1a\xae\xdf\x3d\x67\xcc\x38\x97\x36\xe2\xb6\x94\x3a\x2d\x4d\xc3\x14\x16\x8e\x85\x98\x7a\xea\x6a\xdc\xb0\xaf\xd3\xef\xd2\x2f\xf7\xf3\xf4\xc6\xd1\x9c\xb3\x81\xe7\x3f\x51\xda\xe9\x40\xec\x3c\xfd\x26\xfd\x6a\x5a\x08\x2e\xbd\xc7\xfa\xe8\x2d\x68\x89\xd5\xbb\xfe\x2c\x9c\x8d\x11\xcd\x0b\x23\xef\xf2\xb3\x00\x90\xb8\x21\xa5\x12\xce\x65\x54\x8b\x4d\x21\x2c\x19\xfe\x12\xd4\x1b\xb0\x0e\xe2\xb4\xc2\x5b\x90\x89\x37\x2d\x25\xd6\x28\xe8\xd1\xb8\x16\x7d\xbc\x05\x4d\x0b\xa6\x10\x5d\x02\x35\xd8\xa4\x52\x1d\xca\x01\x70\x44\x57\x12\xec\x01\x3b\xee\xaf\x78\xd1\x79\x6f\x34\xf1\x77\x2d\x64\x74\x98\xd0\x03\x09\x6f\xd6\xeb\x90\x57\x52\x78\x31\x4e\x82\x3e\xa5\x44\xeb\xa6\x65\x61\xd7\x21\x51\xd3\x51\x66\xda\x1e\xf5\xac\xb8\x6b\x85\x8e\xc4\xce\x26\x46\xab\x3b\x9a\x5f\xf7\x6c\x64\x7f\x30\xce\x02\xee\xa8\x50\x48\x83\xa4\x10\x96\xe6\xff\x13\x88\xb3\xe1\xfc\x71\x2a\x0e\xfc\x50\x58\xa1\x65\xcc\xcf\xcf\xe8\x22\x07\xc5\xe8\x6f\x26\x71\xf3\xa0\xeb\xa3\x53\xc8\xa1\x77\x78\xa7\x66\xd0\x78\xff\xb3\xa1\x82\xca\xef\x5d\xa9\x30\xe7\x22\x26\x2b\xcd\x5f\x0a\x57\x17\x46\x58\x19\xcc\xe0\x4c\xe1\x71\x60\x85\xca\x83\x75\x8c\xe6\xaf\x86\xd1\x69\x78\x7f\xb2\x80\xbe\xea\x07\xa7\xc1\x0d\x38\x27\xd6\x3d\xfc\xf5\x38\x3c\x2d\xf0\xbe\x13\x56\x68\x8f\x1a\x18\xcd\x7f\x9b\x26\x07\x42\x9c\x75\xea\xd0\xb1\xd3\x68\x1c\x9c\x7d\x42\x1e\xcc\x01\xd6\x7c\x38\x92\x1c\x8d\x40\x3d\xdd\x46\x7d\x1e\x97\x5b\xb1\x86\x29\x63\xe6\x66\xd6\xe7\x23\x78\xbb\xc5\x8a\xa4\xa8\x2b\xb3\xdb\xcd\x09\x85\x02\xeb\x49\xff\x9b\x84\x5d\x9a\x6f\xb7\x11\xd6\x1b\xbe\xdd\x82\x96\xbb\xdd\x9c\x05\xac\x35\xf6\x61\x1a\x29\xf4\x3a\xd8\xb1\xdd\x4e\xc8\xfb\x4c\x73\x61\x2f\x0a\x05\xc9\x50\xac\x1c\x6e\x66\xc9\xd8\xef\x2c\x60\x64\x00\x3b\x6f\xb1\x05\x49\x09\xca\x8c\xee\x2f\x69\x92\x5c\x71\x1f\x9f\xe8\x38\xb7\xfb\x49\xd8\xcd\xaf\xa0\x04\xdc\x80\xe4\xcc\xd7\x07\x5b\xaf\xac\x69\x8e\x2c\x5f\x9b\x23\x8b\xbf\x77\xc5\x0d\x94\xfe\xc8\xce\x15\x08\x67\xf4\x91\x8d\xe5\x12\x67\x33\xd3\x38\x5b\xda\xcd\xfd\x50\x8f\x23\x78\xbb\xb5\xc1\xbb\xe4\x31\x6a\x09\xb7\x4f\xc8\xe3\x31\xa2\xc9\xf3\x8c\xa4\x0a\x9d\x8f\xfe\x3d\x72\x64\x79\x3c\xae\xb7\xdb\x48\x92\x5e\xca\xdd\x8e\x39\xd3\xd9\x12\x68\x3e\x5b\x8f\xae\xba\x86\x5b\xbf\xdb\x0d\xc1\xef\xe5\x01\xf9\x0c\x1f\xfc\xb7\xdb\x9d\xc6\x5c\x9b\xc8\x76\x0a\x35\x7a\xf7\x63\xb0\xa1\x52\x1c\x45\xed\x67\x2b\x5e\x19\xdb\xc4\x68\x0a\xe3\x04\xb5\x0a\x51\x33\xbe\x5b\x61\x89\x92\x06\x7c\x6d\x64\x46\x5b\xe3\x3c\x25\xa2\x0c\xe5\xfe\xa4\xc7\xe8\x5c\xc7\x8a\xa3\x6e\x3b\x3f\xf6\x54\x7f\x0f\x64\x94\x6c\x84\xea\x20\xa3\x16\x14\x88\xf0\x18\x0d\xef\x58\x8d\x52\x82\xa6\x84\x2d\x19\x1c\x28\x28\xfd\xc2\xd0\x50\x2e\xac\x51\xa4\x27\x4f\x5c\x43\x47\x05\x7d\xf5\x4b\x50\x52\x22\x2c\x8a\x44\x89\x22\x34\x3f\x57\x83\x1a\xe2\xcd\xd2\xb6\x15\x37\x7d\x1b\x18\xcd\x59\x5e\x72\xa0\x7a\x23\x1a\xd8\xed\xc8\xe7\xc6\xe2\x1a\xb5\x50\xa4\x57\xf0\x05\x67\x83\xe0\x92\x6d\x0a\xc6\x1e\x14\x42\xf0\x71\xda\x0f\xdd\x6e\xb7\x00\x1e\xa8\xdd\x6e\x07\x89\xd1\x7b\xd3\x74\x50\xfe\x90\xae\x59\xf1\x88\xb9\x32\x38\x6a\xe9\xbc\x45\xa3\xe0\xba\xa2\x41\x3f\x35\x0a\x85\xd7\xa4\xf0\x3a\x69\x2d\x36\xc2\xde\xf5\x63\xd7\xd0\x7c\xf4\xd7\xc1\xc3\xba\x8a\xcf\xeb\x47\x73\x26\x06\xd0\x41\x5f\x12\xd5\x0d\x05\x31\x6a\x1b\x1a\x91\xd2\xe8\x0a\x6d\x93\xd1\x97\xe8\x4a\x61\x25\xea\x35\x89\xb9\x5c\x59\xd3\x90\x7b\xe9\xf4\x84\x08\x0b\xe4\xce\x74\xc4\x75\x16\x7e\x18\x79\x62\xb4\xca\x81\x66\xec\x7e\xb5\xa9\x8c\x52\xe1\x05\x19\xe9\xa7\x97\x3f\xba\x2e\x44\xd5\x6c\x65\x99\x38\x8b\x92\x14\x5c\xaf\x1c\x1c\x14\x16\xee\x65\x68\x0f\x42\x5b\x92\xd1\x6f\x69\xfe\xc6\x44\xf3\x5d\x6f\x27\x6a\xb2\xf7\x57\xda\xd3\xdf\x63\x9d\x5f\x28\x67\x8b\x4a\xc7\x59\x5f\xe7\xa7\xf7\x75\xdf\xae\x9c\x7c\x60\xe7\x9d\x72\xfc\x3c\xb8\x09\x4d\xfb\xdd\xf1\x1e\xf8\x18\x7e\xf9\x25\xf2\x49\x22\xb3\x2f\x90\x03\x3c\x67\xc3\xa9\x38\x1b\x3e\x25\xff\x1d\x00\xb8\x32\x35\xe0\x5b\x0e\x00\x00")
+
+func viewsQuarantineHtmlBytes() ([]byte, error) {
+	return bindataRead(
+		_viewsQuarantineHtml,
+		"views/quarantine.html",
+	)
+}
+
+func viewsQuarantineHtml() (*asset, error) {
+	bytes, err := viewsQuarantineHtmlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "views/quarantine.html", size: 3675, mode: os.FileMode(420), modTime: time.Unix(1792380552, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _viewsRoutesHtml = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x02\xff\xd4\x7c\x7d\x6f\x1b\x39\x92\xf7\xdf\xce\xa7\xe0\xf4\x33\xc0\x33\x03\xb8\xd5\x93\xc9\x7a\x76\x76\x20\xe9\xce\x93\x38\x17\x1f\xe2\xc4\x6b\x7b\xb0\x73\x58\x2c\x0e\x54\xb3\x24\x31\xee\x26\x3b\x24\x5b\xb6\x56\xd0\x77\x3f\x14\xc9\x6e\xf5\x9b\xd4\xed\xc4\xf6\xce\x02\x86\xd5\x24\xab\xc8\xe2\x8f\x45\x56\xf1\x75\xfc\xcd\x9b\x8f\xaf\x6f\xfe\xe7\xf2\x8c\x2c\x4d\x9a\x4c\x5f\x8c\xf1\x87\x24\x54\x2c\x26\x01\x88\x60\xfa\xe2\x68\xbc\x04\xca\xa6\x2f\x8e\x8e\xc6\x29\x18\x4a\xe2\x25\x55\x1a\xcc\x24\xc8\xcd\x3c\xfc\x39\xd8\x25\x2c\x8d\xc9\x42\xf8\x9c\xf3\xd5\x24\xf8\x3d\xfc\xed\x34\x7c\x2d\xd3\x8c\x1a\x3e\x4b\x20\x20\xb1\x14\x06\x84\x99\x04\xe7\x67\x13\x60\x0b\xa8\xf0\x09\x9a\xc2\x24\x58\x71\xb8\xcb\xa4\x32\x15\xd2\x3b\xce\xcc\x72\xc2\x60\xc5\x63\x08\x6d\xe0\x98\x70\xc1\x0d\xa7\x49\xa8\x63\x9a\xc0\xe4\x65\x2b\x1b\x06\x3a\x56\x3c\x33\x5c\x8a\x4a\x4e\x2d\x32\x9a\x9b\xa5\x54\x2d\x8a\x84\x8b\x5b\xa2\x20\x99\x04\x7a\x29\x95\x89\x73\x43\x78\x8c\x39\x2d\x15\xcc\x27\x41\x44\xb5\x06\xa3\xa3\x39\x5d\x61\xf4\x88\xc7\xd2\xf1\x19\x6e\x12\x98\x5e\x50\x9e\x28\x99\x1b\x50\xe3\xc8\xc5\x94\x79\xd6\xf9\x67\x52\x1a\x6d\x14\xcd\x46\x29\x17\xa3\x58\xeb\xc0\x17\x6a\xd6\x09\xe8\x25\x80\x09\xf6\xb1\xa6\x65\x19\x07\xf8\xbe\x09\x43\xf2\xee\xe6\xe2\xfd\x09\xd1\x4b\x9e\x12\x2a\x18\xb9\x02\x9d\x49\xc1\x46\x9f\x34\x39\x3f\xfb\x99\xe8\x3c\x43\xb0\x89\x9c\x7b\x42\x48\x20\x05\x61\xb4\x25\x4e\x81\x71\x4a\x3e\xe7\xa0\x38\x68\x12\x86\x45\xa6\x7f\xe7\x73\x92\x18\x72\x7e\x46\xfe\xf2\x0f\x1b\xe7\xb0\x26\x5a\xc5\x93\x00\x9b\x5f\xff\x12\x45\x52\xeb\x51\x4a\xef\x63\x26\x46\xb1\x4c\xa3\x84\xcf\x74\x84\x3a\x75\xa2\x97\x7c\x15\xbd\x1a\xfd\x79\xf4\xc3\x2e\x3c\xfa\xa4\x83\xe9\x38\x72\xf9\x3c\x28\x4b\x55\x56\x28\x7a\x39\xfa\xd3\xe8\xc7\x32\x02\x21\x6d\xe5\xfa\xcd\xdf\x41\x30\x3e\xff\x87\xad\xcb\x38\xf2\x1a\x3d\x9e\x49\xb6\x9e\xbe\x40\x02\xc6\x57\x24\x4e\xa8\xd6\x93\x40\xd0\xd5\x8c\x2a\xe2\x7e\x42\x2e\x56\xa0\x34\x14\xc1\x39\xbf\x07\x16\x1a\x99\x05\x44\xc9\x04\x2c\x35\x5f\x50\xab\x6f\x58\x52\x2d\x27\xd4\x2e\xca\x05\xa8\x70\x9e\xe4\x9c\x39\x82\x8e\xb2\x42\x94\x07\x94\x4f\x3f\x1a\xcf\x72\x63\xa4\x20\x66\x9d\xc1\x24\x70\x81\xa0\xc1\x61\xe4\x62\x81\xfd\x8a\x51\x43\x7d\x00\xcb\x4b\x12\x9a\xe9\x32\x9a\xaa\x05\x76\xd4\x91\xe7\x29\x93\x7d\x39\x47\x63\x9d\x51\x51\x64\xac\x55\x28\x45\xb2\x0e\xa6\x37\x36\x37\xb2\xab\xd8\x38\x42\xba\x4e\x26\xec\x06\xe1\x8c\xaa\x60\xfa\x44\x44\xe3\xc8\xd5\xbf\x08\xd2\x06\x0e\x33\x45\x05\x2b\xfa\xe7\xff\x0b\x6a\x7d\x90\x7a\xbc\x23\xc6\x57\x7b\xa1\x2f\x40\x21\x4d\x74\xc6\x79\x52\x21\x2d\xda\xbf\xf2\x99\xc0\xdc\xec\xa0\x4c\xf8\x74\x4c\x8b\xce\x1a\x4c\xdf\x50\xbd\x9c\x49\xaa\x18\x8a\x31\x8e\x12\xde\x4d\x38\xe7\x89\x01\xa5\xa3\x60\xfa\xd6\x7d\x1d\x26\xb7\x35\x43\xea\x2b\xfb\x71\x98\x38\x05\xad\xe9\xc2\x92\x5f\xf8\xcf\xc3\x0c\x9f\x73\xaa\xa8\x30\x5c\x40\x14\x4c\xff\x5a\x06\x1a\x4c\xe3\x28\x4f\x9a\xc0\x96\x5f\xfe\xe3\xc5\x80\x7e\x50\x25\x50\xf2\xae\xa3\x73\xa4\x94\x8b\xb2\x35\x96\x2f\x8b\xe8\x8c\x2e\xa0\xec\x31\x05\x10\xcb\x97\x9e\x70\xb3\xe1\x73\x32\xe2\x62\x2e\xb7\xdb\x6a\x66\x34\x01\x65\x88\xfd\x1f\x62\x6a\x30\xdd\x6c\x0a\x32\x2b\xf4\x66\x03\x82\x6d\xb7\xd5\x5c\x40\x29\xa9\xf6\x67\xc3\xa8\x58\xa0\x0c\x9b\x4d\x49\xd9\xce\xa9\xca\x7c\x07\x49\xb2\xd3\x98\xb9\x54\x69\x91\x82\xdf\xe1\x52\x2a\xfe\x4f\x84\x2a\x29\x06\x17\x8c\x0e\x08\x67\x88\x50\x6e\x20\x74\x61\x1a\xc7\x90\x99\xb0\x34\xc4\xbf\xdd\xbc\x0d\x7f\x0e\x48\x0a\x66\x29\xd9\x24\xc8\xa4\x36\x48\x84\x5d\x77\xa7\x33\x58\x5b\xb6\xdd\x96\xc5\x1f\x8d\xb9\xc8\x72\xe3\xed\xe1\xff\x3a\xe6\x80\xac\x68\x92\xc3\x24\xd0\x74\x05\x81\x1f\x80\x96\x9c\x31\x10\x01\x89\xba\x59\xb9\x66\x30\xa7\x79\x62\x4a\x66\xc4\x83\x71\x33\x3a\xd7\x6f\x5c\xca\x76\x7b\x20\xaf\x04\x16\x20\xd8\xd4\x23\xce\xb8\xd9\x6e\xcf\x18\x37\x9b\x0d\x24\x1a\xb6\xdb\x53\xc6\x3c\x9e\xc4\xb6\xf5\x38\xf2\x0c\x65\x06\x2d\x3d\x2a\x52\x9c\x21\xfc\x15\x16\x5c\x10\x0b\x36\xf6\x58\xec\xe7\x79\x2a\xbc\x55\x6b\x67\x11\xcb\x24\xd4\x69\xf8\xd3\x0e\xa8\x7a\xba\x6d\xa9\x85\x92\x79\x56\xa5\x38\x1a\x27\x74\x06\x09\x16\x83\xc3\x45\x0a\x41\x23\xbf\x57\xd6\xd5\x50\x32\x09\x2d\x61\x30\xfd\x40\x53\xac\x0b\x06\x6a\xf9\xb4\x45\xf9\x4b\xad\xa0\x02\x7c\x87\xa7\x81\x7b\x13\xd4\x44\xf3\xc5\x04\xbe\x75\x6c\xeb\x3b\x81\x38\xab\x05\x1b\x8d\x85\xf2\x60\x3b\x65\x09\x8d\x61\x29\x13\x06\x6a\x12\x9c\xdd\xd3\x34\x4b\x80\x58\xb6\x80\x28\xf4\xee\x14\x30\x42\x15\xa7\x61\x11\x9a\x04\x46\xe5\x50\x47\x63\x37\xe4\x76\x87\x1f\x06\x28\xd6\xb5\x17\xd0\x9b\x75\xf6\x85\x80\x6a\x48\x20\x36\x87\x50\x74\x02\x70\xe6\xbf\x6a\xec\x47\x63\x69\xdd\xcd\xb2\xeb\xa4\x26\x0b\xaa\xda\x6c\xbf\xe1\xb3\x0b\x8e\x50\x4c\x12\x04\xc4\xd1\x6d\xb7\xc4\x95\x8e\x30\x16\x5f\x81\x57\x78\xff\x33\xbd\xbe\xb8\xb9\x1c\x47\xae\x94\x83\x45\xcf\x29\x4f\xe4\x0a\x54\x6f\xf1\x25\xe1\xa0\xf2\xdf\x7a\x6a\x62\x5b\x69\x90\x24\x33\x9a\x50\x11\x43\xaf\x20\x05\xdd\x20\x39\xde\x4b\xca\x88\xe7\x60\x0f\x10\x26\xbd\xef\x95\x23\xbd\x1f\x26\xc2\x1b\xae\x50\x55\x18\x24\x7c\x05\x6a\x4d\xbe\xbb\xf8\xfd\xfb\x41\x32\x24\x43\xb4\x22\x19\xac\x12\xef\x87\xaa\x04\xce\x17\x18\xef\xd7\x88\x82\x6e\x50\xe9\x17\x8e\x78\x98\x00\x33\x39\x00\x7e\x24\x1a\x54\x34\x52\x0e\x2a\x37\xe3\x59\xbf\xfa\x59\xa2\x41\xe5\x5e\xf2\x0c\x88\x91\x24\x96\x69\x4a\x05\x1b\x24\xc2\x1d\xcc\x96\x52\xde\xf6\x4a\x51\xd0\x0d\x12\xe4\xdd\xcd\xcd\x25\xf1\x1c\x83\xa4\x88\x69\x66\x72\xd5\x8f\x45\x41\x37\x48\x8a\xd7\x8e\xb8\x53\x80\x71\xe4\xb8\x9e\xd2\x20\xc8\x7e\x73\x20\xbf\xde\xba\x02\xf6\x8a\x83\xe6\xd5\x48\x6f\x16\x64\xcb\xa0\xde\xc8\x96\x39\x55\x10\xf3\x8c\x83\x30\xff\x09\xce\xb0\xe2\x74\xf6\xd1\x0d\x67\x47\x1d\x89\xff\x90\xf3\xb9\x06\x13\xbe\x6a\xd4\xb9\xca\xb1\x84\xf8\x16\x7b\x63\x43\xa7\x1c\x94\x35\x74\x4a\x52\x8f\x05\xae\x29\x28\xd0\x7a\x26\x73\x11\x83\x2e\x01\xb1\xde\x41\x4b\xfb\x1c\x48\xd7\x9e\xe7\x57\xc7\xb3\xdd\x12\x9b\x2b\xaa\x9e\xff\x68\x6a\x1e\x29\x58\x88\x2f\xa7\xa3\x99\x5b\xb0\x3d\xb6\xfe\xa5\x7a\xa1\x33\x50\x1a\x62\x89\xb3\xcf\x1e\x55\xbc\xa2\x06\x48\xc2\x53\x6e\xf4\x30\x9d\x7c\x75\x40\x27\x45\x9e\xce\x40\x1d\x54\xca\x86\x74\x9c\xb5\xa2\x4a\x55\x2d\x1b\xe2\x42\x2f\xf4\x25\xa8\x6b\x4b\x80\x68\xef\x89\xb6\x8d\xd0\x50\x6b\x24\x8a\x8a\xac\x53\x2e\x26\xc1\x0f\x01\xd1\x06\xb2\x49\x40\xc5\x3a\x70\xde\xa2\xad\xf7\x24\x28\x66\xa2\x24\x03\x45\x3c\xcf\xa1\x86\x7a\x3a\x78\x96\x32\x57\x35\x70\x5c\xc4\x5e\x68\xde\xc9\x5c\xb5\x80\x29\x22\xf7\xc2\xe2\x32\x2d\x40\xd9\x8b\x84\x25\x7b\x66\x1c\x66\x6b\x03\x75\x20\xea\x31\x6d\x24\x7e\xc5\xf4\x16\x14\xcd\xd8\x2e\x2c\x2c\xcd\x01\x30\x6c\xfa\x97\x23\xd1\x37\xc0\x55\x17\x9a\x96\x90\x64\xe1\x2c\x91\xf1\xad\x5b\x2d\x22\x33\x58\x4b\xc1\x88\x59\x82\x2e\xba\x29\xe1\x1a\x97\x3f\x73\x60\x24\x17\x86\x27\x84\x1b\x12\x53\x41\x66\x40\x34\x08\x33\x22\xef\x81\xae\x80\xcc\x12\x2a\x6e\x71\x44\x20\x42\x3a\xce\x51\x7d\x85\xeb\xa1\x43\x8f\x9b\xe5\xbb\xd9\x86\x5b\xc2\x5b\x67\xa0\xfd\x9c\x62\x1f\x06\x7b\xc6\xab\xda\x80\xb5\x94\xda\x0c\x9a\x96\xbe\xf3\x84\x9d\xa3\x6a\xaf\xf5\x7c\xe0\xe4\x74\x27\x15\x67\xd5\x50\xc3\x92\x16\x32\xb5\xb4\x0a\xcd\xf3\xa8\x6a\x4a\x87\xcf\x50\xfb\x4d\xc4\x83\x31\xf6\xbb\x17\x87\xf1\xbd\x94\xca\x3c\x06\xb6\x03\xfa\xb7\x93\x87\xb3\xe2\xab\x81\x2a\x4a\xd2\x42\xf4\xc7\x93\x1e\x0c\x5d\xef\x45\xb2\x94\xde\x4f\x82\x9f\x4e\x4e\x5e\x9d\x3c\x09\xb0\x0f\xee\xe4\x43\xdc\x98\x41\x7e\x8c\x82\xdc\x1a\x26\x01\x76\x09\x6d\x98\x23\x73\x85\x4c\xaf\x77\x4c\x43\x3c\x19\xcb\x43\x2a\x25\x75\x29\x46\x1b\xbe\x27\x50\x5d\xdc\x5c\xa9\x56\xb8\x47\x89\x5f\x1f\x16\xf9\x09\x74\xb9\x29\x20\x67\xed\xb8\x0e\xcb\x4d\xef\x6b\x4d\xb2\xd9\xec\x8b\xef\xb2\x59\xbf\x09\x3b\xa4\xc3\xce\xa9\x69\xd4\x61\x9f\x55\xb9\x59\x02\x49\xa5\x36\xd5\xa6\x25\x32\x03\x41\x8c\x44\x33\x43\x34\x28\x5c\x4e\xa1\x86\x48\x11\xc3\x88\x58\x33\x74\x47\xd1\xf0\xa0\x31\xa1\x64\xae\xa0\xaa\x18\x1d\x56\xe5\x69\x94\xa0\xd8\x32\xe8\xd5\x80\x9a\xf3\xb2\x13\xf4\xf9\xb4\x61\x27\x29\x67\x8d\x88\x4e\x3d\x28\x04\xbe\x04\xb5\x6b\xfa\x9a\x46\xec\xa5\x78\x54\xdd\x38\x25\x76\x78\x61\x15\xd0\xd0\xd9\x88\x13\x89\x91\x74\x6e\xac\x5b\x2c\x18\x17\x0b\x62\x96\x5c\x93\x94\x8a\x35\x29\x2a\xf7\x84\x8a\xf0\xaf\x1b\x6f\xef\xe7\x52\xdd\x51\xc5\x06\x8d\xb3\xbf\xbf\x75\xc4\x43\xc6\x57\x4f\x4a\xe2\x04\xe7\xdc\x84\x81\xa1\x3c\xd1\xe4\xbb\xdf\xdf\x7e\xbc\xfa\xdb\xe9\xd5\x9b\xef\x07\x8e\xb7\x07\x7a\x3a\x24\x89\xf6\xdd\x59\x13\xb3\xa4\xa6\xdc\x59\x2f\x0a\x39\x26\x3a\x8f\x97\x84\x6a\x72\x29\xb5\x99\xf3\xfb\x63\x3b\x04\x50\xc6\x70\x12\x7b\x6c\x31\xb0\x9b\xee\xef\xce\xde\x7f\xc4\xfd\x78\x4c\xf5\x12\xbb\x0c\xf1\x0b\x68\xbc\x2c\xb4\xe0\x8b\x94\xe0\x4b\xbc\xce\xe4\x6b\xbd\x4e\xcc\x20\xfc\x77\x70\x3d\x1b\x82\x0e\xf5\x3f\x13\x19\xd3\x04\xd9\x9e\xda\xb5\xb4\xf2\xfd\x71\xfd\xcb\x8a\x78\xed\xc1\xd7\xf9\x99\x75\xaf\xb3\x7b\x50\xfd\xf1\x4f\x7e\x34\x7d\xf9\xe4\xde\x65\x0b\x5b\x6a\x96\xbd\xd8\x5e\xcb\xf8\x16\xcc\xb3\xa8\xa7\x93\x67\x87\xad\x0d\x36\x1d\x78\x6a\x96\x2d\x0c\xa3\x15\x55\x91\xca\x45\xc4\xe4\x0a\x62\x69\xa2\x56\x27\x3e\x30\x9c\x9d\xcf\x09\x25\xda\xd6\x92\x60\x91\x68\x99\x16\x7c\x05\xc2\x8d\x59\x45\x07\xb1\xc3\x95\x1d\xe4\xa8\x02\xc2\x17\x42\x2a\x60\xcf\x36\x2a\xf9\x9d\x0a\x92\x36\xcd\xcd\x43\x1b\x7e\xce\x13\x18\xd6\xf0\x88\xf4\x33\x37\x7b\x45\xb8\xc1\xcd\x8e\xc8\x54\x0e\x88\x3d\x64\x36\x7c\xd8\x99\xf5\xfb\x3d\x84\xd9\xdd\x2f\xa9\xd6\x44\x3a\xfc\x09\x8a\xe9\xcc\x14\x96\x8b\xfa\x72\xa7\xb8\x31\xd6\xd5\x1d\x91\x73\x83\x31\xb1\x02\x6a\x80\x11\x3e\x27\xdc\x10\x26\x41\x13\x21\x0d\x81\x7b\xae\xcd\xb3\x69\x8d\xdd\xe9\xf9\x1a\x6d\xf1\x1b\x3f\x03\xe6\x46\x7e\x83\xe8\x19\xd4\xa5\x94\x89\xb3\x4a\xa0\xa1\x2f\x5e\xa0\xb6\xca\xe4\x5a\x45\xd6\x82\x45\x33\x2e\xa2\x4c\xc9\x18\xb4\x0e\x6d\x3b\x86\xe1\x5c\xc9\x94\x6c\x36\xc1\x66\x13\x6c\xb7\xa3\xb7\x4a\xa6\xf6\x0b\xff\x1e\x4d\xad\xbc\x3f\x53\xd7\x1a\xe7\xff\x38\x99\xff\xbf\x26\xda\x50\xc1\xd0\x89\xb3\xc0\x8c\xc8\xa9\x5a\xe4\xee\xa0\x62\x4a\xd7\x04\xe7\xcf\x9d\x42\x1e\xef\xa2\x6f\x64\x47\xe4\x75\x3e\xfb\x04\xb1\xe9\x48\x71\xe7\xb0\x8a\x04\x3b\xd4\x95\x69\xf6\x00\x4a\x91\x34\x22\x67\xf7\xdc\xa0\x80\x26\xd7\xe4\xcf\x27\x58\x0d\x4a\x0c\xa4\x99\x54\x54\xad\x09\xee\xb1\xe7\xea\xf9\xbc\xb5\x62\x17\xf1\x6b\x94\x3c\x57\x49\xaf\x82\xff\x76\xf5\xfe\x31\x94\xbb\x5a\x54\x97\x6e\xdb\x74\xce\xfc\x47\x43\xa7\x7f\xbb\x7a\xdf\xd2\xe7\xe2\xb0\x68\x65\x3d\x30\xe2\x02\x37\x89\x58\xe8\xf6\xf1\xfe\x95\x8b\x83\x48\x43\xfb\xdd\xb7\xb7\x96\xec\x4b\xf1\xed\x3f\xc8\x52\x88\xc1\x59\xf9\xdd\x9c\xb1\xd5\x77\x8f\x15\xbd\x3b\xb0\x73\xec\xc4\xb5\xc7\x59\x90\x70\xd0\xd6\xf1\x15\xbd\x2b\x7a\x7e\xf7\xfe\x75\x53\x84\x4f\x5a\x8a\x01\x32\x58\xb2\x41\x12\xfc\xf7\xf5\xc7\x0f\xdd\x45\x77\x6d\x5d\x3f\x81\x32\x18\x9e\x82\xcc\xfb\xb5\xe1\xc6\xd1\x3d\x93\x3f\x5f\x4a\xc5\x59\x25\xd0\x76\xe8\xbd\x54\x3b\x9f\xbe\x12\xd1\xe5\xd6\xbf\xfa\xa1\x74\xeb\x07\x1a\x07\xb7\xdd\xa8\xd1\x1a\xe0\xaa\x98\x5f\x14\x73\x07\xbc\x35\x8c\xc8\x29\xf9\xf1\xfe\xbe\x0c\xe3\xc0\xab\xf3\x38\xb6\xd3\x69\x4a\x4e\x1a\x49\x0a\x8c\xe2\xcf\xe8\x9f\x16\xe7\x28\x9e\x63\x05\xe6\xe0\xbe\x16\xc2\x62\xd0\x35\x27\xb3\x35\xd9\x9d\x8b\xb6\x26\xcd\x6f\x66\xcd\x94\xbc\xd3\xc0\x88\x14\xd6\xec\xf6\x9e\x1d\x26\x78\xf6\x76\x44\xca\x05\x4e\x05\x31\x08\x43\x36\x1b\x3c\x9e\xef\xcf\x84\xe0\xd2\x4c\xc1\x6e\x27\x08\xb7\x90\x19\xc2\x05\x49\x21\x95\x6a\xfd\x7c\xf3\x84\xaf\x9b\x1d\xa4\xf7\x7f\xe0\x09\x77\x29\xdc\x57\x4d\xb7\x4f\xfa\xa7\xdb\xfd\x2a\xe6\x8f\xc2\x01\xf3\xb3\x83\x64\x5d\x38\x71\xd6\x93\x84\xfb\x78\x69\x8f\x49\x6b\x5c\xdb\xb2\xcb\x58\xe5\x29\x18\xc2\x24\x1e\xf1\x7e\x0c\x8d\x68\x06\xf1\xe0\xef\x99\x60\xdd\xc7\x7e\xf7\x9e\x0f\x56\x7c\xb1\xfc\xea\x03\xc2\x4f\xb6\x87\x8b\x77\x96\x40\x18\x1e\x53\x7f\xbb\xe9\xb0\x62\x9e\xd6\xc8\x9f\xce\xa5\x68\x8a\xc5\x59\x2b\xee\xb0\x7d\x17\x52\x1c\x3a\x9d\x86\xf5\x70\x27\xd4\x2c\xe1\x20\x0b\xff\x41\x8a\x81\xce\x45\x96\x50\x2e\x06\x95\xee\x28\x87\x1d\x16\x7c\x7f\x7a\xfe\x61\x58\xf9\xb1\xa2\x69\xca\x4e\x06\x49\x50\xd0\x0e\x3b\xa1\x77\x75\x7a\x11\x5e\xbc\x39\xe9\x71\x74\xc8\x63\x78\x3a\x0f\x69\x3c\x77\x03\x60\x27\xac\x3d\xe4\x5f\x8f\xf5\x7e\xbf\x06\x85\x1a\xd6\x3b\x67\xf1\x74\xfd\x13\x17\x4f\xf8\x2c\x53\xf3\x9d\x54\xd5\xba\xb4\x27\x32\x3e\xa1\x35\x38\x97\x1c\xd3\x3f\x6c\x03\x65\x54\xeb\x3b\xa9\x58\xef\xb9\x08\x4f\xd7\xd7\x40\xf5\x4c\x7d\xa3\x3d\xac\x5f\x5c\x43\xac\xa0\xbc\x3a\x72\xe9\xf3\x6a\xd4\xa5\x11\xfd\x18\xea\xd0\xaa\x62\xf7\xe2\x5e\x41\x54\xad\x68\xc7\xfa\x9e\x4b\x68\xa9\xc4\xc3\xa0\xd0\x35\x28\xb2\x6e\x28\x1a\xd1\xc1\xbf\xc7\x2a\x85\xbb\xfb\xd5\xbf\x49\xfd\xce\xd1\x7d\x69\x03\x63\x1f\xa7\x0a\xe8\xc1\x6d\xa4\x42\x14\xce\x2a\x01\xf4\xaa\x27\xc1\xab\x46\xfb\x9d\xda\x3b\xc7\xfc\x9f\xd6\x26\xfe\x42\x7e\x05\xaa\x40\x11\x23\x6f\x41\x34\xf5\xdc\xef\x3b\xd9\xfc\x6e\xe0\x7e\xe7\xc2\x8d\xa3\x42\xaa\x81\x6e\xda\xd9\xbd\x51\xd4\xae\x7c\x80\x36\xc4\x4b\x78\x4c\xa4\x00\xbb\x75\x9f\x70\x01\xcf\x73\xba\xc0\xe9\x63\xff\x1e\x0b\x5f\x08\xdc\xfb\xbe\x85\xf5\xb3\x75\xcc\x42\x34\xce\x76\xdf\x8d\x4e\xe9\x06\x96\x56\x97\xf4\xd4\xc3\xb7\x57\x34\x98\xe3\xa2\x35\xdc\xec\x48\xf3\x85\x00\x46\xee\xb8\x59\x92\x77\x17\xa7\xaf\xc3\xeb\x77\xa7\x3f\x9e\xfc\x84\x13\x26\x74\xa2\x7f\x0f\x77\x33\xb7\x10\xc1\xa1\x38\xc7\xf2\x0d\xf9\xe8\x73\xa9\x5d\x43\x92\x7d\x1d\xb8\xb8\xb1\xb3\xef\x68\xb4\x36\x8a\x1a\x58\xac\xfb\x5b\xda\x13\x3e\xd5\x9d\xad\x9d\x20\x9c\x55\x42\x07\xef\x4a\x28\x5c\x2d\x54\x72\x76\xd0\x25\x2c\x04\x27\x55\xf2\x41\x3e\xd9\xdf\x00\x27\x18\xc0\x88\x65\x24\x96\x73\xd8\xed\x21\xa0\xda\xc8\xdc\xd8\xd5\x70\x2e\x16\x83\xa4\x6b\x31\x0d\xbb\x59\x84\x5c\xa4\xc2\xf6\xc4\x77\x3c\xf6\x2a\x5a\x71\x47\x8d\x1c\xd0\xb8\xde\x53\x4a\xe9\xac\xdb\x00\x0c\xd0\x30\x43\x67\x09\x14\x34\x2e\x60\xff\xa3\x9e\x31\x10\x1a\x8a\xa3\xf5\xae\x90\x96\xf5\x28\x5e\xcd\xa8\x45\xaa\x46\x0c\xd2\x4d\xfd\xbd\x56\xb3\xec\x4a\xfc\xa8\x18\xa8\x3d\x89\x7d\xc6\xb6\x44\xce\x29\x5e\x47\x2e\xe3\xa8\x29\xd2\x38\xea\x90\x7c\x6c\xdc\x4b\x09\x35\xde\xcd\x46\xe1\xcc\x9e\x7c\xcb\x05\x83\xfb\x63\xf2\xad\xd7\xd9\x5f\x26\x64\xe4\x41\x29\x2e\x41\x1f\x04\x00\xef\xfe\x7a\x5e\xb7\xc3\xe2\xef\xa2\x8e\x23\xc3\xba\xa8\x1f\x7c\x5a\xcc\xca\x12\x36\x0b\x39\xb7\x1e\x56\x75\x01\xa5\x48\x3f\xd7\x4e\x71\xb0\x53\x14\x71\xb6\x19\xf6\xad\xa4\x7c\x90\x86\x50\xe2\xca\x09\xa6\x7b\xe4\x1e\xdc\x58\x0f\xad\xdf\x9d\x6d\xdc\xaf\xac\x9f\xd3\x90\x7d\x15\x7c\xb9\x3b\xe6\xd6\x51\xbb\xb6\x12\x1d\x15\x0e\xe6\x90\xe6\xc7\x05\x17\x34\x63\xe8\x31\x4d\x3f\x48\x37\x20\x38\xe3\x48\x57\x94\x27\xae\xeb\x49\x42\x19\xb3\x3f\xee\xe6\xe7\x68\xb0\x20\xbb\xbb\xf8\x3b\xb2\x96\x3a\x8f\x23\xdb\xb9\x87\x5c\x1b\xe8\x1d\xb1\xca\x81\xc7\xd6\xc1\xae\x3e\xa3\x39\xa7\x3a\xf6\x07\xfb\x24\x2a\x93\xbf\x59\x80\x9e\x98\xbb\xd0\xaf\xdd\xaa\xd9\x81\x43\x5e\x0f\x94\xa7\xd4\xa8\x8b\xea\x72\xac\x5e\x52\xbb\x26\x0c\xe6\x0e\x40\x78\xad\xd5\x28\x60\xa6\x24\xae\x2a\x62\x1f\x76\x4b\x78\x5c\x11\xa7\x5c\x7a\x44\xae\x01\x75\xdc\x05\x71\x29\xef\x07\xa4\x61\x8a\x62\xc5\x7c\x26\x0f\xbf\xfc\x70\x70\xd9\xae\xb5\x1a\xf7\xa2\x93\xab\xfb\x1d\x89\xa3\x47\xba\xd6\xdf\xce\xa2\x58\x8b\x27\x7b\x6e\xe1\xd4\x1e\x6f\xd1\xf9\x2c\xe5\x3b\xaf\x77\x66\x04\x99\x19\x11\x66\x8a\xa7\x54\xad\x83\xe9\x35\x5d\x41\xe3\x89\x93\x87\xe3\x56\x0b\x8d\x23\xac\xca\xf4\x45\x2b\xa5\x5a\x15\x67\xc8\xfc\x2e\x09\x5f\xed\x6c\xeb\x7e\xab\xa7\x8d\xe2\x59\x61\xf3\x5c\x17\xdd\x55\xbc\x69\xed\xea\x1d\x1d\xcd\x98\x7b\xee\xc0\x2c\x1b\xd1\x37\xb2\x23\x12\xcf\xdb\x75\x44\xd7\xa3\x6a\x7d\xbd\x69\xb4\xc6\x66\x2e\xa5\xd9\x2f\x0f\x6b\x0e\x63\x5f\x1a\xd5\x94\xa2\x56\x6c\xd3\x6a\xb6\x0c\xa6\x85\xd1\xda\xcb\x84\x6b\xb3\xdd\x1e\x90\x77\xb3\xf9\x56\x55\x6c\xa3\x1b\xcf\x5d\x4c\xe5\x99\x0d\x52\x1b\x22\x9c\x8f\x64\xff\xef\x34\xce\xd3\xfa\xae\xba\x9b\x56\xb2\x7d\x05\xde\xc8\xce\xf4\x17\xd5\x31\xd6\x93\x5e\xe7\x29\x16\x52\x1b\x6e\xeb\x92\xfe\x17\xf6\xb0\x5a\x7a\xe5\x99\x1d\x04\x21\xcc\x85\x7d\xcd\x8a\x91\x4e\xbf\xaa\x84\xd0\xa5\x22\x76\x3e\x6f\x37\xe4\x5e\xdb\xd3\x18\xb5\x02\xec\x23\x37\x9b\x8d\xe7\xa8\xe2\x07\x9f\x0b\xe6\xd6\x63\x04\xdf\xf9\x81\x6e\xc7\x57\x18\xc8\xef\x3d\x64\xbf\x54\xd2\xae\x41\x18\xeb\x5c\x0b\x73\x5c\x89\xc6\x77\x13\xec\x76\xd8\xdc\x7e\x54\x1f\xde\xd9\x63\x9b\x76\x6f\xeb\xec\xa3\xa8\xc2\x79\x29\x65\x02\xf5\xe4\xbd\x68\x66\x52\x26\x4d\x28\xed\x9c\xb3\x92\x57\x01\x9e\x03\x6c\xf4\x31\x03\xb1\xdd\x56\x0e\xaa\xbb\x4b\x0c\x58\xc3\xd1\x39\x4b\x70\x55\x90\xb3\x04\x6c\xb5\xbe\xb6\x2a\x78\x45\xf6\xbd\x3b\x51\x3f\xac\x3e\xee\x9e\xde\x1e\xe5\xb0\x89\x15\xdd\xb0\x39\xef\x53\x0d\x9b\x88\xad\x66\xb9\x1c\xad\x6f\x5e\x17\x73\x5a\x38\x21\x78\x1c\xa8\xf8\x26\x42\xde\x0d\x68\xd0\x42\xf9\x9c\x1c\x7f\xb5\x57\x0a\xb7\x5b\x7f\xb7\xb0\xc9\xdf\x0b\x59\x5f\x37\xe4\x73\x7b\xb2\xae\x3d\x2e\x54\x0b\x69\x3e\x26\x55\x4a\x87\x0e\x63\x54\xbe\xe5\xe3\x1e\x20\x6a\x3c\x3e\xd6\xb4\x5f\xce\xdd\x88\xa5\x98\x73\x95\x4e\x82\xd7\xb8\xd5\x87\x0e\x8e\xcf\xc5\x79\x26\xc4\x48\xd2\x18\xbe\x8e\xad\x27\xb2\x96\x39\xd1\xb9\x82\xff\xf0\xf9\x14\x0f\x18\xed\x64\x80\x04\xb7\x87\xe6\x32\x49\xd0\xb0\x5f\xd0\x5b\x20\xe5\xf8\x45\x07\x68\x97\x80\x12\x0c\x46\x82\x37\x57\x1f\x2f\x83\x87\x80\x81\x13\xea\x1e\x24\x0a\x59\xa7\xf8\x66\x51\x43\xa8\xc3\xb9\xf7\x65\xec\x1e\x97\x6a\x20\xfc\x06\x12\x30\x88\xb0\x43\xf6\x81\xb0\x26\x60\xa0\x85\xaa\xcd\x12\xfa\xf0\x3c\x60\xf6\x9a\xc4\x0d\xf7\xba\xee\x59\x57\x1f\x63\x3b\xf8\x7c\x58\xf5\x1d\xc0\xe2\xf1\xc3\x4f\xf8\x24\xe1\xba\xfb\x85\xbf\x2e\xfa\xfa\x3b\x8b\x83\x58\x2a\xef\x2b\x36\xe8\xc7\x91\xab\xd5\x38\x72\x0f\x65\xbe\xf8\xbf\x01\x00\x2d\x98\x3d\x5d\x3a\x53\x00\x00")
 
 func viewsRoutesHtmlBytes() ([]byte, error) {
 	return bindataRead(
@@ -230,7 +272,7 @@ func viewsRoutesHtml() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "views/routes.html", size: 7326, mode: os.FileMode(420), modTime: time.Unix(1455058534, 0)}
+	info := bindataFileInfo{name: "views/routes.html", size: 21306, mode: os.FileMode(420), modTime: time.Unix(1792384305, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
@@ -294,6 +336,8 @@ var _bindata = map[string]func() (*asset, error){
 	"assets/mailrouter.js": assetsMailrouterJs,
 	"views/filters.html": viewsFiltersHtml,
 	"views/index.html": viewsIndexHtml,
+	"views/messages.html": viewsMessagesHtml,
+	"views/quarantine.html": viewsQuarantineHtml,
 	"views/routes.html": viewsRoutesHtml,
 }
 
@@ -347,6 +391,8 @@ var _bintree = &bintree{nil, map[string]*bintree{
 	"views": &bintree{nil, map[string]*bintree{
 		"filters.html": &bintree{viewsFiltersHtml, map[string]*bintree{}},
 		"index.html": &bintree{viewsIndexHtml, map[string]*bintree{}},
+		"messages.html": &bintree{viewsMessagesHtml, map[string]*bintree{}},
+		"quarantine.html": &bintree{viewsQuarantineHtml, map[string]*bintree{}},
 		"routes.html": &bintree{viewsRoutesHtml, map[string]*bintree{}},
 	}},
 }}
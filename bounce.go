@@ -0,0 +1,295 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"net/textproto"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mhale/mailrouter/smtpd"
+)
+
+// DSN actions for recipients, from RFC 3464 section 2.3.3.
+const (
+	DSNFailed    = "failed"
+	DSNDelayed   = "delayed"
+	DSNDelivered = "delivered"
+	DSNRelayed   = "relayed"
+)
+
+// Subjects of delivery status notifications.
+const (
+	BounceSubject  = "Undelivered Mail Returned to Sender"
+	DelaySubject   = "Delayed Mail (still being retried)"
+	SuccessSubject = "Successful Mail Delivery Report"
+)
+
+// The delivery status of one recipient in a delivery status notification.
+type RecipientStatus struct {
+	Recipient  string
+	Action     string
+	Status     string // Enhanced status code, e.g. 5.1.1
+	Diagnostic string // SMTP reply or error text explaining the status
+}
+
+// Matches an RFC 3463 enhanced status code in a reply, e.g. 5.1.1.
+var enhancedCode = regexp.MustCompile(`\b([245])\.(\d{1,3})\.(\d{1,3})\b`)
+
+// Send delivery status notifications to the sender of a message delivered via a route.
+// A bounce reports the recipients that failed permanently, unless bounces are suppressed for
+// the route. A success notification reports the recipients whose sender asked for one with
+// the DSN extension, if the route could not pass the request on to the next server.
+// No notifications are sent about a message that was itself a notification.
+func Notify(route Route, message Message, err error) {
+	if IsNullSender(message.From) {
+		return
+	}
+	var failed []RecipientStatus
+	for _, status := range FailedRecipients(message.To, err) {
+		if message.DSN.Wants(status.Recipient, smtpd.NotifyFailure) {
+			failed = append(failed, status)
+		}
+	}
+	if len(failed) > 0 && !route.SuppressBounces {
+		SendDSN(message, failed, BounceSubject)
+	}
+
+	var delivered []RecipientStatus
+	for _, rcpt := range DeliveredRecipients(message.To, err) {
+		if message.DSN.Wants(rcpt, smtpd.NotifySuccess) && !message.DSN.Forwarded[rcpt] {
+			delivered = append(delivered, SuccessStatus(route, rcpt))
+		}
+	}
+	if len(delivered) > 0 {
+		SendDSN(message, delivered, SuccessSubject)
+	}
+}
+
+// Warn the sender of a message that its delivery via a route has been delayed.
+// Like bounces, delay warnings can be suppressed for a route.
+func WarnDelay(route Route, message Message, reason string) {
+	if IsNullSender(message.From) || route.SuppressBounces {
+		return
+	}
+	var delayed []RecipientStatus
+	for _, rcpt := range message.To {
+		if message.DSN.Wants(rcpt, smtpd.NotifyDelay) {
+			// X.4.5 is mail system congestion (RFC 3463).
+			delayed = append(delayed, RecipientStatus{Recipient: rcpt, Action: DSNDelayed, Status: "4.4.5", Diagnostic: reason})
+		}
+	}
+	if len(delayed) > 0 {
+		SendDSN(message, delayed, DelaySubject)
+	}
+}
+
+// Send a delivery status notification about a message to its sender via the bounce route.
+func SendDSN(message Message, recipients []RecipientStatus, subject string) {
+	bounceRoute, ok := BounceRoute()
+	if !ok {
+		return
+	}
+	data := DSNMessage(message, recipients, subject)
+	bounce := Message{From: "", To: []string{message.From}, Data: data, Subject: subject, Filter: "Bounce"}
+	entry := Log{To: message.From, Subject: subject, Filter: "Bounce", Route: bounceRoute.Name}
+	if bounceRoute.Id == "DROP" {
+		entry.Route = "Drop"
+		stats.Dropped(len(data))
+		logs.AddLog(entry)
+		return
+	}
+	member, err := Deliver(bounceRoute, bounce)
+	entry.Member = member
+	if err != nil {
+		// Notifications are sent from the null sender, so a failed notification is never bounced.
+		log.Printf("Failed to deliver bounce to %s: %v", message.From, err)
+		stats.Failed(len(data))
+		entry.Status = "Failed"
+		entry.Error = fmt.Sprintf("Failed to deliver bounce to %s: %v", message.From, err)
+	} else {
+		stats.Sent(len(data))
+		entry.Status = "Sent"
+	}
+	logs.AddLog(entry)
+}
+
+// Find the route that bounces are sent via, given by name or id in the BounceRoute option.
+func BounceRoute() (Route, bool) {
+	config.RLock()
+	defer config.RUnlock()
+	name := config.Options["BounceRoute"]
+	if name == "" {
+		return Route{}, false
+	}
+	if route, ok := config.Routes[name]; ok {
+		return route, true
+	}
+	for _, route := range config.Routes {
+		if route.Name == name {
+			return route, true
+		}
+	}
+	log.Printf("Bounce route %s does not exist, so no delivery notification was sent.", name)
+	return Route{}, false
+}
+
+// Report whether a sender is the null reverse-path used by bounces, or a mailer daemon.
+// Mail from these senders must never be bounced, to avoid bounce loops.
+func IsNullSender(from string) bool {
+	local := strings.ToLower(strings.SplitN(from, "@", 2)[0])
+	return from == "" || local == "mailer-daemon"
+}
+
+// Work out which recipients failed permanently from a delivery error.
+func FailedRecipients(to []string, err error) []RecipientStatus {
+	var deliveryErr *DeliveryError
+	if errors.As(err, &deliveryErr) && len(deliveryErr.Failures) > 0 {
+		var failed []RecipientStatus
+		for _, failure := range deliveryErr.Failures {
+			if !IsTemporary(failure.Err) {
+				failed = append(failed, FailureStatus(failure.Recipient, failure.Err))
+			}
+		}
+		return failed
+	}
+	if err == nil || IsTemporary(err) {
+		return nil
+	}
+	failed := make([]RecipientStatus, len(to))
+	for i, rcpt := range to {
+		failed[i] = FailureStatus(rcpt, err)
+	}
+	return failed
+}
+
+// Work out which recipients were delivered to despite a delivery error.
+func DeliveredRecipients(to []string, err error) []string {
+	if err == nil {
+		return to
+	}
+	var deliveryErr *DeliveryError
+	if !errors.As(err, &deliveryErr) || len(deliveryErr.Failures) == 0 {
+		return nil
+	}
+	failed := map[string]bool{}
+	for _, failure := range deliveryErr.Failures {
+		failed[failure.Recipient] = true
+	}
+	var delivered []string
+	for _, rcpt := range to {
+		if !failed[rcpt] {
+			delivered = append(delivered, rcpt)
+		}
+	}
+	return delivered
+}
+
+// Describe the delivery of a message to a recipient. Mail passed on to another server
+// that will not send notifications is relayed, rather than delivered.
+func SuccessStatus(route Route, rcpt string) RecipientStatus {
+	switch route.Type {
+	case RouteSMTP, RouteFailover, RouteBalance, RouteMX, "":
+		return RecipientStatus{Recipient: rcpt, Action: DSNRelayed, Status: "2.0.0", Diagnostic: "relayed to a server that does not send delivery notifications"}
+	}
+	return RecipientStatus{Recipient: rcpt, Action: DSNDelivered, Status: "2.0.0", Diagnostic: "delivered via route " + route.Name}
+}
+
+// Describe the failure of delivery to a recipient, taking the status code from the error if it has one.
+func FailureStatus(rcpt string, err error) RecipientStatus {
+	status := "5.0.0"
+	if IsTemporary(err) {
+		status = "4.0.0"
+	}
+	text := err.Error()
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) {
+		text = fmt.Sprintf("%d %s", protoErr.Code, protoErr.Msg)
+	}
+	if m := enhancedCode.FindStringSubmatch(text); m != nil {
+		status = m[0]
+	}
+	action := DSNFailed
+	if status[0] == '4' {
+		action = DSNDelayed
+	}
+	return RecipientStatus{Recipient: rcpt, Action: action, Status: status, Diagnostic: text}
+}
+
+// Build an RFC 3464 delivery status notification about a message, addressed to its sender.
+// The report includes the headers of the original message, or the whole message if the sender
+// asked for it to be returned with the DSN extension.
+func DSNMessage(message Message, recipients []RecipientStatus, subject string) []byte {
+	hostname := LocalHostname()
+	boundary := fmt.Sprintf("%d.%s", time.Now().UnixNano(), hostname)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: Mail Delivery System <MAILER-DAEMON@%s>\r\n", hostname)
+	fmt.Fprintf(&buf, "To: <%s>\r\n", message.From)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", subject)
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "Message-ID: <%s@%s>\r\n", MaildirName(), hostname)
+	buf.WriteString("Auto-Submitted: auto-replied\r\n")
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/report; report-type=delivery-status;\r\n\tboundary=\"%s\"\r\n\r\n", boundary)
+	buf.WriteString("This is a MIME-encapsulated message.\r\n\r\n")
+
+	// Human readable explanation.
+	fmt.Fprintf(&buf, "--%s\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n", boundary)
+	fmt.Fprintf(&buf, "This is the mail system at host %s.\r\n\r\n", hostname)
+	buf.WriteString(DSNExplanation(recipients))
+	buf.WriteString("\r\n")
+	for _, r := range recipients {
+		fmt.Fprintf(&buf, "<%s>: %s\r\n", r.Recipient, r.Diagnostic)
+	}
+	buf.WriteString("\r\n")
+
+	// Machine readable status.
+	fmt.Fprintf(&buf, "--%s\r\nContent-Type: message/delivery-status\r\n\r\n", boundary)
+	if message.DSN.EnvId != "" {
+		fmt.Fprintf(&buf, "Original-Envelope-Id: %s\r\n", message.DSN.EnvId)
+	}
+	fmt.Fprintf(&buf, "Reporting-MTA: dns; %s\r\n", hostname)
+	for _, r := range recipients {
+		buf.WriteString("\r\n")
+		if orcpt, ok := message.DSN.ORcpt[r.Recipient]; ok {
+			fmt.Fprintf(&buf, "Original-Recipient: %s\r\n", orcpt)
+		}
+		fmt.Fprintf(&buf, "Final-Recipient: rfc822; %s\r\n", r.Recipient)
+		fmt.Fprintf(&buf, "Action: %s\r\n", r.Action)
+		fmt.Fprintf(&buf, "Status: %s\r\n", r.Status)
+		if r.Diagnostic != "" {
+			fmt.Fprintf(&buf, "Diagnostic-Code: smtp; %s\r\n", strings.Join(strings.Fields(r.Diagnostic), " "))
+		}
+	}
+	buf.WriteString("\r\n")
+
+	// The original message or its headers.
+	if message.DSN.Ret == smtpd.RetFull {
+		fmt.Fprintf(&buf, "--%s\r\nContent-Type: message/rfc822\r\n\r\n", boundary)
+		buf.Write(message.Data)
+	} else {
+		headers, _ := SplitMessage(message.Data)
+		fmt.Fprintf(&buf, "--%s\r\nContent-Type: text/rfc822-headers\r\n\r\n", boundary)
+		for _, field := range headers {
+			buf.WriteString(field)
+			buf.WriteString("\r\n")
+		}
+	}
+	fmt.Fprintf(&buf, "\r\n--%s--\r\n", boundary)
+	return buf.Bytes()
+}
+
+// Explain the outcome for the recipients in plain language.
+func DSNExplanation(recipients []RecipientStatus) string {
+	switch recipients[0].Action {
+	case DSNDelayed:
+		return "Your message has not been delivered yet to the recipients below. Delivery will be retried.\r\n"
+	case DSNDelivered, DSNRelayed:
+		return "Your message was successfully delivered to the recipients below.\r\n"
+	}
+	return "I'm sorry to have to inform you that your message could not be delivered to one or more\r\nrecipients. The reasons are given below.\r\n"
+}
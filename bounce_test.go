@@ -0,0 +1,225 @@
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"github.com/mhale/mailrouter/smtpd"
+)
+
+func TestFailedRecipients(t *testing.T) {
+	partial := RecipientErrors([]RecipientError{
+		{"unknown@example.com", &textproto.Error{Code: 550, Msg: "5.1.1 unknown user"}},
+		{"full@example.com", &textproto.Error{Code: 452, Msg: "4.2.2 mailbox full"}},
+		{"quota@example.com", &textproto.Error{Code: 552, Msg: "over quota"}},
+	})
+	to := []string{"a@example.com", "b@example.com"}
+	tests := []struct {
+		err  error
+		want []string
+	}{
+		{nil, nil},
+		{errors.New("connection refused"), nil},
+		{&textproto.Error{Code: 554, Msg: "5.7.1 rejected"}, []string{"a@example.com 5.7.1", "b@example.com 5.7.1"}},
+		{&DeliveryError{Msg: "no route", Permanent: true}, []string{"a@example.com 5.0.0", "b@example.com 5.0.0"}},
+		{partial, []string{"unknown@example.com 5.1.1", "quota@example.com 5.0.0"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, status := range FailedRecipients(to, tt.err) {
+			got = append(got, status.Recipient+" "+status.Status)
+		}
+		if strings.Join(got, ", ") != strings.Join(tt.want, ", ") {
+			t.Errorf("FailedRecipients(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestIsNullSender(t *testing.T) {
+	tests := []struct {
+		from string
+		want bool
+	}{
+		{"", true},
+		{"MAILER-DAEMON@example.com", true},
+		{"mailer-daemon", true},
+		{"sender@example.com", false},
+		{"daemon@example.com", false},
+	}
+	for _, tt := range tests {
+		if got := IsNullSender(tt.from); got != tt.want {
+			t.Errorf("IsNullSender(%q) = %v, want %v", tt.from, got, tt.want)
+		}
+	}
+}
+
+func TestDSNMessage(t *testing.T) {
+	message := Message{From: "sender@example.com", To: []string{"unknown@example.com"}, Data: []byte("Subject: Hello\r\nFrom: sender@example.com\r\n\r\nSecret body.\r\n")}
+	status := FailureStatus("unknown@example.com", &textproto.Error{Code: 550, Msg: "5.1.1 unknown\nuser"})
+	data := DSNMessage(message, []RecipientStatus{status}, BounceSubject)
+
+	msg, err := mail.ReadMessage(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if to := msg.Header.Get("To"); to != "<sender@example.com>" {
+		t.Errorf("To = %q, want <sender@example.com>", to)
+	}
+	if auto := msg.Header.Get("Auto-Submitted"); auto != "auto-replied" {
+		t.Errorf("Auto-Submitted = %q, want auto-replied", auto)
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/report" || params["report-type"] != "delivery-status" {
+		t.Fatalf("Content-Type = %s %v %v, want multipart/report of delivery-status", mediaType, params, err)
+	}
+
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	want := []struct {
+		contentType string
+		contains    []string
+		excludes    string
+	}{
+		{"text/plain; charset=utf-8", []string{"<unknown@example.com>: 550 5.1.1 unknown"}, ""},
+		{"message/delivery-status", []string{"Reporting-MTA: dns; ", "Final-Recipient: rfc822; unknown@example.com", "Action: failed", "Status: 5.1.1", "Diagnostic-Code: smtp; 550 5.1.1 unknown user"}, ""},
+		{"text/rfc822-headers", []string{"Subject: Hello"}, "Secret body."},
+	}
+	for _, part := range want {
+		p, err := reader.NextPart()
+		if err != nil {
+			t.Fatalf("missing %s part: %v", part.contentType, err)
+		}
+		body, _ := ioutil.ReadAll(p)
+		if ct := p.Header.Get("Content-Type"); ct != part.contentType {
+			t.Errorf("part Content-Type = %q, want %q", ct, part.contentType)
+		}
+		for _, s := range part.contains {
+			if !strings.Contains(string(body), s) {
+				t.Errorf("%s part = %q, want it to contain %q", part.contentType, body, s)
+			}
+		}
+		if part.excludes != "" && strings.Contains(string(body), part.excludes) {
+			t.Errorf("%s part = %q, want it not to contain %q", part.contentType, body, part.excludes)
+		}
+	}
+	if _, err := reader.NextPart(); err == nil {
+		t.Errorf("DSN has more than 3 parts")
+	}
+}
+
+func TestBounce(t *testing.T) {
+	useTestCapture(t)
+	useTestRoutes(t,
+		Route{Id: "DROP", Name: "Drop"},
+		Route{Id: "bounces", Name: "Bounces", Type: RouteCapture},
+		Route{Id: "rejecting", Name: "Rejecting", Type: RoutePipe, Command: "false"},
+		Route{Id: "quiet", Name: "Quiet", Type: RoutePipe, Command: "false", SuppressBounces: true},
+	)
+	useTestOptions(t, map[string]string{})
+
+	tests := []struct {
+		bounceRoute string
+		from        string
+		routeId     string
+		bounced     bool
+	}{
+		{"Bounces", "sender@example.com", "rejecting", true},
+		{"bounces", "sender@example.com", "rejecting", true},
+		{"", "sender@example.com", "rejecting", false},
+		{"missing", "sender@example.com", "rejecting", false},
+		{"Bounces", "sender@example.com", "quiet", false},
+		{"Bounces", "", "rejecting", false},
+		{"Bounces", "MAILER-DAEMON@example.com", "rejecting", false},
+	}
+	data := []byte("Subject: Hello\r\n\r\nHi.\r\n")
+	for _, tt := range tests {
+		config.Options["BounceRoute"] = tt.bounceRoute
+		captured.Clear()
+		RouteMessage(Message{From: tt.from, To: []string{"rcpt@example.com"}, Data: data, Subject: "Hello"}, tt.routeId, 0)
+
+		bounces := captured.Search("")
+		if (len(bounces) == 1) != tt.bounced || len(bounces) > 1 {
+			t.Errorf("RouteMessage(from %q via %s) with bounce route %q sent %d bounces, want bounced %v", tt.from, tt.routeId, tt.bounceRoute, len(bounces), tt.bounced)
+			continue
+		}
+		if tt.bounced {
+			if b := bounces[0]; b.From != "" || b.ToText() != tt.from || b.Subject != BounceSubject {
+				t.Errorf("bounce from %q to %q with subject %q, want null sender to %s", b.From, b.ToText(), b.Subject, tt.from)
+			}
+			if l := logs.Logs[0]; l.Filter != "Bounce" || l.Status != "Sent" || l.To != tt.from {
+				t.Errorf("bounce log = %+v, want Sent to %s", l, tt.from)
+			}
+		}
+	}
+}
+
+func TestNotifySuccess(t *testing.T) {
+	withDSN := newTestSMTPServer(t, "250 2.0.0 queued")
+	withDSN.ehlo = []string{"DSN"}
+	withoutDSN := newTestSMTPServer(t, "250 2.0.0 queued")
+	useTestCapture(t)
+	useTestRoutes(t,
+		Route{Id: "DROP", Name: "Drop"},
+		Route{Id: "notices", Name: "Notices", Type: RouteCapture},
+		withDSN.route("with"),
+		withoutDSN.route("without"),
+	)
+	useTestOptions(t, map[string]string{"BounceRoute": "notices"})
+
+	tests := []struct {
+		routeId string
+		action  string
+	}{
+		{"notices", "Action: delivered"},
+		{"without", "Action: relayed"},
+		{"with", ""},
+	}
+	for _, tt := range tests {
+		captured.Clear()
+		dsn := DSNParams{DSNParams: smtpd.DSNParams{EnvId: "order-1", Notify: map[string]string{"a@example.com": "SUCCESS"}}}
+		message := Message{From: "sender@example.com", To: []string{"a@example.com", "b@example.com"}, Data: []byte("Subject: Receipt\r\n\r\nThanks.\r\n"), DSN: dsn}
+		RouteMessage(message, tt.routeId, 0)
+
+		var notices []CapturedMessage
+		for _, m := range captured.Search("") {
+			if m.Subject == SuccessSubject {
+				notices = append(notices, m)
+			}
+		}
+		if tt.action == "" {
+			if len(notices) != 0 {
+				t.Errorf("RouteMessage(%s) sent %d success notifications, want none", tt.routeId, len(notices))
+			}
+			continue
+		}
+		if len(notices) != 1 {
+			t.Errorf("RouteMessage(%s) sent %d success notifications, want 1", tt.routeId, len(notices))
+			continue
+		}
+		data := string(notices[0].Data)
+		for _, s := range []string{tt.action, "Final-Recipient: rfc822; a@example.com", "Original-Envelope-Id: order-1"} {
+			if !strings.Contains(data, s) {
+				t.Errorf("RouteMessage(%s) notification = %q, want it to contain %q", tt.routeId, data, s)
+			}
+		}
+		if strings.Contains(data, "b@example.com") {
+			t.Errorf("RouteMessage(%s) notification reports b@example.com, which did not ask for one", tt.routeId)
+		}
+	}
+}
+
+func TestDSNMessageReturn(t *testing.T) {
+	dsn := DSNParams{DSNParams: smtpd.DSNParams{Ret: smtpd.RetFull, ORcpt: map[string]string{"a@example.com": "rfc822;orig@example.com"}}}
+	message := Message{From: "sender@example.com", To: []string{"a@example.com"}, Data: []byte("Subject: Hello\r\n\r\nWhole body.\r\n"), DSN: dsn}
+	data := string(DSNMessage(message, FailedRecipients(message.To, &DeliveryError{Msg: "rejected", Permanent: true}), BounceSubject))
+	for _, s := range []string{"Content-Type: message/rfc822\r\n\r\nSubject: Hello\r\n\r\nWhole body.\r\n", "Original-Recipient: rfc822;orig@example.com\r\nFinal-Recipient: rfc822; a@example.com"} {
+		if !strings.Contains(data, s) {
+			t.Errorf("DSNMessage() = %q, want it to contain %q", data, s)
+		}
+	}
+}
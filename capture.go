@@ -0,0 +1,213 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mhale/mailrouter/smtpd"
+)
+
+// Maximum number of captured messages kept. The oldest are discarded first.
+const MaxCaptured = 1000
+
+// Content Security Policy for the HTML body of captured mail, so scripts in it cannot run or
+// load remote content.
+const SandboxPolicy = "sandbox; default-src 'none'; style-src 'unsafe-inline'; img-src data:"
+
+// A message stored by a capture route, or held in quarantine.
+type CapturedMessage struct {
+	Id       int
+	Received time.Time
+	From     string
+	To       []string
+	Subject  string
+	Filter   string
+	Route    string
+	Origin   net.IP
+	Data     []byte
+	RouteId  string // Route a quarantined message is released to by default
+	LogId    int    // Log entry recording that the message was quarantined
+	DSN      DSNParams
+	Listener string           // Name of the listener that received the message
+	Client   smtpd.ClientInfo // The client's name, HELO and protocol, passed on with XFORWARD
+}
+
+// A header field of a captured message, in the order it appears in the message.
+type HeaderField struct {
+	Name  string
+	Value string
+}
+
+// Captured or quarantined messages, newest first.
+type CaptureStore struct {
+	sync.RWMutex
+	Messages []CapturedMessage
+	lastId   int
+}
+
+// Store a copy of a message delivered to a capture route.
+func deliverCapture(route Route, msg Message) error {
+	captured.Add(route.Name, msg)
+	return nil
+}
+
+// Add a copy of a message to the store.
+func (cs *CaptureStore) Add(route string, msg Message) CapturedMessage {
+	return cs.Store(CapturedMessage{
+		From:     msg.From,
+		To:       append([]string(nil), msg.To...),
+		Subject:  DecodeHeader(msg.Subject),
+		Filter:   msg.Filter,
+		Route:    route,
+		Origin:   msg.Origin,
+		Listener: msg.Listener,
+		Client:   msg.Client,
+		Data:     append([]byte(nil), msg.Data...),
+	})
+}
+
+// Store a message, setting its id and received time, and discard the oldest message if the store is full.
+func (cs *CaptureStore) Store(m CapturedMessage) CapturedMessage {
+	cs.Lock()
+	defer cs.Unlock()
+
+	m = cs.add(m)
+	for len(cs.Messages) > MaxCaptured {
+		old := cs.Messages[len(cs.Messages)-1]
+		log.Printf("Discarding captured message %d from %s, as only the most recent %d are kept.", old.Id, old.From, MaxCaptured)
+		cs.Messages = cs.Messages[:len(cs.Messages)-1]
+	}
+	return m
+}
+
+// Add a message to a locked store, setting its id and received time.
+func (cs *CaptureStore) add(m CapturedMessage) CapturedMessage {
+	cs.lastId++
+	m.Id = cs.lastId
+	m.Received = time.Now()
+	cs.Messages = append([]CapturedMessage{m}, cs.Messages...)
+	return m
+}
+
+// Return the messages whose envelope, subject or content contain the query, ignoring case.
+// An empty query returns all messages.
+func (cs *CaptureStore) Search(query string) []CapturedMessage {
+	cs.RLock()
+	defer cs.RUnlock()
+
+	query = strings.ToLower(strings.TrimSpace(query))
+	var found []CapturedMessage
+	for _, m := range cs.Messages {
+		if query == "" || m.Matches(query) {
+			found = append(found, m)
+		}
+	}
+	return found
+}
+
+// Find a message by its id.
+func (cs *CaptureStore) Get(id int) (CapturedMessage, bool) {
+	cs.RLock()
+	defer cs.RUnlock()
+
+	for _, m := range cs.Messages {
+		if m.Id == id {
+			return m, true
+		}
+	}
+	return CapturedMessage{}, false
+}
+
+// Delete a message by its id, reporting whether it was found.
+func (cs *CaptureStore) Delete(id int) bool {
+	cs.Lock()
+	defer cs.Unlock()
+
+	for i, m := range cs.Messages {
+		if m.Id == id {
+			cs.Messages = append(cs.Messages[:i:i], cs.Messages[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Delete all messages.
+func (cs *CaptureStore) Clear() {
+	cs.Lock()
+	defer cs.Unlock()
+	cs.Messages = nil
+}
+
+// Report whether the message contains a lower case query.
+func (m CapturedMessage) Matches(query string) bool {
+	for _, s := range []string{m.From, strings.Join(m.To, ","), m.Subject} {
+		if strings.Contains(strings.ToLower(s), query) {
+			return true
+		}
+	}
+	if bytes.Contains(bytes.ToLower(m.Data), []byte(query)) {
+		return true
+	}
+	// Search the decoded bodies too, as they may be base64 or quoted-printable encoded.
+	parsed, err := m.Parse()
+	if err != nil {
+		return false
+	}
+	return strings.Contains(strings.ToLower(parsed.Text), query) || strings.Contains(strings.ToLower(parsed.HTML), query)
+}
+
+// Decode the message's bodies and attachments.
+func (m CapturedMessage) Parse() (ParsedMessage, error) {
+	return ParseMessage(m.Data)
+}
+
+// Return the message's header fields in order, with encoded words decoded.
+func (m CapturedMessage) HeaderFields() []HeaderField {
+	headers, _ := SplitMessage(m.Data)
+	fields := make([]HeaderField, 0, len(headers))
+	for _, field := range headers {
+		fields = append(fields, HeaderField{Name: HeaderName(field), Value: DecodeHeader(strings.TrimSpace(HeaderValue(field)))})
+	}
+	return fields
+}
+
+// Format the time the message was received for display.
+func (m CapturedMessage) ReceivedText() string {
+	return m.Received.Format("2006-01-02 15:04:05")
+}
+
+// Format the recipients for display.
+func (m CapturedMessage) ToText() string {
+	return strings.Join(m.To, ", ")
+}
+
+// Format the size of the message for display.
+func (m CapturedMessage) Size() string {
+	return FormatSize(len(m.Data))
+}
+
+// Format a number of bytes with a binary unit, e.g. 1.5 KB.
+func FormatSize(n int) string {
+	if n < 1024 {
+		return fmt.Sprintf("%d bytes", n)
+	}
+	size := float64(n) / 1024
+	for _, unit := range []string{"KB", "MB"} {
+		if size < 1024 {
+			return fmt.Sprintf("%.1f %s", size, unit)
+		}
+		size /= 1024
+	}
+	return fmt.Sprintf("%.1f GB", size)
+}
+
+// Format the size of the attachment for display.
+func (a Attachment) Size() string {
+	return FormatSize(len(a.Data))
+}
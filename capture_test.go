@@ -0,0 +1,78 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestCaptureStore(t *testing.T) {
+	var cs CaptureStore
+	first := cs.Add("Capture", Message{From: "alice@example.com", To: []string{"bob@example.com"}, Subject: "Invoice", Data: []byte("Subject: Invoice\r\n\r\nPlease pay.\r\n")})
+	second := cs.Add("Capture", Message{From: "carol@example.com", To: []string{"dave@example.com"}, Subject: "=?UTF-8?Q?Caf=C3=A9?=", Data: []byte(testMultipart)})
+	if first.Id == second.Id {
+		t.Errorf("Add() ids = %d, %d, want unique ids", first.Id, second.Id)
+	}
+	if second.Subject != "Café" {
+		t.Errorf("Add() subject = %q, want decoded subject Café", second.Subject)
+	}
+
+	tests := []struct {
+		query string
+		ids   []int
+	}{
+		{"", []int{second.Id, first.Id}},
+		{"ALICE", []int{first.Id}},
+		{"dave@", []int{second.Id}},
+		{"please pay", []int{first.Id}},
+		{"café report", []int{second.Id}}, // Only found in the quoted-printable body once decoded
+		{"nothing", nil},
+	}
+	for _, tt := range tests {
+		var ids []int
+		for _, m := range cs.Search(tt.query) {
+			ids = append(ids, m.Id)
+		}
+		if len(ids) != len(tt.ids) || (len(ids) > 0 && ids[0] != tt.ids[0]) {
+			t.Errorf("Search(%q) = %v, want %v", tt.query, ids, tt.ids)
+		}
+	}
+
+	if m, ok := cs.Get(first.Id); !ok || m.From != "alice@example.com" {
+		t.Errorf("Get(%d) = %v, %v, want alice@example.com", first.Id, m.From, ok)
+	}
+	if !cs.Delete(first.Id) || cs.Delete(first.Id) {
+		t.Errorf("Delete(%d) twice, want success then failure", first.Id)
+	}
+	if _, ok := cs.Get(first.Id); ok {
+		t.Errorf("Get(%d) after Delete() found the message", first.Id)
+	}
+	cs.Clear()
+	if len(cs.Search("")) != 0 {
+		t.Errorf("Search() after Clear() = %d messages, want 0", len(cs.Search("")))
+	}
+
+	// The oldest messages are discarded once the store is full.
+	for i := 0; i < MaxCaptured+5; i++ {
+		cs.Add("Capture", Message{Data: []byte("\r\n")})
+	}
+	if len(cs.Messages) != MaxCaptured || cs.Messages[0].Id != cs.lastId {
+		t.Errorf("store holds %d messages, newest %d, want %d, newest %d", len(cs.Messages), cs.Messages[0].Id, MaxCaptured, cs.lastId)
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := []struct {
+		in  int
+		out string
+	}{
+		{0, "0 bytes"},
+		{1023, "1023 bytes"},
+		{1536, "1.5 KB"},
+		{5 * 1024 * 1024, "5.0 MB"},
+		{3 * 1024 * 1024 * 1024, "3.0 GB"},
+	}
+	for _, tt := range tests {
+		if out := FormatSize(tt.in); out != tt.out {
+			t.Errorf("FormatSize(%d) = %s, want %s", tt.in, out, tt.out)
+		}
+	}
+}
@@ -2,61 +2,150 @@ package main
 
 import (
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
+	"strconv"
+	"strings"
 	"sync"
+
+	"github.com/mhale/mailrouter/smtpd"
 )
 
 type Config struct {
 	sync.RWMutex
-	Routes  map[string]Route
-	Filters map[string]Filter
-	Options map[string]string
+	Routes    map[string]Route
+	Filters   map[string]Filter
+	Options   map[string]string
+	Listeners []Listener
 }
 
 // Add the DROP route. Make it the default if there is no existing default route.
-func AddDropRoute() {
+func (c *Config) AddDropRoute() {
 	dropIsDefault := true
-	for _, route := range config.Routes {
+	for _, route := range c.Routes {
 		if route.IsDefault == true {
 			dropIsDefault = false
 		}
 	}
-	config.Routes["DROP"] = Route{Id: "DROP", Name: "Drop", IsDefault: dropIsDefault}
+	c.Routes["DROP"] = Route{Id: "DROP", Name: "Drop", IsDefault: dropIsDefault}
 }
 
-func SetDefaultOptions() {
-	if _, exists := config.Options["PIDFile"]; !exists {
-		config.Options["PIDFile"] = ""
+func (c *Config) SetDefaultOptions() {
+	if _, exists := c.Options["PIDFile"]; !exists {
+		c.Options["PIDFile"] = ""
+	}
+	if _, exists := c.Options["VerifyAuthentication"]; !exists {
+		c.Options["VerifyAuthentication"] = "false"
+	}
+	if _, exists := c.Options["DNSServer"]; !exists {
+		c.Options["DNSServer"] = ""
+	}
+	if _, exists := c.Options["RecipientDomains"]; !exists {
+		c.Options["RecipientDomains"] = ""
+	}
+	if _, exists := c.Options["BounceRoute"]; !exists {
+		c.Options["BounceRoute"] = ""
+	}
+	if _, exists := c.Options["AllowClients"]; !exists {
+		c.Options["AllowClients"] = ""
+	}
+	if _, exists := c.Options["DenyClients"]; !exists {
+		c.Options["DenyClients"] = ""
+	}
+	if _, exists := c.Options["SpoolDirectory"]; !exists {
+		c.Options["SpoolDirectory"] = "/var/spool/mailrouter"
+	}
+	if _, exists := c.Options["MaxRecipientsPerMessage"]; !exists {
+		c.Options["MaxRecipientsPerMessage"] = strconv.Itoa(smtpd.MaxRecipients)
+	}
+	for _, name := range []string{"MaxConnectionsPerIP", "ConnectionsPerMinutePerIP", "MessagesPerMinutePerIP", "MaxConnectionsPerNetwork", "ConnectionsPerMinutePerNetwork", "MessagesPerMinutePerNetwork"} {
+		if _, exists := c.Options[name]; !exists {
+			c.Options[name] = "0"
+		}
+	}
+	if _, exists := c.Options["NetworkPrefixIPv4"]; !exists {
+		c.Options["NetworkPrefixIPv4"] = "24"
+	}
+	if _, exists := c.Options["NetworkPrefixIPv6"]; !exists {
+		c.Options["NetworkPrefixIPv6"] = "64"
+	}
+	if _, exists := c.Options["ReloadOnChange"]; !exists {
+		c.Options["ReloadOnChange"] = "false"
 	}
 }
 
-// Load the filter and route configuration from a JSON file.
-// Add the drop route as it must always be present.
-func LoadConfig() error {
+// Read a configuration from a JSON file.
+// Add the drop route as it must always be present, and the defaults of any missing options.
+// If the file cannot be read, the configuration is empty apart from these.
+func ReadConfig(path string) (*Config, error) {
+	c := new(Config)
 	defer func() {
-		if config.Routes == nil {
-			config.Routes = map[string]Route{}
+		if c.Routes == nil {
+			c.Routes = map[string]Route{}
 		}
-		if config.Filters == nil {
-			config.Filters = map[string]Filter{}
+		if c.Filters == nil {
+			c.Filters = map[string]Filter{}
 		}
-		if config.Options == nil {
-			config.Options = map[string]string{}
+		if c.Options == nil {
+			c.Options = map[string]string{}
 		}
-		AddDropRoute()
-		SetDefaultOptions()
+		c.AddDropRoute()
+		c.SetDefaultOptions()
 	}()
 
-	data, err := ioutil.ReadFile(*confFile)
+	data, err := ioutil.ReadFile(path)
 	if err != nil {
-		return err
+		return c, err
 	}
 
-	err = json.Unmarshal(data, &config)
+	err = json.Unmarshal(data, c)
 	if err != nil {
-		return err
+		return c, err
 	}
 
+	return c, nil
+}
+
+// Load the filter and route configuration from the configuration file.
+func LoadConfig() error {
+	recordConfigModTime()
+	c, err := ReadConfig(*confFile)
+	config.Routes, config.Filters, config.Options, config.Listeners = c.Routes, c.Filters, c.Options, c.Listeners
+	return err
+}
+
+// Check a configuration for settings that Mailrouter cannot use: listeners that cannot be
+// started as configured, numeric options that are not numbers, and client lists that are not
+// networks or addresses.
+func (c *Config) Validate() error {
+	names := map[string]bool{}
+	for _, l := range c.Listeners {
+		if l.Name == "" {
+			return fmt.Errorf("listener on %s has no name", l.Address())
+		}
+		if names[l.Name] {
+			return fmt.Errorf("listener name %s is used more than once", l.Name)
+		}
+		names[l.Name] = true
+		if l.Addr == "" && l.Socket == "" {
+			return fmt.Errorf("listener %s has no address or socket", l.Name)
+		}
+		if _, err := l.Server(c); err != nil {
+			return err
+		}
+	}
+	for _, name := range []string{"MaxRecipientsPerMessage", "MaxConnectionsPerIP", "ConnectionsPerMinutePerIP", "MessagesPerMinutePerIP",
+		"MaxConnectionsPerNetwork", "ConnectionsPerMinutePerNetwork", "MessagesPerMinutePerNetwork", "NetworkPrefixIPv4", "NetworkPrefixIPv6", "MaxQuarantined"} {
+		value := strings.TrimSpace(c.Options[name])
+		if n, err := strconv.Atoi(value); value != "" && (err != nil || n < 0) {
+			return fmt.Errorf("option %s must be a number, not %q", name, value)
+		}
+	}
+	for _, name := range []string{"AllowClients", "DenyClients"} {
+		if _, err := ParseNetworks(strings.Split(c.Options[name], ",")); err != nil {
+			return fmt.Errorf("option %s: %v", name, err)
+		}
+	}
 	return nil
 }
 
@@ -75,9 +164,18 @@ func CloneConfig() *Config {
 	for k, v := range config.Options {
 		clone.Options[k] = v
 	}
+	clone.Listeners = append(clone.Listeners, config.Listeners...)
 	return clone
 }
 
+// Take a copy of the global config under the read lock, for handling a message with settings
+// that stay the same while it is handled.
+func ConfigSnapshot() *Config {
+	config.RLock()
+	defer config.RUnlock()
+	return CloneConfig()
+}
+
 // Save the filter and route configuration to a JSON file.
 // Remove the DROP route before marshalling - it is a hardcoded route that should never be in the config file.
 // Don't delete DROP from the actual config variable (it might be needed mid-save), make a copy instead.
@@ -95,5 +193,38 @@ func SaveConfig() error {
 		return err
 	}
 
+	// The file has changed, but there is nothing new to reload from it.
+	recordConfigModTime()
 	return nil
 }
+
+// Split a comma separated option into its non-empty values.
+func (c *Config) OptionList(name string) []string {
+	var values []string
+	for _, value := range strings.Split(c.Options[name], ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// Parse a numeric option. Returns zero if it is empty or not a number.
+func (c *Config) OptionInt(name string) int {
+	n, _ := strconv.Atoi(strings.TrimSpace(c.Options[name]))
+	return n
+}
+
+// Split a comma separated option of the global config into its non-empty values.
+func OptionList(name string) []string {
+	config.RLock()
+	defer config.RUnlock()
+	return config.OptionList(name)
+}
+
+// Parse a numeric option of the global config. Returns zero if it is empty or not a number.
+func OptionInt(name string) int {
+	config.RLock()
+	defer config.RUnlock()
+	return config.OptionInt(name)
+}
@@ -0,0 +1,358 @@
+package main
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mhale/mailrouter/smtpd"
+)
+
+// A message to be delivered, with the details of how it was received and routed.
+type Message struct {
+	From     string
+	To       []string
+	Data     []byte
+	Subject  string
+	Filter   string // Name of the filter that selected the route, if any
+	Origin   net.IP
+	Listener string // Name of the listener that received the message
+	Client   smtpd.ClientInfo
+	DSN      DSNParams
+	Attempts int       // Delivery attempts that failed temporarily
+	Deferred time.Time // When delivery first failed temporarily, zero until it has
+	warned   bool      // Set once the sender has been warned that delivery is delayed
+}
+
+// Deliver a message via a route.
+// For route groups, the name of the member route that accepted the message is returned.
+func Deliver(route Route, msg Message) (string, error) {
+	// Override the recipient if To field is set.
+	if route.To != "" {
+		msg.To = []string{route.To}
+	}
+
+	switch route.Type {
+	case RouteFailover:
+		return deliverFailover(route, msg)
+	case RouteBalance:
+		return deliverBalance(route, msg)
+	case RouteMX:
+		return "", deliverMX(route, msg)
+	case RouteLMTP:
+		return "", deliverLMTP(route, msg)
+	case RouteMaildir:
+		return "", deliverMaildir(route, msg)
+	case RouteMbox:
+		return "", deliverMbox(route, msg)
+	case RoutePipe:
+		return "", deliverPipe(route, msg)
+	case RouteWebhook:
+		return "", deliverWebhook(route, msg)
+	case RouteCapture:
+		return "", deliverCapture(route, msg)
+	}
+	return "", deliverSMTP(route, msg)
+}
+
+// Deliver a message to the route's SMTP server.
+func deliverSMTP(route Route, msg Message) error {
+	addr := route.Hostname + ":" + strconv.Itoa(route.Port)
+
+	var auth smtp.Auth
+	auth = nil
+	if route.AuthType == "plain" {
+		auth = smtp.PlainAuth("", route.Username, route.Password, route.Hostname)
+	} else if route.AuthType == "crammd5" {
+		auth = smtp.CRAMMD5Auth(route.Username, route.Password)
+	}
+
+	var err error
+	if route.Pooled() {
+		err = connPools.Send(route, addr, auth, msg)
+	} else {
+		err = sendSMTP(addr, route.Hostname, auth, msg, route.XForward)
+	}
+	if err != nil {
+		return fmt.Errorf("route %s (%s): %w", route.Name, addr, err)
+	}
+	return nil
+}
+
+// Send a message to an SMTP server over a new connection.
+// This is smtp.SendMail with DSN parameters passed on to servers that support them,
+// and the client's details too if xforward is set.
+func sendSMTP(addr string, host string, auth smtp.Auth, msg Message, xforward bool) error {
+	c, err := dialSMTP(addr, host, auth)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	if err = sendMessage(c, msg, xforward); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// Connect to an SMTP server, using STARTTLS when it is offered and authenticating if auth is set.
+func dialSMTP(addr string, host string, auth smtp.Auth) (*smtp.Client, error) {
+	c, err := smtp.Dial(addr)
+	if err != nil {
+		return nil, err
+	}
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err = c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			c.Close()
+			return nil, err
+		}
+	}
+	if auth != nil {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			c.Close()
+			return nil, errors.New("smtp: server doesn't support AUTH")
+		}
+		if err = c.Auth(auth); err != nil {
+			c.Close()
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// Send a message over a connection that is ready for a new mail transaction, first passing
+// on the details of the client that sent it if xforward is set.
+func sendMessage(c *smtp.Client, msg Message, xforward bool) error {
+	if xforward {
+		if err := sendXForward(c, msg); err != nil {
+			return err
+		}
+	}
+	forwarded, err := sendEnvelope(c, msg)
+	if err != nil {
+		return err
+	}
+	if err = sendData(c, msg.Data); err != nil {
+		return err
+	}
+	if forwarded {
+		msg.DSN.Forward(msg.To)
+	}
+	return nil
+}
+
+// Send the message data after the envelope has been accepted.
+func sendData(c *smtp.Client, data []byte) error {
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err = w.Write(data); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+// Pass the details of the client that sent a message to a server that supports the Postfix
+// XFORWARD extension, so its logs and policy checks see the client rather than Mailrouter.
+// Only the attributes the server advertises are sent, and nothing is sent for messages that
+// did not come from a client over IP, such as bounces.
+func sendXForward(c *smtp.Client, msg Message) error {
+	ok, params := c.Extension("XFORWARD")
+	if !ok || msg.Origin == nil {
+		return nil
+	}
+	addr := msg.Origin.String()
+	if msg.Origin.To4() == nil {
+		addr = "IPV6:" + addr
+	}
+	values := map[string]string{"NAME": msg.Client.Name, "ADDR": addr, "PROTO": msg.Client.Proto, "HELO": msg.Client.Helo}
+	supported := map[string]bool{}
+	for _, name := range strings.Fields(strings.ToUpper(params)) {
+		supported[name] = true
+	}
+	var attrs []string
+	for _, name := range []string{"NAME", "ADDR", "PROTO", "HELO"} {
+		if !supported[name] {
+			continue
+		}
+		value := values[name]
+		if value == "" {
+			value = "[UNAVAILABLE]"
+		}
+		attrs = append(attrs, name+"="+smtpd.EncodeXtext(value))
+	}
+	if len(attrs) == 0 {
+		return nil
+	}
+	id, err := c.Text.Cmd("XFORWARD %s", strings.Join(attrs, " "))
+	if err != nil {
+		return err
+	}
+	c.Text.StartResponse(id)
+	defer c.Text.EndResponse(id)
+	_, _, err = c.Text.ReadResponse(250)
+	return err
+}
+
+// Try each member of a failover group in order, moving on when a member is unreachable or
+// temporarily refuses the message. A permanent rejection stops delivery.
+func deliverFailover(group Route, msg Message) (string, error) {
+	members := group.SortedMembers()
+	return deliverGroup(group, msg, func() (RouteMember, bool) {
+		if len(members) == 0 {
+			return RouteMember{}, false
+		}
+		member := members[0]
+		members = members[1:]
+		return member, true
+	})
+}
+
+// Spread messages across the members of a balance group according to their weights.
+// A member that is unreachable or temporarily refuses the message is skipped in favour of the others.
+func deliverBalance(group Route, msg Message) (string, error) {
+	tried := map[string]bool{}
+	return deliverGroup(group, msg, func() (RouteMember, bool) {
+		member, ok := groupStats.Pick(group, tried)
+		tried[member.RouteId] = true
+		return member, ok
+	})
+}
+
+// Deliver via the members of a group in the order next gives them, until every recipient has
+// been delivered to or has failed permanently. A member that delivers to some recipients but
+// not others is followed by one sent only the recipients that failed temporarily, so no
+// recipient receives the message twice. Returns the name of the last member that was sent
+// the message, unless no member was available.
+func deliverGroup(group Route, msg Message, next func() (RouteMember, bool)) (string, error) {
+	var errs []string
+	var name string
+	delivered := false
+	var failed, pending []RecipientError // Permanent failures, and temporary ones to try again
+	for {
+		member, ok := next()
+		if !ok {
+			break
+		}
+		config.RLock()
+		route, exists := config.Routes[member.RouteId]
+		config.RUnlock()
+		if !exists || route.IsGroup() || route.Id == "DROP" {
+			continue
+		}
+		name = route.Name
+		err := deliverMember(group, route, msg)
+		if err == nil {
+			return name, groupFailures(group, failed)
+		}
+		errs = append(errs, err.Error())
+		failures := recipientFailures(msg.To, err)
+		delivered = delivered || len(failures) < len(msg.To)
+		pending = nil
+		for _, failure := range failures {
+			if IsTemporary(failure.Err) {
+				pending = append(pending, failure)
+			} else {
+				failed = append(failed, failure)
+			}
+		}
+		if len(pending) == 0 {
+			// A member that permanently refuses the whole message stops delivery with its error.
+			if !delivered && len(failed) == len(failures) {
+				return name, fmt.Errorf("route %s: %w", group.Name, err)
+			}
+			return name, groupFailures(group, failed)
+		}
+		msg.To = nil
+		for _, failure := range pending {
+			msg.To = append(msg.To, failure.Recipient)
+		}
+	}
+	if !delivered && len(failed) == 0 {
+		return "", groupError(group, errs)
+	}
+	return name, groupFailures(group, append(failed, pending...))
+}
+
+// Return the recipients a delivery error applies to: the recipients it lists, or all of them
+// if it does not list them individually.
+func recipientFailures(to []string, err error) []RecipientError {
+	var deliveryErr *DeliveryError
+	if errors.As(err, &deliveryErr) && len(deliveryErr.Failures) > 0 {
+		return deliveryErr.Failures
+	}
+	failures := make([]RecipientError, len(to))
+	for i, rcpt := range to {
+		failures[i] = RecipientError{Recipient: rcpt, Err: err}
+	}
+	return failures
+}
+
+// Describe the recipients of a group delivery that failed, or return nil if there were none.
+func groupFailures(group Route, failed []RecipientError) error {
+	if err := RecipientErrors(failed); err != nil {
+		return fmt.Errorf("route %s: %w", group.Name, err)
+	}
+	return nil
+}
+
+// Deliver via a member of a group, recording the outcome against the member.
+func deliverMember(group Route, route Route, msg Message) error {
+	groupStats.Start(group.Id, route.Id)
+	_, err := Deliver(route, msg)
+	groupStats.Finish(group.Id, route.Id, err)
+	return err
+}
+
+// Describe the failure of a group after every available member has been tried.
+func groupError(group Route, errs []string) error {
+	if len(errs) == 0 {
+		return fmt.Errorf("route %s: no members available", group.Name)
+	}
+	return fmt.Errorf("route %s: all members failed: %s", group.Name, strings.Join(errs, "; "))
+}
+
+// A delivery failure described by mailrouter itself rather than by a remote server.
+type DeliveryError struct {
+	Msg       string
+	Err       error // Underlying cause, if any
+	Permanent bool
+	Failures  []RecipientError // Recipients that failed, if they are known individually
+}
+
+func (e *DeliveryError) Error() string {
+	return e.Msg
+}
+
+func (e *DeliveryError) Unwrap() error {
+	return e.Err
+}
+
+// Report whether a delivery error is worth retrying elsewhere: a connection failure or a 4xx reply.
+func IsTemporary(err error) bool {
+	var deliveryErr *DeliveryError
+	if errors.As(err, &deliveryErr) {
+		return !deliveryErr.Permanent
+	}
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) {
+		return protoErr.Code >= 400 && protoErr.Code < 500
+	}
+	return true
+}
+
+// The name this host uses to identify itself to other mail servers.
+func LocalHostname() string {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		return "localhost"
+	}
+	return hostname
+}
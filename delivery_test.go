@@ -0,0 +1,333 @@
+package main
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mhale/mailrouter/smtpd"
+)
+
+// A minimal SMTP or LMTP server for delivery tests. It replies to DATA with the configured reply and
+// records the envelopes and messages it accepts.
+type testSMTPServer struct {
+	sync.Mutex
+	ln          net.Listener
+	dataReply   string
+	rcptReplies map[string]string // Replies to RCPT for specific recipients
+	lmtpReplies map[string]string // Replies after DATA for specific recipients in LMTP mode
+	lmtp        bool
+	ehlo        []string // Extra EHLO keywords to advertise
+	commands    []string
+	messages    []string
+	conns       int // Connections accepted
+	active      int // Connections open now
+	maxActive   int // Most connections open at once
+}
+
+func newTestSMTPServer(t *testing.T, dataReply string) *testSMTPServer {
+	return newTestServer(t, "tcp", "127.0.0.1:0", dataReply)
+}
+
+func newTestServer(t *testing.T, network string, address string, dataReply string) *testSMTPServer {
+	ln, err := net.Listen(network, address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &testSMTPServer{ln: ln, dataReply: dataReply, rcptReplies: map[string]string{}, lmtpReplies: map[string]string{}}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *testSMTPServer) serve(conn net.Conn) {
+	defer conn.Close()
+	s.Lock()
+	s.conns++
+	s.active++
+	if s.active > s.maxActive {
+		s.maxActive = s.active
+	}
+	s.Unlock()
+	quit := false
+	defer func() {
+		if !quit {
+			s.Lock()
+			s.active--
+			s.Unlock()
+		}
+	}()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+	reply("220 test ESMTP")
+	var rcpts []string
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		s.Lock()
+		s.commands = append(s.commands, line)
+		s.Unlock()
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch verb {
+		case "EHLO", "LHLO":
+			lines := append([]string{"test"}, s.ehlo...)
+			for _, line := range lines[:len(lines)-1] {
+				reply("250-" + line)
+			}
+			reply("250 " + lines[len(lines)-1])
+		case "RCPT":
+			rcpt := strings.Trim(strings.SplitN(strings.SplitN(line, ":", 2)[1], ">", 2)[0], "< ")
+			s.Lock()
+			rcptReply, exists := s.rcptReplies[rcpt]
+			s.Unlock()
+			if exists {
+				reply(rcptReply)
+				continue
+			}
+			rcpts = append(rcpts, rcpt)
+			reply("250 ok")
+		case "DATA":
+			reply("354 go ahead")
+			var msg strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				msg.WriteString(strings.TrimPrefix(l, "."))
+			}
+			s.Lock()
+			if strings.HasPrefix(s.dataReply, "2") {
+				s.messages = append(s.messages, msg.String())
+			}
+			s.Unlock()
+			if s.lmtp {
+				// LMTP replies once for each accepted recipient.
+				for _, rcpt := range rcpts {
+					s.Lock()
+					lmtpReply, exists := s.lmtpReplies[rcpt]
+					s.Unlock()
+					if !exists {
+						lmtpReply = s.dataReply
+					}
+					reply(lmtpReply)
+				}
+			} else {
+				reply(s.dataReply)
+			}
+			rcpts = nil
+		case "QUIT":
+			// Count the connection as closed before the client is told it can go.
+			s.Lock()
+			s.active--
+			s.Unlock()
+			quit = true
+			reply("221 bye")
+			return
+		default:
+			reply("250 ok")
+		}
+	}
+}
+
+// Create an SMTP route pointing at the test server.
+func (s *testSMTPServer) route(id string) Route {
+	addr := s.ln.Addr().(*net.TCPAddr)
+	return Route{Id: id, Name: id, Type: RouteSMTP, Hostname: addr.IP.String(), Port: addr.Port}
+}
+
+func (s *testSMTPServer) received() int {
+	s.Lock()
+	defer s.Unlock()
+	return len(s.messages)
+}
+
+// Use a fresh set of routes for the duration of a test.
+func useTestRoutes(t *testing.T, routes ...Route) {
+	saved := config.Routes
+	config.Routes = map[string]Route{}
+	for _, route := range routes {
+		config.Routes[route.Id] = route
+	}
+	t.Cleanup(func() { config.Routes = saved })
+}
+
+// Use a fresh set of options for the duration of a test.
+func useTestOptions(t *testing.T, options map[string]string) {
+	saved := config.Options
+	config.Options = options
+	t.Cleanup(func() { config.Options = saved })
+}
+
+// Find a local port with nothing listening on it.
+func closedPort(t *testing.T) int {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+	return port
+}
+
+func TestDeliverFailover(t *testing.T) {
+	down := Route{Id: "down", Name: "down", Type: RouteSMTP, Hostname: "127.0.0.1", Port: closedPort(t)}
+	busy := newTestSMTPServer(t, "451 4.3.0 try again later")
+	rejecting := newTestSMTPServer(t, "554 5.7.1 rejected")
+	ok := newTestSMTPServer(t, "250 2.0.0 queued")
+	useTestRoutes(t, down, busy.route("busy"), rejecting.route("rejecting"), ok.route("ok"))
+
+	tests := []struct {
+		members []string
+		member  string
+		fail    bool
+	}{
+		{[]string{"ok"}, "ok", false},
+		{[]string{"down", "busy", "ok"}, "ok", false},
+		{[]string{"busy", "down"}, "", true},
+		{[]string{"rejecting", "ok"}, "rejecting", true},
+		{[]string{"missing", "ok"}, "ok", false},
+		{[]string{}, "", true},
+	}
+	data := []byte("Subject: test\r\n\r\ntest\r\n")
+	for _, tt := range tests {
+		group := Route{Id: "group", Name: "group", Type: RouteFailover}
+		for i, id := range tt.members {
+			group.Members = append(group.Members, RouteMember{RouteId: id, Order: (i + 1) * 100})
+		}
+		member, err := Deliver(group, Message{From: "sender@example.com", To: []string{"recipient@example.com"}, Data: data})
+		if member != tt.member || (err != nil) != tt.fail {
+			t.Errorf("Deliver(%v) = %s, %v, want %s, failed %v", tt.members, member, err, tt.member, tt.fail)
+		}
+	}
+	if n := ok.received(); n != 3 {
+		t.Errorf("ok route received %d messages, want 3", n)
+	}
+}
+
+func TestDeliverFailoverPartial(t *testing.T) {
+	lmtp := newTestSMTPServer(t, "250 2.0.0 delivered")
+	lmtp.lmtp = true
+	lmtp.lmtpReplies["full@example.com"] = "452 4.2.2 mailbox full"
+	lmtp.lmtpReplies["quota@example.com"] = "552 5.2.2 over quota"
+	addr := lmtp.ln.Addr().(*net.TCPAddr)
+	lmtpRoute := Route{Id: "lmtp", Name: "lmtp", Type: RouteLMTP, Hostname: addr.IP.String(), Port: addr.Port}
+	busy := newTestSMTPServer(t, "451 4.3.0 try again later")
+	backup := newTestSMTPServer(t, "250 2.0.0 queued")
+	useTestRoutes(t, lmtpRoute, busy.route("busy"), backup.route("backup"))
+
+	// Only the recipient that failed temporarily is passed on to the next member.
+	tests := []struct {
+		members   []string
+		member    string
+		failed    string
+		temporary bool
+		delivered string
+	}{
+		{[]string{"lmtp", "backup"}, "backup", "quota@example.com", false, "a@example.com,full@example.com"},
+		{[]string{"lmtp", "busy"}, "busy", "quota@example.com,full@example.com", true, "a@example.com"},
+	}
+	to := []string{"a@example.com", "full@example.com", "quota@example.com"}
+	for _, tt := range tests {
+		group := Route{Id: "group", Name: "group", Type: RouteFailover}
+		for i, id := range tt.members {
+			group.Members = append(group.Members, RouteMember{RouteId: id, Order: (i + 1) * 100})
+		}
+		member, err := Deliver(group, Message{From: "sender@example.com", To: to, Data: []byte("Subject: test\r\n\r\ntest\r\n")})
+		var failed []string
+		for _, failure := range recipientFailures(to, err) {
+			failed = append(failed, failure.Recipient)
+		}
+		if member != tt.member || strings.Join(failed, ",") != tt.failed || IsTemporary(err) != tt.temporary {
+			t.Errorf("Deliver(%v) = %s, %v, want %s with %s failed, temporary %v", tt.members, member, err, tt.member, tt.failed, tt.temporary)
+		}
+		if delivered := DeliveredRecipients(to, err); strings.Join(delivered, ",") != tt.delivered {
+			t.Errorf("Deliver(%v) delivered to %v, want %s", tt.members, delivered, tt.delivered)
+		}
+	}
+	var rcpts []string
+	for _, cmd := range backup.commands {
+		if strings.HasPrefix(cmd, "RCPT") {
+			rcpts = append(rcpts, cmd)
+		}
+	}
+	if len(rcpts) != 1 || rcpts[0] != "RCPT TO:<full@example.com>" || backup.received() != 1 {
+		t.Errorf("backup member received %d messages for %v, want one for full@example.com", backup.received(), rcpts)
+	}
+}
+
+func TestIsTemporary(t *testing.T) {
+	busy := newTestSMTPServer(t, "452 4.3.1 insufficient storage")
+	rejecting := newTestSMTPServer(t, "550 5.1.1 no such user")
+	tests := []struct {
+		route Route
+		out   bool
+	}{
+		{busy.route("busy"), true},
+		{rejecting.route("rejecting"), false},
+		{Route{Name: "down", Hostname: "127.0.0.1", Port: closedPort(t)}, true},
+	}
+	for _, tt := range tests {
+		err := deliverSMTP(tt.route, Message{From: "sender@example.com", To: []string{"recipient@example.com"}, Data: []byte("\r\n")})
+		if x := IsTemporary(err); x != tt.out {
+			t.Errorf("IsTemporary(%v) = %v, want %v", err, x, tt.out)
+		}
+	}
+}
+
+func TestDeliverXForward(t *testing.T) {
+	client := smtpd.ClientInfo{Helo: "app.example.com", Proto: "ESMTP"}
+	tests := []struct {
+		ehlo     []string
+		xforward bool
+		pooled   bool
+		origin   string
+		want     string
+	}{
+		{[]string{"XFORWARD NAME ADDR PROTO HELO SOURCE"}, true, false, "192.0.2.1", "XFORWARD NAME=[UNAVAILABLE] ADDR=192.0.2.1 PROTO=ESMTP HELO=app.example.com"},
+		{[]string{"XFORWARD ADDR HELO"}, true, true, "2001:db8::1", "XFORWARD ADDR=IPV6:2001:db8::1 HELO=app.example.com"},
+		{[]string{"XFORWARD NAME ADDR PROTO HELO"}, true, false, "", ""},
+		{[]string{"XFORWARD NAME ADDR PROTO HELO"}, false, false, "192.0.2.1", ""},
+		{nil, true, false, "192.0.2.1", ""},
+	}
+	for _, tt := range tests {
+		server := newTestSMTPServer(t, "250 2.0.0 queued")
+		server.ehlo = tt.ehlo
+		route := server.route("xforward")
+		route.XForward, route.ReuseConnections = tt.xforward, tt.pooled
+		msg := Message{From: "sender@example.com", To: []string{"a@example.com"}, Data: []byte("\r\n"), Origin: net.ParseIP(tt.origin), Client: client}
+		for i := 0; i < 2; i++ {
+			if _, err := Deliver(route, msg); err != nil {
+				t.Fatalf("Deliver(%v) = %v", tt.ehlo, err)
+			}
+		}
+		server.Lock()
+		commands := strings.Join(server.commands, "\n")
+		server.Unlock()
+
+		// The attributes only last for one mail transaction, so they are sent for each message.
+		want := "MAIL FROM:<sender@example.com>"
+		if tt.want != "" {
+			want = tt.want + "\n" + want
+		}
+		if strings.Count(commands, want) != 2 || (tt.want == "" && strings.Contains(commands, "XFORWARD")) {
+			t.Errorf("Deliver(%v, xforward %v, origin %q) sent %q, want %q before each MAIL", tt.ehlo, tt.xforward, tt.origin, commands, tt.want)
+		}
+	}
+}
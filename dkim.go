@@ -0,0 +1,304 @@
+package main
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Maximum number of DKIM-Signature headers verified per message.
+const DKIMMaxSignatures = 5
+
+// The outcome of verifying a single DKIM-Signature header.
+type DKIMResult struct {
+	Domain   string
+	Selector string
+	Result   string
+	Reason   string
+}
+
+// Verify every DKIM signature on a message.
+func VerifyDKIM(data []byte) []DKIMResult {
+	headers, body := SplitMessage(data)
+	var results []DKIMResult
+	for i := len(headers) - 1; i >= 0; i-- {
+		if !strings.EqualFold(HeaderName(headers[i]), "DKIM-Signature") {
+			continue
+		}
+		if len(results) == DKIMMaxSignatures {
+			break
+		}
+		results = append(results, verifyDKIMSignature(headers, body, headers[i]))
+	}
+	return results
+}
+
+// Summarise the per-signature results as a single result: pass if any signature passed.
+func DKIMSummary(results []DKIMResult) string {
+	if len(results) == 0 {
+		return AuthNone
+	}
+	summary := results[0].Result
+	for _, r := range results {
+		if r.Result == AuthPass {
+			return AuthPass
+		}
+		if r.Result == AuthTempError {
+			summary = AuthTempError
+		}
+	}
+	return summary
+}
+
+func verifyDKIMSignature(headers []string, body []byte, field string) DKIMResult {
+	tags, err := ParseTags(HeaderValue(field))
+	if err != nil {
+		return DKIMResult{Result: AuthPermError, Reason: err.Error()}
+	}
+	result := DKIMResult{Domain: strings.ToLower(tags["d"]), Selector: tags["s"]}
+	fail := func(res string, format string, args ...interface{}) DKIMResult {
+		result.Result = res
+		result.Reason = fmt.Sprintf(format, args...)
+		return result
+	}
+
+	for _, tag := range []string{"v", "a", "b", "bh", "d", "h", "s"} {
+		if tags[tag] == "" {
+			return fail(AuthPermError, "missing %s= tag", tag)
+		}
+	}
+	if tags["v"] != "1" {
+		return fail(AuthPermError, "unsupported version %s", tags["v"])
+	}
+
+	var hashType crypto.Hash
+	var newHash func() hash.Hash
+	keyType := "rsa"
+	switch strings.ToLower(tags["a"]) {
+	case "rsa-sha256":
+		hashType, newHash = crypto.SHA256, sha256.New
+	case "rsa-sha1":
+		hashType, newHash = crypto.SHA1, sha1.New
+	case "ed25519-sha256":
+		hashType, newHash, keyType = crypto.SHA256, sha256.New, "ed25519"
+	default:
+		return fail(AuthPermError, "unsupported algorithm %s", tags["a"])
+	}
+
+	signed := strings.Split(tags["h"], ":")
+	hasFrom := false
+	for i := range signed {
+		signed[i] = strings.TrimSpace(signed[i])
+		if strings.EqualFold(signed[i], "From") {
+			hasFrom = true
+		}
+	}
+	if !hasFrom {
+		return fail(AuthPermError, "From header not signed")
+	}
+
+	if id := tags["i"]; id != "" {
+		idDomain := strings.ToLower(id[strings.LastIndex(id, "@")+1:])
+		if idDomain != result.Domain && !strings.HasSuffix(idDomain, "."+result.Domain) {
+			return fail(AuthPermError, "identity %s not within domain %s", id, result.Domain)
+		}
+	}
+	if x := tags["x"]; x != "" {
+		expiry, err := strconv.ParseInt(x, 10, 64)
+		if err != nil {
+			return fail(AuthPermError, "invalid x= tag")
+		}
+		if time.Now().Unix() > expiry {
+			return fail(AuthFail, "signature expired")
+		}
+	}
+
+	headerCanon, bodyCanon := "simple", "simple"
+	if c := strings.ToLower(tags["c"]); c != "" {
+		parts := strings.SplitN(c, "/", 2)
+		headerCanon = parts[0]
+		if len(parts) == 2 {
+			bodyCanon = parts[1]
+		}
+	}
+	if (headerCanon != "simple" && headerCanon != "relaxed") || (bodyCanon != "simple" && bodyCanon != "relaxed") {
+		return fail(AuthPermError, "unsupported canonicalization %s", tags["c"])
+	}
+
+	// Check the body hash.
+	canonBody := CanonicalBody(body, bodyCanon)
+	if l := tags["l"]; l != "" {
+		length, err := strconv.Atoi(l)
+		if err != nil || length < 0 || length > len(canonBody) {
+			return fail(AuthPermError, "invalid l= tag")
+		}
+		canonBody = canonBody[:length]
+	}
+	h := newHash()
+	h.Write(canonBody)
+	bodyHash, err := base64.StdEncoding.DecodeString(tags["bh"])
+	if err != nil {
+		return fail(AuthPermError, "invalid bh= tag")
+	}
+	if !bytes.Equal(h.Sum(nil), bodyHash) {
+		return fail(AuthFail, "body hash did not verify")
+	}
+
+	// Fetch the public key.
+	name := tags["s"] + "._domainkey." + result.Domain
+	txts, err := resolver.LookupTXT(name)
+	if err != nil && !IsNotFound(err) {
+		return fail(AuthTempError, "key lookup for %s failed", name)
+	}
+	if len(txts) == 0 {
+		return fail(AuthPermError, "no key for signature")
+	}
+	keyTags, err := ParseTags(strings.Join(txts, ""))
+	if err != nil {
+		return fail(AuthPermError, "invalid key record: %s", err)
+	}
+	if k := keyTags["k"]; k != "" && !strings.EqualFold(k, keyType) {
+		return fail(AuthPermError, "key type %s does not match algorithm", k)
+	}
+	if keyTags["p"] == "" {
+		return fail(AuthPermError, "key revoked")
+	}
+	keyData, err := base64.StdEncoding.DecodeString(keyTags["p"])
+	if err != nil {
+		return fail(AuthPermError, "invalid key data")
+	}
+	sig, err := base64.StdEncoding.DecodeString(tags["b"])
+	if err != nil {
+		return fail(AuthPermError, "invalid b= tag")
+	}
+
+	// Hash the signed headers, selecting instances from the bottom up, followed by the signature itself.
+	h = newHash()
+	used := map[int]bool{}
+	for _, name := range signed {
+		for i := len(headers) - 1; i >= 0; i-- {
+			if used[i] || !strings.EqualFold(HeaderName(headers[i]), name) {
+				continue
+			}
+			used[i] = true
+			h.Write([]byte(CanonicalHeader(headers[i], headerCanon) + "\r\n"))
+			break
+		}
+	}
+	h.Write([]byte(CanonicalHeader(stripSignature(field), headerCanon)))
+	hashed := h.Sum(nil)
+
+	switch keyType {
+	case "rsa":
+		pub, err := x509.ParsePKIXPublicKey(keyData)
+		if err != nil {
+			pub, err = x509.ParsePKCS1PublicKey(keyData)
+		}
+		rsaPub, ok := pub.(*rsa.PublicKey)
+		if err != nil || !ok {
+			return fail(AuthPermError, "invalid RSA key")
+		}
+		if rsa.VerifyPKCS1v15(rsaPub, hashType, hashed, sig) != nil {
+			return fail(AuthFail, "signature did not verify")
+		}
+	case "ed25519":
+		if len(keyData) != ed25519.PublicKeySize {
+			return fail(AuthPermError, "invalid Ed25519 key")
+		}
+		if !ed25519.Verify(ed25519.PublicKey(keyData), hashed, sig) {
+			return fail(AuthFail, "signature did not verify")
+		}
+	}
+
+	result.Result = AuthPass
+	return result
+}
+
+// Remove the value of the b= tag from a DKIM-Signature header, leaving everything else untouched.
+func stripSignature(field string) string {
+	colon := strings.Index(field, ":")
+	parts := strings.Split(field[colon+1:], ";")
+	for i, part := range parts {
+		eq := strings.Index(part, "=")
+		if eq > 0 && strings.TrimSpace(part[:eq]) == "b" {
+			parts[i] = part[:eq+1]
+		}
+	}
+	return field[:colon+1] + strings.Join(parts, ";")
+}
+
+// Parse a tag=value list as used by DKIM signatures, DKIM keys and DMARC records.
+// Whitespace is removed from values, which is harmless for the tags used here.
+func ParseTags(s string) (map[string]string, error) {
+	tags := map[string]string{}
+	for _, part := range strings.Split(s, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		eq := strings.Index(part, "=")
+		if eq < 1 {
+			return nil, fmt.Errorf("malformed tag %q", part)
+		}
+		name := strings.TrimSpace(part[:eq])
+		if _, exists := tags[name]; exists {
+			return nil, fmt.Errorf("duplicate tag %q", name)
+		}
+		tags[name] = strings.Join(strings.Fields(part[eq+1:]), "")
+	}
+	return tags, nil
+}
+
+// Canonicalize a header field (without its trailing CRLF) per RFC 6376 section 3.4.
+func CanonicalHeader(field string, canon string) string {
+	if canon == "simple" {
+		return field
+	}
+	colon := strings.Index(field, ":")
+	if colon < 0 {
+		return field
+	}
+	name := strings.ToLower(strings.TrimSpace(field[:colon]))
+	value := strings.Join(strings.Fields(field[colon+1:]), " ")
+	return name + ":" + value
+}
+
+// Canonicalize a message body per RFC 6376 section 3.4.
+func CanonicalBody(body []byte, canon string) []byte {
+	lines := strings.Split(string(body), "\r\n")
+	if canon == "relaxed" {
+		for i, line := range lines {
+			fields := strings.FieldsFunc(line, func(r rune) bool { return r == ' ' || r == '\t' })
+			if len(fields) == 0 {
+				lines[i] = ""
+				continue
+			}
+			lead := ""
+			if line[0] == ' ' || line[0] == '\t' {
+				lead = " "
+			}
+			lines[i] = lead + strings.Join(fields, " ")
+		}
+	}
+	// Remove trailing empty lines.
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		if canon == "simple" {
+			return []byte("\r\n")
+		}
+		return []byte{}
+	}
+	return []byte(strings.Join(lines, "\r\n") + "\r\n")
+}
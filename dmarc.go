@@ -0,0 +1,96 @@
+package main
+
+import (
+	"strings"
+)
+
+// Evaluate DMARC for the RFC 5322 From domain given the SPF and DKIM results.
+// Returns the result and the published policy, if any.
+func CheckDMARC(fromDomain string, spfResult string, spfDomain string, dkim []DKIMResult) (string, string) {
+	if fromDomain == "" {
+		return AuthNone, ""
+	}
+
+	// Look for a record at the From domain, then at its organizational domain.
+	tags, result := lookupDMARC(fromDomain)
+	policyTag := "p"
+	if result == AuthNone {
+		if org := OrganizationalDomain(fromDomain); org != fromDomain {
+			tags, result = lookupDMARC(org)
+			policyTag = "sp"
+		}
+	}
+	if result != "" {
+		return result, ""
+	}
+	policy := strings.ToLower(tags[policyTag])
+	if policy == "" {
+		policy = strings.ToLower(tags["p"])
+	}
+
+	if spfResult == AuthPass && Aligned(fromDomain, spfDomain, tags["aspf"] == "s") {
+		return AuthPass, policy
+	}
+	for _, r := range dkim {
+		if r.Result == AuthPass && Aligned(fromDomain, r.Domain, tags["adkim"] == "s") {
+			return AuthPass, policy
+		}
+	}
+	return AuthFail, policy
+}
+
+// Fetch and parse the DMARC record for a domain. Returns a result string if no usable record was found.
+func lookupDMARC(domain string) (map[string]string, string) {
+	txts, err := resolver.LookupTXT("_dmarc." + domain)
+	if err != nil && !IsNotFound(err) {
+		return nil, AuthTempError
+	}
+	for _, txt := range txts {
+		txt = strings.TrimSpace(txt)
+		if !strings.HasPrefix(txt, "v=DMARC1") {
+			continue
+		}
+		tags, err := ParseTags(txt)
+		if err != nil || tags["v"] != "DMARC1" {
+			return nil, AuthPermError
+		}
+		switch strings.ToLower(tags["p"]) {
+		case "none", "quarantine", "reject":
+			return tags, ""
+		}
+		return nil, AuthPermError
+	}
+	return nil, AuthNone
+}
+
+// Report whether an authenticated domain aligns with the From domain.
+// Relaxed alignment compares organizational domains; strict alignment requires an exact match.
+func Aligned(fromDomain string, domain string, strict bool) bool {
+	fromDomain = strings.ToLower(strings.TrimSuffix(fromDomain, "."))
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+	if domain == "" {
+		return false
+	}
+	if strict {
+		return fromDomain == domain
+	}
+	return OrganizationalDomain(fromDomain) == OrganizationalDomain(domain)
+}
+
+// Approximate the organizational domain without a public suffix list.
+// This keeps the last two labels, or three where the second-level label looks like a
+// country-code registry (e.g. example.co.uk, example.com.au).
+func OrganizationalDomain(domain string) string {
+	labels := strings.Split(strings.ToLower(strings.TrimSuffix(domain, ".")), ".")
+	keep := 2
+	if len(labels) > 2 && len(labels[len(labels)-1]) == 2 {
+		switch labels[len(labels)-2] {
+		case "ac", "co", "com", "edu", "gov", "net", "org", "ne", "or":
+			keep = 3
+		}
+	}
+	if len(labels) <= keep {
+		return strings.Join(labels, ".")
+	}
+	return strings.Join(labels[len(labels)-keep:], ".")
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"net/smtp"
+	"strings"
+
+	"github.com/mhale/mailrouter/smtpd"
+)
+
+// The delivery status notification parameters of a message (RFC 3461), and the recipients
+// whose notifications have been handed on.
+type DSNParams struct {
+	smtpd.DSNParams
+
+	// Recipients delivered to a server that supports DSN, which takes over sending notifications.
+	Forwarded map[string]bool
+}
+
+// Report whether any DSN parameters were given.
+func (d DSNParams) Requested() bool {
+	return d.Ret != "" || d.EnvId != "" || len(d.Notify) > 0 || len(d.ORcpt) > 0
+}
+
+// Report whether the sender asked to be notified of a condition for a recipient.
+// Without a NOTIFY parameter, failures and delays are notified but successes are not.
+func (d DSNParams) Wants(rcpt string, condition string) bool {
+	notify, ok := d.Notify[rcpt]
+	if !ok {
+		return condition != smtpd.NotifySuccess
+	}
+	for _, c := range strings.Split(notify, ",") {
+		if c == condition {
+			return true
+		}
+	}
+	return false
+}
+
+// Record that recipients were delivered to a server that sends its own notifications.
+func (d DSNParams) Forward(to []string) {
+	if d.Forwarded == nil {
+		return
+	}
+	for _, rcpt := range to {
+		d.Forwarded[rcpt] = true
+	}
+}
+
+// The DSN parameters to add to a MAIL command, with a leading space.
+func (d DSNParams) MailParams() string {
+	var params string
+	if d.Ret != "" {
+		params += " RET=" + d.Ret
+	}
+	if d.EnvId != "" {
+		params += " ENVID=" + smtpd.EncodeXtext(d.EnvId)
+	}
+	return params
+}
+
+// The DSN parameters to add to the RCPT command for a recipient, with a leading space.
+func (d DSNParams) RcptParams(rcpt string) string {
+	var params string
+	if notify, ok := d.Notify[rcpt]; ok {
+		params += " NOTIFY=" + notify
+	}
+	if orcpt, ok := d.ORcpt[rcpt]; ok {
+		i := strings.IndexByte(orcpt, ';')
+		params += " ORCPT=" + orcpt[:i+1] + smtpd.EncodeXtext(orcpt[i+1:])
+	}
+	return params
+}
+
+// Send the MAIL and RCPT commands for a message, passing on its DSN parameters if the server
+// supports the extension. Reports whether the parameters were passed on.
+func sendEnvelope(c *smtp.Client, msg Message) (bool, error) {
+	if ok, _ := c.Extension("DSN"); !ok || !msg.DSN.Requested() {
+		if err := c.Mail(msg.From); err != nil {
+			return false, err
+		}
+		for _, rcpt := range msg.To {
+			if err := c.Rcpt(rcpt); err != nil {
+				return false, err
+			}
+		}
+		return false, nil
+	}
+
+	cmd := func(expectCode int, format string, args ...interface{}) error {
+		id, err := c.Text.Cmd(format, args...)
+		if err != nil {
+			return err
+		}
+		c.Text.StartResponse(id)
+		defer c.Text.EndResponse(id)
+		_, _, err = c.Text.ReadResponse(expectCode)
+		return err
+	}
+	// Declare the same body and address types as smtp.Client.Mail does.
+	params := msg.DSN.MailParams()
+	if ok, _ := c.Extension("8BITMIME"); ok {
+		params += " BODY=8BITMIME"
+	}
+	if ok, _ := c.Extension("SMTPUTF8"); ok {
+		params += " SMTPUTF8"
+	}
+	if err := cmd(250, "MAIL FROM:<%s>%s", msg.From, params); err != nil {
+		return false, err
+	}
+	for _, rcpt := range msg.To {
+		if err := cmd(25, "RCPT TO:<%s>%s", rcpt, msg.DSN.RcptParams(rcpt)); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
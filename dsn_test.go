@@ -0,0 +1,79 @@
+package main
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/mhale/mailrouter/smtpd"
+)
+
+func TestDSNParamsWants(t *testing.T) {
+	dsn := DSNParams{DSNParams: smtpd.DSNParams{Notify: map[string]string{"never@example.com": "NEVER", "success@example.com": "SUCCESS,FAILURE"}}}
+	tests := []struct {
+		rcpt      string
+		condition string
+		out       bool
+	}{
+		{"default@example.com", smtpd.NotifyFailure, true},
+		{"default@example.com", smtpd.NotifyDelay, true},
+		{"default@example.com", smtpd.NotifySuccess, false},
+		{"never@example.com", smtpd.NotifyFailure, false},
+		{"success@example.com", smtpd.NotifySuccess, true},
+		{"success@example.com", smtpd.NotifyFailure, true},
+		{"success@example.com", smtpd.NotifyDelay, false},
+	}
+	for _, tt := range tests {
+		if out := dsn.Wants(tt.rcpt, tt.condition); out != tt.out {
+			t.Errorf("Wants(%s, %s) = %v, want %v", tt.rcpt, tt.condition, out, tt.out)
+		}
+	}
+}
+
+func TestDeliverDSN(t *testing.T) {
+	withDSN := newTestSMTPServer(t, "250 2.0.0 queued")
+	withDSN.ehlo = []string{"DSN"}
+	withMIME := newTestSMTPServer(t, "250 2.0.0 queued")
+	withMIME.ehlo = []string{"DSN", "8BITMIME", "SMTPUTF8"}
+	withoutDSN := newTestSMTPServer(t, "250 2.0.0 queued")
+	lmtp := newTestSMTPServer(t, "250 2.0.0 delivered")
+	lmtp.lmtp = true
+	lmtp.ehlo = []string{"DSN"}
+	addr := lmtp.ln.Addr().(*net.TCPAddr)
+	lmtpRoute := Route{Name: "lmtp", Type: RouteLMTP, Hostname: addr.IP.String(), Port: addr.Port}
+
+	dsn := DSNParams{DSNParams: smtpd.DSNParams{
+		Ret:    smtpd.RetFull,
+		EnvId:  "id+1",
+		Notify: map[string]string{"a@example.com": "SUCCESS"},
+		ORcpt:  map[string]string{"a@example.com": "rfc822;a+b@example.com"},
+	}}
+	tests := []struct {
+		server    *testSMTPServer
+		route     Route
+		mail      string
+		rcpt      string
+		forwarded bool
+	}{
+		{withDSN, withDSN.route("with"), "MAIL FROM:<sender@example.com> RET=FULL ENVID=id+2B1", "RCPT TO:<a@example.com> NOTIFY=SUCCESS ORCPT=rfc822;a+2Bb@example.com", true},
+		{withMIME, withMIME.route("mime"), "MAIL FROM:<sender@example.com> RET=FULL ENVID=id+2B1 BODY=8BITMIME SMTPUTF8", "RCPT TO:<a@example.com> NOTIFY=SUCCESS ORCPT=rfc822;a+2Bb@example.com", true},
+		{withoutDSN, withoutDSN.route("without"), "MAIL FROM:<sender@example.com>", "RCPT TO:<a@example.com>", false},
+		{lmtp, lmtpRoute, "MAIL FROM:<sender@example.com> RET=FULL ENVID=id+2B1", "RCPT TO:<a@example.com> NOTIFY=SUCCESS ORCPT=rfc822;a+2Bb@example.com", true},
+	}
+	for _, tt := range tests {
+		dsn.Forwarded = map[string]bool{}
+		msg := Message{From: "sender@example.com", To: []string{"a@example.com"}, Data: []byte("Subject: test\r\n\r\ntest\r\n"), DSN: dsn}
+		if _, err := Deliver(tt.route, msg); err != nil {
+			t.Fatalf("Deliver(%s) = %v", tt.route.Name, err)
+		}
+		tt.server.Lock()
+		commands := strings.Join(tt.server.commands, "\n")
+		tt.server.Unlock()
+		if !strings.Contains(commands, tt.mail+"\n"+tt.rcpt+"\n") {
+			t.Errorf("Deliver(%s) sent %q, want %q and %q", tt.route.Name, commands, tt.mail, tt.rcpt)
+		}
+		if dsn.Forwarded["a@example.com"] != tt.forwarded {
+			t.Errorf("Deliver(%s) forwarded notifications = %v, want %v", tt.route.Name, dsn.Forwarded["a@example.com"], tt.forwarded)
+		}
+	}
+}
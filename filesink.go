@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Counter to keep Maildir filenames unique within this process.
+var maildirSeq int64
+
+// Serialises mbox appends within this process. File locks guard against other processes.
+var mboxLock sync.Mutex
+
+// Deliver a message into a Maildir, creating the directory structure if needed.
+// The message is written to tmp/ and then moved into new/ so readers never see a partial file.
+func deliverMaildir(route Route, msg Message) error {
+	err := writeMaildir(route.Path, msg.From, msg.Data)
+	if err != nil {
+		return fmt.Errorf("route %s (%s): %w", route.Name, route.Path, err)
+	}
+	return nil
+}
+
+func writeMaildir(dir string, from string, data []byte) error {
+	if dir == "" {
+		return &DeliveryError{Msg: "no Maildir path configured", Permanent: true}
+	}
+	for _, sub := range []string{"tmp", "new", "cur"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0700); err != nil {
+			return err
+		}
+	}
+
+	name := MaildirName()
+	tmpPath := filepath.Join(dir, "tmp", name)
+	f, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(PrependHeader(data, "Return-Path", "<"+from+">"))
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err = os.Rename(tmpPath, filepath.Join(dir, "new", name)); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
+
+// Generate a unique Maildir filename of the form time.MusecPpidQseq.host.
+func MaildirName() string {
+	now := time.Now()
+	seq := atomic.AddInt64(&maildirSeq, 1)
+	host := strings.NewReplacer("/", `\057`, ":", `\072`).Replace(LocalHostname())
+	return fmt.Sprintf("%d.M%dP%dQ%d.%s", now.Unix(), now.Nanosecond()/1000, os.Getpid(), seq, host)
+}
+
+// Append a message to an mbox file in mboxrd format, holding an exclusive lock while writing.
+func deliverMbox(route Route, msg Message) error {
+	err := appendMbox(route.Path, msg.From, msg.Data)
+	if err != nil {
+		return fmt.Errorf("route %s (%s): %w", route.Name, route.Path, err)
+	}
+	return nil
+}
+
+func appendMbox(path string, from string, data []byte) error {
+	if path == "" {
+		return &DeliveryError{Msg: "no mbox path configured", Permanent: true}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	mboxLock.Lock()
+	defer mboxLock.Unlock()
+	if err = LockFile(f); err != nil {
+		return err
+	}
+	defer UnlockFile(f)
+
+	_, err = f.Write(MboxMessage(from, time.Now(), data))
+	if err == nil {
+		err = f.Sync()
+	}
+	return err
+}
+
+// Lines that need quoting in mboxrd format: any number of ">" followed by "From ".
+var mboxFromLine = regexp.MustCompile(`(?m)^(>*From )`)
+
+// Format a message as an mbox entry: a From_ line, the message with LF line endings and
+// From_ lines quoted, and a blank line separating it from the next message.
+func MboxMessage(from string, received time.Time, data []byte) []byte {
+	sender := from
+	if sender == "" {
+		sender = "MAILER-DAEMON"
+	}
+	data = bytes.Replace(data, []byte("\r\n"), []byte("\n"), -1)
+	data = mboxFromLine.ReplaceAll(data, []byte(">$1"))
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From %s %s\n", sender, received.UTC().Format(time.ANSIC))
+	fmt.Fprintf(&buf, "Return-Path: <%s>\n", from)
+	buf.Write(data)
+	if !bytes.HasSuffix(data, []byte("\n")) {
+		buf.WriteString("\n")
+	}
+	buf.WriteString("\n")
+	return buf.Bytes()
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDeliverMaildir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "Maildir")
+	route := Route{Name: "maildir", Type: RouteMaildir, Path: dir}
+	data := []byte("Subject: test\r\n\r\ntest\r\n")
+	for i := 0; i < 2; i++ {
+		if _, err := Deliver(route, Message{From: "sender@example.com", To: []string{"recipient@example.com"}, Data: data}); err != nil {
+			t.Fatalf("Deliver() = %v, want success", err)
+		}
+	}
+
+	for _, sub := range []string{"tmp", "cur"} {
+		if entries, err := os.ReadDir(filepath.Join(dir, sub)); err != nil || len(entries) != 0 {
+			t.Errorf("%s/ contains %d entries (%v), want 0", sub, len(entries), err)
+		}
+	}
+	entries, err := os.ReadDir(filepath.Join(dir, "new"))
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("new/ contains %d entries (%v), want 2", len(entries), err)
+	}
+	content, _ := os.ReadFile(filepath.Join(dir, "new", entries[0].Name()))
+	if want := "Return-Path: <sender@example.com>\r\n" + string(data); string(content) != want {
+		t.Errorf("Maildir message = %q, want %q", content, want)
+	}
+}
+
+func TestMboxMessage(t *testing.T) {
+	received := time.Date(2014, 3, 7, 9, 5, 1, 0, time.UTC)
+	tests := []struct {
+		from string
+		data string
+		out  string
+	}{
+		{
+			"sender@example.com",
+			"Subject: test\r\n\r\nFrom here\r\n>From there\r\n From nowhere\r\n",
+			"From sender@example.com Fri Mar  7 09:05:01 2014\nReturn-Path: <sender@example.com>\nSubject: test\n\n>From here\n>>From there\n From nowhere\n\n",
+		},
+		{
+			"",
+			"Subject: bounce\r\n\r\nno newline",
+			"From MAILER-DAEMON Fri Mar  7 09:05:01 2014\nReturn-Path: <>\nSubject: bounce\n\nno newline\n\n",
+		},
+	}
+	for _, tt := range tests {
+		if x := string(MboxMessage(tt.from, received, []byte(tt.data))); x != tt.out {
+			t.Errorf("MboxMessage(%s, %q) = %q, want %q", tt.from, tt.data, x, tt.out)
+		}
+	}
+}
+
+func TestDeliverMbox(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mail", "inbox")
+	route := Route{Name: "mbox", Type: RouteMbox, Path: path}
+	data := []byte("Subject: test\r\n\r\ntest\r\n")
+	for i := 0; i < 3; i++ {
+		if _, err := Deliver(route, Message{From: "sender@example.com", To: []string{"recipient@example.com"}, Data: data}); err != nil {
+			t.Fatalf("Deliver() = %v, want success", err)
+		}
+	}
+	content, _ := os.ReadFile(path)
+	if n := strings.Count(string(content), "\nFrom sender@example.com ") + 1; !strings.HasPrefix(string(content), "From ") || n != 3 {
+		t.Errorf("mbox contains %d messages, want 3", n)
+	}
+
+	if _, err := Deliver(Route{Name: "empty", Type: RouteMbox}, Message{Data: data}); err == nil || IsTemporary(err) {
+		t.Errorf("Deliver() without a path = %v, want permanent failure", err)
+	}
+}
@@ -2,11 +2,25 @@ package main
 
 import (
 	"fmt"
+	"log"
 	"net"
 	"sort"
+	"strconv"
 	"strings"
+
+	"github.com/mhale/mailrouter/smtpd"
+)
+
+// Filter actions. Filters without an action deliver via their route.
+const (
+	ActionRoute      = "route"
+	ActionQuarantine = "quarantine"
+	ActionReject     = "reject"
 )
 
+// Reply sent for rejected mail when a reject filter has no reply set.
+const DefaultRejectReply = "550 5.7.1 Message rejected"
+
 type Filter struct {
 	Id        string
 	Order     int
@@ -15,7 +29,13 @@ type Filter struct {
 	To        string
 	Subject   string
 	Origin    string
+	SPF       string
+	DKIM      string
+	DMARC     string
+	Listener  string // Name of the listener that received the message
 	RouteId   string
+	Action    string
+	Reply     string // SMTP reply for reject filters, e.g. "550 5.7.1 Outbound mail disabled"
 	Summary   string // Convenience field for filter listing
 	RouteName string // Convenience field for filter listing
 }
@@ -34,10 +54,22 @@ func (f *Filter) Summarise() string {
 	if f.Origin != "" {
 		attrs = append(attrs, fmt.Sprintf("Origin: %s", f.Origin))
 	}
+	if f.SPF != "" {
+		attrs = append(attrs, fmt.Sprintf("SPF: %s", f.SPF))
+	}
+	if f.DKIM != "" {
+		attrs = append(attrs, fmt.Sprintf("DKIM: %s", f.DKIM))
+	}
+	if f.DMARC != "" {
+		attrs = append(attrs, fmt.Sprintf("DMARC: %s", f.DMARC))
+	}
+	if f.Listener != "" {
+		attrs = append(attrs, fmt.Sprintf("Listener: %s", f.Listener))
+	}
 	return strings.Join(attrs, ", ")
 }
 
-func (f *Filter) Match(from string, to []string, subject string, originIP net.IP) bool {
+func (f *Filter) Match(from string, to []string, subject string, origin net.Addr, listener string, auth AuthResults) bool {
 	fieldsSet := 0
 	if f.From != "" {
 		fieldsSet++
@@ -59,7 +91,31 @@ func (f *Filter) Match(from string, to []string, subject string, originIP net.IP
 	}
 	if f.Origin != "" {
 		fieldsSet++
-		if !f.MatchOrigin(originIP) {
+		if !f.MatchOrigin(origin) {
+			return false
+		}
+	}
+	if f.Listener != "" {
+		fieldsSet++
+		if !strings.EqualFold(f.Listener, listener) {
+			return false
+		}
+	}
+	if f.SPF != "" {
+		fieldsSet++
+		if !MatchAuthResult(f.SPF, auth.SPF) {
+			return false
+		}
+	}
+	if f.DKIM != "" {
+		fieldsSet++
+		if !MatchAuthResult(f.DKIM, auth.DKIM) {
+			return false
+		}
+	}
+	if f.DMARC != "" {
+		fieldsSet++
+		if !MatchAuthResult(f.DMARC, auth.DMARC) {
 			return false
 		}
 	}
@@ -68,6 +124,22 @@ func (f *Filter) Match(from string, to []string, subject string, originIP net.IP
 	return fieldsSet > 0
 }
 
+// Report whether the filter only checks the envelope (origin, listener, sender and recipients), so it can
+// be evaluated for each recipient before the message data is received.
+func (f *Filter) EnvelopeOnly() bool {
+	return f.Subject == "" && f.SPF == "" && f.DKIM == "" && f.DMARC == ""
+}
+
+// Return the SMTP reply for a reject filter, falling back to the default if the filter's reply is invalid.
+func (f *Filter) RejectReply() *smtpd.SMTPError {
+	reply, err := smtpd.ParseReply(f.Reply, 550)
+	if err != nil {
+		log.Printf("Invalid reply for filter %s: %v", f.Name, err)
+		reply, _ = smtpd.ParseReply(DefaultRejectReply, 550)
+	}
+	return reply
+}
+
 func (f *Filter) MatchFrom(from string) bool {
 	if f.From == "" {
 		return false
@@ -95,19 +167,87 @@ func (f *Filter) MatchSubject(subject string) bool {
 	return strings.Contains(subject, f.Subject)
 }
 
-func (f *Filter) MatchOrigin(originIP net.IP) bool {
-	// Is filter.Origin in CIDR notation e.g. "192.168.100.1/24" or "2001:DB8::/48"?
-	_, filterNet, err := net.ParseCIDR(f.Origin)
-	if err == nil {
-		return filterNet.Contains(originIP)
+// Match the origin of a message. Clients on a Unix socket match "local", or "uid:N" and "gid:N"
+// for the user and group of the connecting process. Other clients match by IP address.
+func (f *Filter) MatchOrigin(origin net.Addr) bool {
+	if local, ok := origin.(*smtpd.LocalAddr); ok {
+		return MatchLocal(f.Origin, local)
+	}
+	originIP := smtpd.OriginIP(origin)
+	if originIP == nil {
+		return false
+	}
+	return MatchAddress(f.Origin, originIP)
+}
+
+// Match a local client against a filter origin: "local" matches any local client, and
+// "uid:N" or "gid:N" match the user or group of the client's process.
+func MatchLocal(pattern string, a *smtpd.LocalAddr) bool {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	if pattern == "local" {
+		return true
+	}
+	if !a.Known {
+		return false
+	}
+	for prefix, id := range map[string]int{"uid:": a.Uid, "gid:": a.Gid} {
+		if strings.HasPrefix(pattern, prefix) {
+			n, err := strconv.Atoi(pattern[len(prefix):])
+			return err == nil && n == id
+		}
+	}
+	return false
+}
+
+// Match an IP address against a network in CIDR notation, e.g. "192.168.100.1/24" or
+// "2001:DB8::/48", or against a single IPv4 or IPv6 address.
+func MatchAddress(pattern string, ip net.IP) bool {
+	network, err := ParseNetwork(pattern)
+	return err == nil && network.Contains(ip)
+}
+
+// Parse a network in CIDR notation or a single address, which is a network of one address.
+// Filters and the client allow and deny lists both use this, so they accept the same entries.
+func ParseNetwork(pattern string) (*net.IPNet, error) {
+	if _, network, err := net.ParseCIDR(pattern); err == nil {
+		return network, nil
 	}
+	ip := net.ParseIP(pattern)
+	if ip == nil {
+		return nil, fmt.Errorf("%q is not a network or address", pattern)
+	}
+	bits := 8 * net.IPv6len
+	if ip4 := ip.To4(); ip4 != nil {
+		ip, bits = ip4, 8*net.IPv4len
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+}
 
-	// Is filter.Origin a valid IPv4 or IPv6 address?
-	filterIP := net.ParseIP(f.Origin)
-	if filterIP != nil {
-		return filterIP.Equal(originIP)
+// Parse a list of networks in CIDR notation and single addresses, e.g. "192.0.2.0/24" or
+// "2001:db8::1". A single address is a network of one address. Empty entries are skipped.
+func ParseNetworks(list []string) ([]*net.IPNet, error) {
+	var networks []*net.IPNet
+	for _, entry := range list {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		network, err := ParseNetwork(entry)
+		if err != nil {
+			return nil, err
+		}
+		networks = append(networks, network)
 	}
+	return networks, nil
+}
 
+// Match an authentication result against a filter value, which may list several results e.g. "fail,softfail".
+func MatchAuthResult(want string, result string) bool {
+	for _, w := range strings.Split(want, ",") {
+		if strings.EqualFold(strings.TrimSpace(w), result) {
+			return true
+		}
+	}
 	return false
 }
 
@@ -126,10 +266,11 @@ func (fl FilterList) Less(i, j int) bool {
 	return fl[i].Order < fl[j].Order
 }
 
-func SortedFilters() FilterList {
-	fl := make(FilterList, len(config.Filters))
+// Return the filters in the order they are checked.
+func (c *Config) SortedFilters() FilterList {
+	fl := make(FilterList, len(c.Filters))
 	i := 0
-	for _, filter := range config.Filters {
+	for _, filter := range c.Filters {
 		fl[i] = filter
 		i++
 	}
@@ -3,7 +3,10 @@ package main
 import (
 	"fmt"
 	"net"
+	"strings"
 	"testing"
+
+	"github.com/mhale/mailrouter/smtpd"
 )
 
 // Test for valid generation of summary string.
@@ -53,11 +56,14 @@ func TestFilterMatch(t *testing.T) {
 		{Filter{Subject: "Lorem ipsum dolor sit amet"}, true},
 		{Filter{Subject: "Lorem ipsum dolor sit amet", Origin: "127.0.0.1"}, true},
 		{Filter{Origin: "127.0.0.1"}, true},
+		{Filter{Listener: "staging"}, true},
+		{Filter{Listener: "Staging", Origin: "127.0.0.1"}, true},
 		// Full field negative matches
 		{Filter{From: "sender2@example.com"}, false},
 		{Filter{From: "sender@example.com", To: "recipient2@example.com"}, false},
 		{Filter{From: "sender@example.com", To: "recipient@example.com", Subject: "Lorem ipsum dolor sit amet 2"}, false},
 		{Filter{From: "sender@example.com", To: "recipient@example.com", Subject: "Lorem ipsum dolor sit amet", Origin: "127.0.0.2"}, false},
+		{Filter{From: "sender@example.com", Listener: "production"}, false},
 		// Partial field positive matches
 		{Filter{From: "sender", To: "recipient@example.com", Subject: "Lorem ipsum dolor sit amet"}, true},
 		{Filter{From: "sender@example.com", To: "recipient", Subject: "Lorem ipsum dolor sit amet"}, true},
@@ -70,10 +76,11 @@ func TestFilterMatch(t *testing.T) {
 	from := "sender@example.com"
 	to := []string{"recipient@example.com"}
 	subject := "Lorem ipsum dolor sit amet"
-	originIP := net.ParseIP("127.0.0.1")
+	origin := &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 25}
+	listener := "staging"
 	for _, tt := range tests {
-		if x := tt.f.Match(from, to, subject, originIP); x != tt.out {
-			t.Errorf("Filter{%v}.Match(%v, %v, %v, %v) = %v, want %v", tt.f, from, to, subject, originIP, x, tt.out)
+		if x := tt.f.Match(from, to, subject, origin, listener, AuthResults{}); x != tt.out {
+			t.Errorf("Filter{%v}.Match(%v, %v, %v, %v, %v) = %v, want %v", tt.f, from, to, subject, origin, listener, x, tt.out)
 		}
 	}
 }
@@ -156,7 +163,7 @@ func TestFilterMatchOrigin(t *testing.T) {
 		{"127.0.0.1/32", true},
 	}
 	f := Filter{}
-	addr := net.ParseIP("127.0.0.1")
+	addr := &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 25}
 	for _, tt := range tests {
 		f.Origin = tt.origin
 		if x := f.MatchOrigin(addr); x != tt.out {
@@ -164,3 +171,159 @@ func TestFilterMatchOrigin(t *testing.T) {
 		}
 	}
 }
+
+func TestFilterMatchLocalOrigin(t *testing.T) {
+	tests := []struct {
+		origin net.Addr
+		filter string
+		out    bool
+	}{
+		{&smtpd.LocalAddr{}, "local", true},
+		{&smtpd.LocalAddr{}, "LOCAL", true},
+		{&smtpd.LocalAddr{}, "uid:0", false},
+		{&smtpd.LocalAddr{Uid: 1000, Gid: 100, Known: true}, "uid:1000", true},
+		{&smtpd.LocalAddr{Uid: 1000, Gid: 100, Known: true}, "gid:100", true},
+		{&smtpd.LocalAddr{Uid: 1000, Gid: 100, Known: true}, "uid:100", false},
+		{&smtpd.LocalAddr{Uid: 1000, Gid: 100, Known: true}, "127.0.0.1", false},
+		{&net.TCPAddr{IP: net.ParseIP("127.0.0.1")}, "local", false},
+		{&net.UnixAddr{Name: "@", Net: "unix"}, "127.0.0.1", false},
+	}
+	for _, tt := range tests {
+		f := Filter{Origin: tt.filter}
+		if x := f.MatchOrigin(tt.origin); x != tt.out {
+			t.Errorf("Filter{Origin: %s}.MatchOrigin(%v) = %v, want %v", tt.filter, tt.origin, x, tt.out)
+		}
+	}
+}
+
+func TestParseNetworks(t *testing.T) {
+	tests := []struct {
+		list []string
+		want string
+		err  bool
+	}{
+		{[]string{"192.0.2.0/24", " 2001:db8::/32", ""}, "192.0.2.0/24 2001:db8::/32", false},
+		{[]string{"192.0.2.1", "2001:db8::1"}, "192.0.2.1/32 2001:db8::1/128", false},
+		{[]string{"192.0.2.7/24"}, "192.0.2.0/24", false},
+		{[]string{"::ffff:192.0.2.1"}, "192.0.2.1/32", false},
+		{[]string{"fe80::1%eth0"}, "", true},
+		{[]string{"10.0.0.0/33"}, "", true},
+		{[]string{"192.0.2.0/24", "not an address"}, "", true},
+	}
+	for _, tt := range tests {
+		networks, err := ParseNetworks(tt.list)
+		var got []string
+		for _, network := range networks {
+			got = append(got, network.String())
+		}
+		if (err != nil) != tt.err || strings.Join(got, " ") != tt.want {
+			t.Errorf("ParseNetworks(%q) = %v, %v, want %s", tt.list, got, err, tt.want)
+		}
+	}
+}
+
+func TestMatchAuthResult(t *testing.T) {
+	tests := []struct {
+		want   string
+		result string
+		out    bool
+	}{
+		{"fail", "fail", true},
+		{"fail", "pass", false},
+		{"FAIL", "fail", true},
+		{"fail,softfail", "softfail", true},
+		{"fail, softfail", "softfail", true},
+		{"fail,softfail", "neutral", false},
+		{"none", "", false},
+	}
+	for _, tt := range tests {
+		if x := MatchAuthResult(tt.want, tt.result); x != tt.out {
+			t.Errorf("MatchAuthResult(%s, %s) = %v, want %v", tt.want, tt.result, x, tt.out)
+		}
+	}
+
+	// Authentication fields combine with other fields like any other condition.
+	f := Filter{From: "sender", DMARC: "fail"}
+	auth := AuthResults{DMARC: AuthFail}
+	if !f.Match("sender@example.com", nil, "", nil, "", auth) {
+		t.Errorf("Filter{%v}.Match() = false, want true", f)
+	}
+	auth.DMARC = AuthPass
+	if f.Match("sender@example.com", nil, "", nil, "", auth) {
+		t.Errorf("Filter{%v}.Match() = true, want false", f)
+	}
+}
+
+// Test that reject filters refuse mail with their reply, unless an earlier filter quarantined it.
+func TestRejectFilter(t *testing.T) {
+	useTestCapture(t)
+	useTestRoutes(t, Route{Id: "DROP", Name: "Drop"}, Route{Id: "inbox", Name: "Inbox", Type: RouteCapture, IsDefault: true})
+	savedFilters := config.Filters
+	config.Filters = map[string]Filter{
+		"hold":   {Id: "hold", Order: 100, Name: "Hold", Subject: "hold", Action: ActionQuarantine},
+		"reject": {Id: "reject", Order: 200, Name: "No outbound", To: "@external.com", Action: ActionReject, Reply: "550 5.7.1 Outbound mail disabled in this environment"},
+	}
+	t.Cleanup(func() { config.Filters = savedFilters })
+	savedQuarantine := quarantine.Messages
+	t.Cleanup(func() { quarantine.Messages = savedQuarantine })
+
+	origin := &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 25}
+	tests := []struct {
+		to      string
+		subject string
+		reply   string
+	}{
+		{"user@external.com", "test", "550 5.7.1 Outbound mail disabled in this environment"},
+		{"user@internal.com", "test", ""},
+		{"user@external.com", "hold", ""},
+	}
+	for _, tt := range tests {
+		err := mailHandler(origin, smtpd.Envelope{From: "sender@example.com", To: []string{tt.to}}, []byte("Subject: "+tt.subject+"\r\n\r\ntest\r\n"))
+		if (err == nil && tt.reply != "") || (err != nil && err.Error() != tt.reply) {
+			t.Errorf("mailHandler(%s, %s) = %v, want %q", tt.to, tt.subject, err, tt.reply)
+		}
+	}
+	if logs.Logs[2].Status != "Rejected" || logs.Logs[2].Filter != "No outbound" {
+		t.Errorf("reject log = %+v, want Rejected by No outbound", logs.Logs[2])
+	}
+	if len(captured.Search("")) != 1 {
+		t.Errorf("%d messages delivered, want 1", len(captured.Search("")))
+	}
+
+	// Mail that can't be parsed is accepted and dropped, as it can't be filtered.
+	if err := mailHandler(origin, smtpd.Envelope{From: "sender@example.com", To: []string{"a@internal.com"}}, []byte("no header end")); err != nil {
+		t.Errorf("mailHandler(unparseable) = %v, want nil", err)
+	}
+	if len(captured.Search("")) != 1 {
+		t.Errorf("%d messages delivered after an unparseable message, want 1", len(captured.Search("")))
+	}
+}
+
+// Test that envelope-only filters are applied to each recipient, stopping at the first filter
+// that needs the message content.
+func TestRcptFilter(t *testing.T) {
+	savedFilters := config.Filters
+	config.Filters = map[string]Filter{
+		"allow":   {Id: "allow", Order: 100, Name: "Allow QA", To: "qa@external.com", RouteId: "inbox"},
+		"reject":  {Id: "reject", Order: 200, Name: "No outbound", To: "@external.com", Action: ActionReject, Reply: "550 5.7.1 Outbound mail disabled"},
+		"subject": {Id: "subject", Order: 300, Name: "Subject", Subject: "urgent", RouteId: "inbox"},
+		"later":   {Id: "later", Order: 400, Name: "Later", From: "sender@", Action: ActionReject},
+	}
+	t.Cleanup(func() { config.Filters = savedFilters })
+
+	origin := &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 25}
+	tests := []struct {
+		to    string
+		reply string
+	}{
+		{"qa@external.com", ""},
+		{"user@external.com", "550 5.7.1 Outbound mail disabled"},
+		{"user@internal.com", ""}, // The subject filter must be checked first, after DATA.
+	}
+	for _, tt := range tests {
+		err := rcptHandler(origin, smtpd.Envelope{From: "sender@example.com"}, tt.to)
+		if (err == nil && tt.reply != "") || (err != nil && err.Error() != tt.reply) {
+			t.Errorf("rcptHandler(%s) = %v, want %q", tt.to, err, tt.reply)
+		}
+	}
+}
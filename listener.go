@@ -0,0 +1,258 @@
+package main
+
+import (
+	"crypto/subtle"
+	"crypto/tls"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/mhale/mailrouter/smtpd"
+)
+
+// Name of the listener started from the -smtp flag when the configuration has none.
+const DefaultListenerName = "default"
+
+// Permissions of a listener's Unix socket if none are configured: the owner and group may connect.
+const DefaultSocketMode = 0660
+
+// An SMTP listener, with its own TLS and authentication policy and its own default route.
+type Listener struct {
+	Name           string
+	Addr           string // TCP address to listen on, e.g. ":2525"
+	DefaultRouteId string // Route for mail that matches no filter. Empty uses the default route.
+
+	// Domains that recipients must belong to. Empty uses the RecipientDomains option.
+	RecipientDomains []string
+
+	// A Unix socket to listen on instead of Addr, for clients on the same machine.
+	Socket     string // Path of the socket, e.g. "/run/mailrouter/smtp.sock"
+	SocketMode string // Octal permissions of the socket. Empty uses DefaultSocketMode.
+
+	// STARTTLS is offered when a certificate and key file are set.
+	TLSCert    string
+	TLSKey     string
+	RequireTLS bool // Refuse mail from clients that have not started TLS
+
+	// AUTH is offered when users are set. If the listener has TLS, clients must start TLS first.
+	Users       map[string]string // Passwords, keyed by username
+	RequireAuth bool              // Refuse mail from clients that have not authenticated
+
+	// Proxies in TrustedProxies may give the address of the real client, with a PROXY protocol
+	// header or the XCLIENT command, so filters and limits see it instead of the proxy.
+	TrustedProxies []string // Networks and addresses of the proxies, e.g. "10.0.0.5/32"
+	ProxyProtocol  bool     // Connections from trusted proxies start with a PROXY header
+	XClient        bool     // Offer XCLIENT to trusted proxies
+}
+
+// Return the configured listeners, or a single listener on the -smtp address if there are none.
+func (c *Config) SMTPListeners() []Listener {
+	if len(c.Listeners) == 0 {
+		return []Listener{{Name: DefaultListenerName, Addr: *smtpAddr}}
+	}
+	return c.Listeners
+}
+
+// Return the listeners of the global config.
+func Listeners() []Listener {
+	config.RLock()
+	defer config.RUnlock()
+	return config.SMTPListeners()
+}
+
+// Return the names of the listeners, for the filter form.
+func ListenerNames() []string {
+	var names []string
+	for _, l := range Listeners() {
+		names = append(names, l.Name)
+	}
+	return names
+}
+
+// Return the id of the route for mail received by a listener that matches no filter:
+// the listener's own default route if it has one, otherwise the default route.
+func (c *Config) ListenerDefaultRouteId(name string) string {
+	for _, l := range c.Listeners {
+		if l.Name != name || l.DefaultRouteId == "" {
+			continue
+		}
+		if _, exists := c.Routes[l.DefaultRouteId]; exists {
+			return l.DefaultRouteId
+		}
+		log.Printf("Default route %s of listener %s does not exist, using the default route.", l.DefaultRouteId, l.Name)
+	}
+	return c.DefaultRouteId()
+}
+
+// Return the address the listener listens on, for logging.
+func (l Listener) Address() string {
+	if l.Socket != "" {
+		return l.Socket
+	}
+	return l.Addr
+}
+
+// Check a username and password against the listener's users.
+func (l Listener) Authenticate(username string, password string) bool {
+	want, exists := l.Users[username]
+	return exists && subtle.ConstantTimeCompare([]byte(password), []byte(want)) == 1
+}
+
+// Create an SMTP server for the listener, with the client options of c.
+func (l Listener) Server(c *Config) (*smtpd.Server, error) {
+	srv := &smtpd.Server{
+		Name:        l.Name,
+		Addr:        l.Addr,
+		Handler:     mailHandler,
+		RcptHandler: rcptHandler,
+		Appname:     "Mailrouter",
+		RequireTLS:  l.RequireTLS,
+		RequireAuth: l.RequireAuth,
+
+		RecipientDomains: l.RecipientDomains,
+		MaxRecipients:    c.OptionInt("MaxRecipientsPerMessage"),
+		Throttle:         &throttle,
+
+		ProxyProtocol: l.ProxyProtocol,
+		XClient:       l.XClient,
+	}
+	var err error
+	if srv.AllowClients, err = ParseNetworks(c.OptionList("AllowClients")); err != nil {
+		return nil, fmt.Errorf("option AllowClients: %v", err)
+	}
+	if srv.DenyClients, err = ParseNetworks(c.OptionList("DenyClients")); err != nil {
+		return nil, fmt.Errorf("option DenyClients: %v", err)
+	}
+	if srv.TrustedProxies, err = ParseNetworks(l.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("listener %s has invalid trusted proxies: %v", l.Name, err)
+	}
+	if len(srv.RecipientDomains) == 0 {
+		srv.RecipientDomains = c.OptionList("RecipientDomains")
+	}
+	if l.Socket != "" {
+		srv.Socket, srv.SocketMode = l.Socket, DefaultSocketMode
+		if l.SocketMode != "" {
+			mode, err := strconv.ParseUint(l.SocketMode, 8, 32)
+			if err != nil || mode > 0777 {
+				return nil, fmt.Errorf("listener %s has invalid socket mode %q", l.Name, l.SocketMode)
+			}
+			srv.SocketMode = os.FileMode(mode)
+		}
+	}
+	if l.TLSCert != "" || l.TLSKey != "" {
+		cert, err := tls.LoadX509KeyPair(l.TLSCert, l.TLSKey)
+		if err != nil {
+			return nil, fmt.Errorf("listener %s: %v", l.Name, err)
+		}
+		srv.TLSConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
+	}
+	if l.RequireTLS && srv.TLSConfig == nil {
+		return nil, fmt.Errorf("listener %s requires TLS but has no certificate", l.Name)
+	}
+	if len(l.Users) > 0 {
+		srv.Auth = l.Authenticate
+	}
+	if l.RequireAuth && srv.Auth == nil {
+		return nil, fmt.Errorf("listener %s requires authentication but has no users", l.Name)
+	}
+	if (l.ProxyProtocol || l.XClient) && len(l.TrustedProxies) == 0 {
+		return nil, fmt.Errorf("listener %s accepts client addresses from proxies but has no trusted proxies", l.Name)
+	}
+	return srv, nil
+}
+
+// A listener that is running, so a reload can update or stop it.
+type runningListener struct {
+	Listener
+	srv     *smtpd.Server
+	ln      net.Listener
+	stopped bool // Set when a reload closes the listener
+}
+
+// The running listeners, keyed by name.
+var running = struct {
+	sync.Mutex
+	listeners map[string]*runningListener
+	errs      chan error // Errors that stop a listener other than a reload closing it
+}{listeners: map[string]*runningListener{}, errs: make(chan error)}
+
+// Start the configured listeners, returning a channel that receives an error if one of them stops.
+func StartListeners() (<-chan error, error) {
+	running.Lock()
+	defer running.Unlock()
+	conf := ConfigSnapshot()
+	for _, l := range conf.SMTPListeners() {
+		srv, err := l.Server(conf)
+		if err != nil {
+			return nil, err
+		}
+		if err := startListener(l, srv); err != nil {
+			return nil, err
+		}
+	}
+	return running.errs, nil
+}
+
+// Start a listener with a server built from it. The caller must hold the lock.
+func startListener(l Listener, srv *smtpd.Server) error {
+	ln, err := srv.Listen()
+	if err != nil {
+		return fmt.Errorf("listener %s: %v", l.Name, err)
+	}
+	r := &runningListener{Listener: l, srv: srv, ln: ln}
+	running.listeners[l.Name] = r
+	log.Printf("Mailrouter serving SMTP on %s for listener %s", l.Address(), l.Name)
+	go func() {
+		err := srv.Serve(ln)
+		running.Lock()
+		stopped := r.stopped
+		running.Unlock()
+		if !stopped {
+			running.errs <- err
+		}
+	}()
+	return nil
+}
+
+// Bring the running listeners into line with the configuration after it is reloaded.
+// Listeners that keep their address carry on listening with their new settings, so no
+// connections are refused, while listeners that were removed or moved are closed and
+// started again. Sessions in progress are not interrupted either way.
+func UpdateListeners() {
+	running.Lock()
+	defer running.Unlock()
+	conf := ConfigSnapshot()
+	listeners := conf.SMTPListeners()
+	wanted := map[string]Listener{}
+	for _, l := range listeners {
+		wanted[l.Name] = l
+	}
+	for name, r := range running.listeners {
+		l, exists := wanted[name]
+		if exists && l.Addr == r.Addr && l.Socket == r.Socket && l.SocketMode == r.SocketMode {
+			continue
+		}
+		log.Printf("Stopping SMTP listener %s on %s", name, r.Address())
+		r.stopped = true
+		r.ln.Close()
+		delete(running.listeners, name)
+	}
+	for _, l := range listeners {
+		srv, err := l.Server(conf)
+		if err != nil {
+			log.Printf("Could not update SMTP listener: %v", err)
+			continue
+		}
+		if r, exists := running.listeners[l.Name]; exists {
+			r.srv.Replace(srv)
+			r.Listener = l
+			continue
+		}
+		if err := startListener(l, srv); err != nil {
+			log.Printf("Could not start SMTP listener: %v", err)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mhale/mailrouter/smtpd"
+)
+
+// Write a self-signed certificate for localhost and its key to files, returning their paths.
+func writeTestCertificate(t *testing.T) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, _ := x509.MarshalECPrivateKey(key)
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600)
+	return certFile, keyFile
+}
+
+func TestListenerServer(t *testing.T) {
+	certFile, keyFile := writeTestCertificate(t)
+	tests := []struct {
+		l   Listener
+		err string
+	}{
+		{Listener{Name: "plain"}, ""},
+		{Listener{Name: "tls", TLSCert: certFile, TLSKey: keyFile, RequireTLS: true}, ""},
+		{Listener{Name: "missing", TLSCert: "/nonexistent/cert.pem", TLSKey: keyFile}, "listener missing: open /nonexistent/cert.pem: no such file or directory"},
+		{Listener{Name: "notls", RequireTLS: true}, "listener notls requires TLS but has no certificate"},
+		{Listener{Name: "nousers", RequireAuth: true}, "listener nousers requires authentication but has no users"},
+		{Listener{Name: "noproxies", ProxyProtocol: true}, "listener noproxies accepts client addresses from proxies but has no trusted proxies"},
+	}
+	for _, tt := range tests {
+		srv, err := tt.l.Server(ConfigSnapshot())
+		if (err == nil && tt.err != "") || (err != nil && err.Error() != tt.err) {
+			t.Errorf("Listener{%s}.Server() = %v, want %q", tt.l.Name, err, tt.err)
+			continue
+		}
+		if err == nil && srv.Name != tt.l.Name {
+			t.Errorf("Listener{%s}.Server() has name %q", tt.l.Name, srv.Name)
+		}
+	}
+}
+
+func TestListenerRecipientDomains(t *testing.T) {
+	useTestOptions(t, map[string]string{"RecipientDomains": "example.com"})
+	tests := []struct {
+		domains []string
+		want    string
+	}{
+		{nil, "example.com"},
+		{[]string{"staging.example.com", ".staging.example.com"}, "staging.example.com,.staging.example.com"},
+	}
+	for _, tt := range tests {
+		srv, err := Listener{Name: "test", Addr: ":2525", RecipientDomains: tt.domains}.Server(ConfigSnapshot())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Join(srv.RecipientDomains, ","); got != tt.want {
+			t.Errorf("recipient domains of a listener with %v = %s, want %s", tt.domains, got, tt.want)
+		}
+	}
+}
+
+func TestServerStartTLSAuth(t *testing.T) {
+	certFile, keyFile := writeTestCertificate(t)
+	l := Listener{Name: "production", Addr: "127.0.0.1:0", TLSCert: certFile, TLSKey: keyFile, RequireTLS: true, Users: map[string]string{"app": "secret"}, RequireAuth: true}
+	srv, err := l.Server(ConfigSnapshot())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var listener string
+	srv.Handler = func(origin net.Addr, env smtpd.Envelope, data []byte) error {
+		listener = env.Listener
+		return nil
+	}
+	srv.RcptHandler = nil
+	srv.Hostname = "mx.test"
+
+	ln, err := srv.Listen()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.Serve(ln)
+	t.Cleanup(func() { ln.Close() })
+
+	c, err := smtp.Dial(ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if ok, _ := c.Extension("AUTH"); ok {
+		t.Errorf("AUTH advertised before STARTTLS")
+	}
+	if err := c.Mail("sender@example.com"); replyCode(err) != 530 {
+		t.Errorf("MAIL before STARTTLS = %v, want 530", err)
+	}
+	if err := c.StartTLS(&tls.Config{InsecureSkipVerify: true}); err != nil {
+		t.Fatalf("StartTLS() = %v", err)
+	}
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		t.Errorf("STARTTLS advertised after TLS started")
+	}
+	if err := c.Mail("sender@example.com"); replyCode(err) != 530 {
+		t.Errorf("MAIL before AUTH = %v, want 530", err)
+	}
+	if err := c.Auth(smtp.PlainAuth("", "app", "secret", "127.0.0.1")); err != nil {
+		t.Fatalf("AUTH = %v", err)
+	}
+	if err := c.Mail("sender@example.com"); err != nil {
+		t.Fatalf("MAIL after AUTH = %v", err)
+	}
+	c.Rcpt("rcpt@example.com")
+	w, _ := c.Data()
+	w.Write([]byte("Subject: test\r\n\r\ntest\r\n"))
+	if err := w.Close(); err != nil {
+		t.Fatalf("DATA = %v", err)
+	}
+	if listener != "production" {
+		t.Errorf("Envelope.Listener = %q, want production", listener)
+	}
+}
+
+// Return the code of an SMTP error reply, or zero for other errors.
+func replyCode(err error) int {
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) {
+		return protoErr.Code
+	}
+	return 0
+}
+
+func TestListenerDefaultRoute(t *testing.T) {
+	useTestCapture(t)
+	useTestRoutes(t,
+		Route{Id: "DROP", Name: "Drop", IsDefault: true},
+		Route{Id: "staging", Name: "Staging", Type: RouteCapture},
+	)
+	saved := config.Listeners
+	config.Listeners = []Listener{
+		{Name: "staging", Addr: ":2525", DefaultRouteId: "staging"},
+		{Name: "production", Addr: ":2526"},
+		{Name: "deleted", Addr: ":2527", DefaultRouteId: "missing"},
+	}
+	t.Cleanup(func() { config.Listeners = saved })
+
+	tests := []struct {
+		listener string
+		want     string
+	}{
+		{"staging", "staging"},
+		{"production", "DROP"},
+		{"deleted", "DROP"},
+		{"unknown", "DROP"},
+	}
+	for _, tt := range tests {
+		if got := config.ListenerDefaultRouteId(tt.listener); got != tt.want {
+			t.Errorf("ListenerDefaultRouteId(%s) = %s, want %s", tt.listener, got, tt.want)
+		}
+	}
+
+	origin := &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 25}
+	env := smtpd.Envelope{From: "sender@example.com", To: []string{"rcpt@example.com"}, Listener: "staging"}
+	if err := mailHandler(origin, env, []byte("Subject: Hello\r\n\r\nHi.\r\n")); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(captured.Search("")); n != 1 {
+		t.Errorf("staging listener captured %d messages, want 1", n)
+	}
+	if l := logs.Logs[0]; l.Listener != "staging" || l.Route != "Staging" {
+		t.Errorf("log = %+v, want listener staging and route Staging", l)
+	}
+}
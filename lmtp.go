@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Default port for LMTP over TCP.
+const LMTPPort = 24
+
+// Timeout for connecting to an LMTP server.
+const LMTPDialTimeout = 30 * time.Second
+
+// Deliver a message over LMTP (RFC 2033) via TCP, or via a Unix socket if the route has a path.
+// LMTP reports a status for each recipient after the message data, so some recipients may
+// succeed while others fail.
+func deliverLMTP(route Route, msg Message) error {
+	network, addr := "tcp", net.JoinHostPort(route.Hostname, strconv.Itoa(route.Port))
+	if route.Port == 0 {
+		addr = net.JoinHostPort(route.Hostname, strconv.Itoa(LMTPPort))
+	}
+	if route.Path != "" {
+		network, addr = "unix", route.Path
+	}
+
+	err := sendLMTP(network, addr, msg)
+	if err != nil {
+		return fmt.Errorf("route %s (%s): %w", route.Name, addr, err)
+	}
+	return nil
+}
+
+func sendLMTP(network string, addr string, msg Message) error {
+	conn, err := net.DialTimeout(network, addr, LMTPDialTimeout)
+	if err != nil {
+		return err
+	}
+	text := textproto.NewConn(conn)
+	defer text.Close()
+
+	cmd := func(expectCode int, format string, args ...interface{}) error {
+		id, err := text.Cmd(format, args...)
+		if err != nil {
+			return err
+		}
+		text.StartResponse(id)
+		defer text.EndResponse(id)
+		_, _, err = text.ReadResponse(expectCode)
+		return err
+	}
+
+	if _, _, err = text.ReadResponse(220); err != nil {
+		return err
+	}
+	id, err := text.Cmd("LHLO %s", LocalHostname())
+	if err != nil {
+		return err
+	}
+	text.StartResponse(id)
+	_, ext, err := text.ReadResponse(250)
+	text.EndResponse(id)
+	if err != nil {
+		return err
+	}
+
+	// Pass on DSN parameters if the server supports them.
+	var dsn DSNParams
+	for _, line := range strings.Split(ext, "\n") {
+		if strings.EqualFold(strings.TrimSpace(line), "DSN") {
+			dsn = msg.DSN
+		}
+	}
+	if err = cmd(250, "MAIL FROM:<%s>%s", msg.From, dsn.MailParams()); err != nil {
+		return err
+	}
+
+	var accepted []string
+	var failures []RecipientError
+	for _, rcpt := range msg.To {
+		if err := cmd(25, "RCPT TO:<%s>%s", rcpt, dsn.RcptParams(rcpt)); err != nil {
+			if _, ok := err.(*textproto.Error); !ok {
+				return err
+			}
+			failures = append(failures, RecipientError{Recipient: rcpt, Err: err})
+			continue
+		}
+		accepted = append(accepted, rcpt)
+	}
+
+	if len(accepted) > 0 {
+		if err = cmd(354, "DATA"); err != nil {
+			return err
+		}
+		w := text.DotWriter()
+		if _, err = w.Write(msg.Data); err != nil {
+			return err
+		}
+		if err = w.Close(); err != nil {
+			return err
+		}
+		// One reply follows for each accepted recipient, in the order they were given.
+		for _, rcpt := range accepted {
+			if _, _, err := text.ReadResponse(25); err != nil {
+				if _, ok := err.(*textproto.Error); !ok {
+					return err
+				}
+				failures = append(failures, RecipientError{Recipient: rcpt, Err: err})
+				continue
+			}
+			dsn.Forward([]string{rcpt})
+		}
+	}
+	cmd(221, "QUIT")
+
+	return RecipientErrors(failures)
+}
+
+// The failure of delivery to a single recipient.
+type RecipientError struct {
+	Recipient string
+	Err       error
+}
+
+// Combine per-recipient failures into a single error, or nil if there were none.
+// The error is permanent only if every failure was permanent.
+func RecipientErrors(failures []RecipientError) error {
+	if len(failures) == 0 {
+		return nil
+	}
+	var msgs []string
+	permanent := true
+	for _, failure := range failures {
+		msgs = append(msgs, fmt.Sprintf("%s: %s", failure.Recipient, failure.Err))
+		if IsTemporary(failure.Err) {
+			permanent = false
+		}
+	}
+	return &DeliveryError{
+		Msg:       strings.Join(msgs, "; "),
+		Err:       failures[0].Err,
+		Permanent: permanent,
+		Failures:  failures,
+	}
+}
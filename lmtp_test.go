@@ -0,0 +1,58 @@
+package main
+
+import (
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDeliverLMTP(t *testing.T) {
+	tcp := newTestSMTPServer(t, "250 2.0.0 delivered")
+	tcp.lmtp = true
+	tcp.rcptReplies["unknown@example.com"] = "550 5.1.1 unknown user"
+	tcp.lmtpReplies["full@example.com"] = "452 4.2.2 mailbox full"
+	tcp.lmtpReplies["quota@example.com"] = "552 5.2.2 over quota"
+
+	unix := newTestServer(t, "unix", filepath.Join(t.TempDir(), "lmtp.sock"), "250 2.0.0 delivered")
+	unix.lmtp = true
+
+	addr := tcp.ln.Addr().(*net.TCPAddr)
+	tcpRoute := Route{Name: "tcp", Type: RouteLMTP, Hostname: addr.IP.String(), Port: addr.Port}
+	unixRoute := Route{Name: "unix", Type: RouteLMTP, Hostname: "ignored", Path: unix.ln.Addr().String()}
+
+	// Each recipient that failed is listed with whether it failed temporarily, so recipients
+	// that were delivered to are not sent the message again.
+	tests := []struct {
+		route  Route
+		to     []string
+		failed string
+	}{
+		{tcpRoute, []string{"a@example.com", "b@example.com"}, ""},
+		{unixRoute, []string{"a@example.com"}, ""},
+		{tcpRoute, []string{"a@example.com", "unknown@example.com"}, "unknown@example.com permanent"},
+		{tcpRoute, []string{"a@example.com", "full@example.com"}, "full@example.com temporary"},
+		{tcpRoute, []string{"quota@example.com", "full@example.com"}, "quota@example.com permanent, full@example.com temporary"},
+		{tcpRoute, []string{"quota@example.com", "unknown@example.com"}, "unknown@example.com permanent, quota@example.com permanent"},
+	}
+	data := []byte("Subject: test\r\n\r\ntest\r\n")
+	for _, tt := range tests {
+		_, err := Deliver(tt.route, Message{From: "sender@example.com", To: tt.to, Data: data})
+		var failed []string
+		if err != nil {
+			for _, failure := range recipientFailures(tt.to, err) {
+				kind := "permanent"
+				if IsTemporary(failure.Err) {
+					kind = "temporary"
+				}
+				failed = append(failed, failure.Recipient+" "+kind)
+			}
+		}
+		if got := strings.Join(failed, ", "); got != tt.failed {
+			t.Errorf("Deliver(%s, %v) failed %q, want %q", tt.route.Name, tt.to, got, tt.failed)
+		}
+	}
+	if n := unix.received(); n != 1 {
+		t.Errorf("Unix socket server received %d messages, want 1", n)
+	}
+}
@@ -0,0 +1,18 @@
+//go:build windows
+// +build windows
+
+package main
+
+import (
+	"os"
+)
+
+// Advisory file locking is not available on Windows. Appends from this process are
+// still serialised by mboxLock.
+func LockFile(f *os.File) error {
+	return nil
+}
+
+func UnlockFile(f *os.File) error {
+	return nil
+}
@@ -1,8 +1,6 @@
 package main
 
 import (
-	"net"
-	"strings"
 	"sync"
 	"time"
 )
@@ -10,35 +8,34 @@ import (
 const MaxLogs = 20
 
 type Log struct {
+	Id       int
 	Received string
 	From     string
 	To       string
 	Subject  string
 	Filter   string
 	Route    string
+	Member   string // Member route that delivered the message, for route groups
 	Status   string
 	Error    string
+	Original int    // Id of the quarantined log entry, for released messages
+	Listener string // Name of the listener that received the message
 }
 
 type LogList struct {
 	sync.RWMutex
-	Logs []Log
+	Logs   []Log
+	lastId int
 }
 
-func (ll *LogList) Add(origin net.IP, from string, to []string, subject string, filter string, route string, status string, error string) {
+// Add a log entry, setting its id and received time, and return its id.
+func (ll *LogList) AddLog(l Log) int {
 	ll.Lock()
 	defer ll.Unlock()
 
-	l := Log{
-		Received: time.Now().Format("2006-01-02 15:04:05"),
-		From:     from,
-		To:       strings.Join(to, ", "),
-		Subject:  subject,
-		Filter:   filter,
-		Route:    route,
-		Status:   status,
-		Error:    error,
-	}
+	ll.lastId++
+	l.Id = ll.lastId
+	l.Received = time.Now().Format("2006-01-02 15:04:05")
 
 	// Expand the log list out to the max size.
 	if len(ll.Logs) < MaxLogs {
@@ -48,4 +45,5 @@ func (ll *LogList) Add(origin net.IP, from string, to []string, subject string,
 	// Shuffle the existing log entries down one slot and put the new one in the first slot.
 	copy(ll.Logs[1:], ll.Logs[0:])
 	ll.Logs[0] = l
+	return l.Id
 }
@@ -2,18 +2,15 @@ package main
 
 import (
 	"fmt"
-	"net"
 	"testing"
 )
 
 func TestLogListAdd(t *testing.T) {
 	logs := LogList{}
-	ip := net.ParseIP("127.0.0.1")
-	to := []string{"To"}
 
 	// Test that log list grows to MaxLogs in size.
 	for i := 1; i <= MaxLogs; i++ {
-		logs.Add(ip, "From", to, fmt.Sprintf("%d", i), "Filter", "Route", "Status", "Error")
+		logs.AddLog(Log{From: "From", To: "To", Subject: fmt.Sprintf("%d", i), Filter: "Filter", Route: "Route", Status: "Status", Error: "Error"})
 		if len(logs.Logs) != i {
 			t.Errorf("LogList contains %v entries, want %v", len(logs.Logs), i)
 		}
@@ -26,9 +23,21 @@ func TestLogListAdd(t *testing.T) {
 
 	// Test that log list grows no further than MaxLogs in size.
 	for i := 1; i < MaxLogs; i++ {
-		logs.Add(ip, "From", to, fmt.Sprintf("%d", i), "Filter", "Route", "Status", "Error")
+		logs.AddLog(Log{From: "From", To: "To", Subject: fmt.Sprintf("%d", i), Filter: "Filter", Route: "Route", Status: "Status", Error: "Error"})
 		if len(logs.Logs) != MaxLogs {
 			t.Errorf("LogList contains %v entries, want %v", len(logs.Logs), MaxLogs)
 		}
 	}
 }
+
+func TestLogListIds(t *testing.T) {
+	logs := LogList{}
+	first := logs.AddLog(Log{From: "From", Subject: "Subject", Filter: "Filter", Route: "Route", Status: "Quarantined"})
+	second := logs.AddLog(Log{From: "From", Route: "Route", Status: "Sent", Original: first})
+	if first == second || logs.Logs[0].Id != second || logs.Logs[1].Id != first {
+		t.Errorf("log ids = %d, %d, want unique ids in newest first order", logs.Logs[0].Id, logs.Logs[1].Id)
+	}
+	if logs.Logs[0].Original != first || logs.Logs[0].Received == "" {
+		t.Errorf("log = %+v, want original %d and a received time", logs.Logs[0], first)
+	}
+}
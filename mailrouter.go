@@ -10,19 +10,25 @@ import (
 	"net"
 	"net/http"
 	"net/mail"
-	"net/smtp"
+	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
-	"github.com/mhale/smtpd"
 	"github.com/streadway/simpleuuid"
+
+	"github.com/mhale/mailrouter/smtpd"
 )
 
 var (
-	config Config  // Filters & routes
-	stats  Stats   // Statistics of sent and dropped mail
-	logs   LogList // Recent mail log for Dashboard
+	config     Config        // Filters & routes
+	stats      Stats         // Statistics of sent and dropped mail
+	logs       LogList       // Recent mail log for Dashboard
+	captured   CaptureStore  // Messages stored by capture routes
+	quarantine CaptureStore  // Messages held by quarantine filters
+	queue      DeliveryQueue // Messages waiting for rate limited routes
+	throttle   Throttle      // Limits on connections and mail from each client
 )
 
 var httpAddr *string = flag.String("http", ":8080", "Address & port for HTTP server")
@@ -30,74 +36,185 @@ var smtpAddr *string = flag.String("smtp", ":2525", "Address & port for SMTP ser
 var confFile *string = flag.String("conf", "/etc/mailrouter.conf", "Full path to configuration file")
 
 // Handler for handling incoming mail messages.
-func mailHandler(origin net.Addr, from string, to []string, data []byte) {
-	originIPStr, _, _ := net.SplitHostPort(origin.String())
-	originIP := net.ParseIP(originIPStr)
+// It runs before the reply to DATA is sent, so a returned error rejects the message.
+func mailHandler(origin net.Addr, env smtpd.Envelope, data []byte) error {
+	from, to := env.From, env.To
+	originIP := smtpd.OriginIP(origin)
+	// Use one copy of the configuration throughout, in case it is reloaded or edited meanwhile.
+	conf := ConfigSnapshot()
 
 	// Parse the message to get the Subject header.
 	msg, err := mail.ReadMessage(bytes.NewReader(data))
 	if err != nil {
 		log.Printf("Failed to parse message: %s\n", err)
 		log.Printf("Aborting processing of message from %s.", from)
-		return
+		return nil
 	}
 	subject := msg.Header.Get("Subject")
 
+	// Check SPF, DKIM and DMARC if enabled, and record the results in the message.
+	// Local clients have no IP address to check.
+	var authResults AuthResults
+	if conf.AuthEnabled() && originIP != nil {
+		authResults = Authenticate(originIP, from, data)
+		data = authResults.AddHeader(data)
+	}
+
 	// Check each filter in order.
 	var filterName string
 	var routeId string
-	for _, filter := range SortedFilters() {
-		if filter.Match(from, to, subject, originIP) {
-			filterName = filter.Name
-			routeId = filter.RouteId
-			break
+	var quarantinedBy string
+	for _, filter := range conf.SortedFilters() {
+		if !filter.Match(from, to, subject, origin, env.Listener, authResults) {
+			continue
+		}
+		if filter.Action == ActionQuarantine {
+			// Keep checking filters to find the route the message would be released to.
+			if quarantinedBy == "" {
+				quarantinedBy = filter.Name
+			}
+			continue
+		}
+		if filter.Action == ActionReject {
+			// A message already quarantined is held rather than rejected.
+			if quarantinedBy != "" {
+				continue
+			}
+			reply := filter.RejectReply()
+			logs.AddLog(Log{From: from, To: strings.Join(to, ", "), Subject: subject, Filter: filter.Name, Route: "Reject", Status: "Rejected", Error: reply.Error(), Listener: env.Listener})
+			return reply
 		}
+		filterName = filter.Name
+		routeId = filter.RouteId
+		break
 	}
 
-	// Use the default route if no filters were matched.
+	// Use the listener's default route if no filters were matched.
 	if routeId == "" {
-		for _, route := range config.Routes {
-			if route.IsDefault {
-				routeId = route.Id
-			}
+		routeId = conf.ListenerDefaultRouteId(env.Listener)
+	}
+
+	message := Message{From: from, To: to, Data: data, Subject: subject, Filter: filterName, Origin: originIP, Listener: env.Listener, Client: env.Client, DSN: DSNParams{DSNParams: env.DSN}}
+
+	// Hold quarantined messages until they are released or discarded.
+	if quarantinedBy != "" {
+		_, err := QuarantineMessage(message, routeId, quarantinedBy)
+		return err
+	}
+
+	RouteMessage(message, routeId, 0)
+	return nil
+}
+
+// Handler for checking each recipient as it is given, before the message data is received.
+// Filters are checked in order until one matches or one needs the message content to decide.
+// A matching reject filter refuses the recipient; any other match accepts it.
+func rcptHandler(origin net.Addr, env smtpd.Envelope, to string) error {
+	for _, filter := range ConfigSnapshot().SortedFilters() {
+		if !filter.EnvelopeOnly() {
+			return nil
 		}
+		if !filter.Match(env.From, []string{to}, "", origin, env.Listener, AuthResults{}) {
+			continue
+		}
+		if filter.Action != ActionReject {
+			return nil
+		}
+		reply := filter.RejectReply()
+		logs.AddLog(Log{From: env.From, To: to, Filter: filter.Name, Route: "Reject", Status: "Rejected", Error: reply.Error(), Listener: env.Listener})
+		return reply
 	}
+	return nil
+}
 
-	// If the message is to be dropped, record the drop and return.
-	if routeId == "DROP" {
-		stats.Dropped(len(data))
-		logs.Add(originIP, from, to, subject, filterName, "Drop", "", "")
+// Deliver a message via a route, or drop it, and record the outcome.
+// Mail for a rate limited route is queued until the route's limits allow it to be delivered.
+// Released messages pass the id of their quarantined log entry as original.
+func RouteMessage(message Message, routeId string, original int) {
+	// The route may have been removed by a reload since it was chosen, so use the default
+	// route instead.
+	config.RLock()
+	route, exists := config.Routes[routeId]
+	if !exists {
+		log.Printf("Route %s does not exist, using the default route for mail from %s.", routeId, message.From)
+		routeId = config.DefaultRouteId()
+		route, exists = config.Routes[routeId]
+	}
+	config.RUnlock()
+
+	// If the message is to be dropped, or there is no route to deliver it, record the drop and return.
+	if routeId == "DROP" || !exists {
+		stats.Dropped(len(message.Data))
+		entry := MessageLog(message, original)
+		entry.Route = "Drop"
+		logs.AddLog(entry)
 		return
 	}
 
-	// Otherwise, deliver the mail to the selected route and record the delivery.
-	route := config.Routes[routeId]
-	addr := route.Hostname + ":" + strconv.Itoa(route.Port)
+	// Otherwise, deliver the mail to the selected route.
+	if route.RateLimited() {
+		QueueMessage(route, message, original)
+		return
+	}
+	DeliverMessage(route, message, original)
+}
 
-	// Override the recipient if To field is set.
-	if route.To != "" {
-		to = []string{route.To}
+// Start a log entry for a message.
+func MessageLog(message Message, original int) Log {
+	return Log{
+		From:     message.From,
+		To:       strings.Join(message.To, ", "),
+		Subject:  message.Subject,
+		Filter:   message.Filter,
+		Original: original,
+		Listener: message.Listener,
 	}
+}
 
-	// Deliver the mail.
-	var auth smtp.Auth
-	auth = nil
-	if route.AuthType == "plain" {
-		auth = smtp.PlainAuth("", route.Username, route.Password, route.Hostname)
-	} else if route.AuthType == "crammd5" {
-		auth = smtp.CRAMMD5Auth(route.Username, route.Password)
+// Deliver a message via a route now, and record the delivery.
+func DeliverMessage(route Route, message Message, original int) {
+	entry := MessageLog(message, original)
+	entry.Route = route.Name
+
+	// Override the recipient if To field is set.
+	if route.To != "" {
+		entry.To = route.To
 	}
 
-	err = smtp.SendMail(addr, auth, from, to, data)
+	// Deliver the mail, noting which recipients' notifications were passed on to the next server.
+	message.DSN.Forwarded = map[string]bool{}
+	member, err := Deliver(route, message)
+	entry.Member = member
 	if err != nil {
-		msg := fmt.Sprintf("Failed to deliver mail to route %s (%s): %s", route.Name, addr, err)
+		// Recipients that failed temporarily are tried again later, and bounced if that goes on for too long.
+		retrying, err := RetryMessage(route, message, original, err)
+		msg := fmt.Sprintf("Failed to deliver mail to %s", err)
 		log.Printf(msg)
-		stats.Failed(len(data))
-		logs.Add(originIP, from, to, subject, filterName, route.Name, "Failed", msg)
-	} else {
-		stats.Sent(len(data))
-		logs.Add(originIP, from, to, subject, filterName, route.Name, "Sent", "")
+		entry.Error = msg
+		if retrying && len(FailedRecipients(message.To, err)) == 0 {
+			entry.Status = "Deferred"
+		} else {
+			stats.Failed(len(message.Data))
+			entry.Status = "Failed"
+		}
+		logs.AddLog(entry)
+		Notify(route, message, err)
+		return
+	}
+	stats.Sent(len(message.Data))
+	entry.Status = "Sent"
+	logs.AddLog(entry)
+	Notify(route, message, nil)
+}
+
+// Return the id of the default route.
+func (c *Config) DefaultRouteId() string {
+	for _, route := range c.Routes {
+		if route.IsDefault {
+			return route.Id
+		}
 	}
+	return ""
 }
 
 func routeHandler(w http.ResponseWriter, req *http.Request) {
@@ -110,10 +227,14 @@ func routeHandler(w http.ResponseWriter, req *http.Request) {
 		data["list"] = SortedRoutes()
 
 		// Populate the form if requested.
+		var edit Route
 		if id != "" && action == "edit" {
+			edit = config.Routes[id]
 			data["id"] = id
-			data["edit"] = config.Routes[id]
+			data["edit"] = edit
 		}
+		data["members"] = MemberCandidates(edit)
+		data["maxCaptured"] = MaxCaptured
 
 		// Check for info and error messages passed via cookies. Clear any that are displayed.
 		msg = GetCookie(w, req, "info")
@@ -151,6 +272,7 @@ func routeHandler(w http.ResponseWriter, req *http.Request) {
 				msg = fmt.Sprintf("%s The Drop route is now the default route.", msg)
 			}
 			delete(config.Routes, id)
+			RemoveMember(id)
 		}
 
 		if method == "default" {
@@ -167,7 +289,11 @@ func routeHandler(w http.ResponseWriter, req *http.Request) {
 		if method == "save" {
 			// Unset id means a new route is being added
 			if id == "" {
-				msg = fmt.Sprintf("Added route %s to host %s.", req.FormValue("routename"), req.FormValue("hostname"))
+				if req.FormValue("type") == RouteSMTP {
+					msg = fmt.Sprintf("Added route %s to host %s.", req.FormValue("routename"), req.FormValue("hostname"))
+				} else {
+					msg = fmt.Sprintf("Added route %s.", req.FormValue("routename"))
+				}
 				uuid, _ := simpleuuid.NewTime(time.Now())
 				id = uuid.String()
 			} else {
@@ -175,18 +301,56 @@ func routeHandler(w http.ResponseWriter, req *http.Request) {
 			}
 
 			// Create a new Route from the form submission.
+			headers, err := ParseHeaders(req.FormValue("headers"))
+			if err != nil {
+				msg = fmt.Sprintf("Failed to save route %s: %v", req.FormValue("routename"), err)
+				log.Printf(msg)
+				SetCookie(w, "error", msg)
+				http.Redirect(w, req, "/routes/", http.StatusFound)
+				return
+			}
 			port, _ := strconv.Atoi(req.FormValue("port"))
+			timeout, _ := strconv.Atoi(req.FormValue("timeout"))
 			isDefault, _ := strconv.ParseBool(req.FormValue("isdefault"))
+			suppressBounces, _ := strconv.ParseBool(req.FormValue("suppressbounces"))
+			msgsPerSecond, _ := strconv.ParseFloat(req.FormValue("msgspersecond"), 64)
+			msgsPerHour, _ := strconv.Atoi(req.FormValue("msgsperhour"))
+			bytesPerHour, _ := strconv.Atoi(req.FormValue("bytesperhour"))
+			reuseConnections, _ := strconv.ParseBool(req.FormValue("reuseconnections"))
+			maxConnections, _ := strconv.Atoi(req.FormValue("maxconnections"))
+			maxMessages, _ := strconv.Atoi(req.FormValue("maxmessages"))
+			xforward, _ := strconv.ParseBool(req.FormValue("xforward"))
 			route := Route{
-				Id:        id,
-				Name:      req.FormValue("routename"),
-				To:        req.FormValue("to"),
-				Hostname:  req.FormValue("hostname"),
-				Port:      port,
-				AuthType:  req.FormValue("authentication"),
-				Username:  req.FormValue("username"),
-				Password:  req.FormValue("password"),
-				IsDefault: isDefault,
+				Id:              id,
+				Name:            req.FormValue("routename"),
+				Type:            req.FormValue("type"),
+				Strategy:        req.FormValue("strategy"),
+				To:              req.FormValue("to"),
+				Hostname:        req.FormValue("hostname"),
+				Port:            port,
+				Path:            req.FormValue("path"),
+				Command:         req.FormValue("command"),
+				URL:             req.FormValue("url"),
+				Format:          req.FormValue("format"),
+				Headers:         headers,
+				Secret:          req.FormValue("secret"),
+				Timeout:         timeout,
+				AuthType:        req.FormValue("authentication"),
+				Username:        req.FormValue("username"),
+				Password:        req.FormValue("password"),
+				IsDefault:       isDefault,
+				SuppressBounces: suppressBounces,
+				MsgsPerSecond:   msgsPerSecond,
+				MsgsPerHour:     msgsPerHour,
+				BytesPerHour:    bytesPerHour,
+
+				ReuseConnections:         reuseConnections,
+				MaxConnections:           maxConnections,
+				MaxMessagesPerConnection: maxMessages,
+				XForward:                 xforward,
+			}
+			if route.IsGroup() {
+				route.Members = ParseMembers(req, id)
 			}
 			config.Routes[id] = route
 		}
@@ -213,8 +377,9 @@ func filterHandler(w http.ResponseWriter, req *http.Request) {
 
 	if req.Method == "GET" {
 		data := make(map[string]interface{})
-		data["list"] = SortedFilters()
+		data["list"] = config.SortedFilters()
 		data["routes"] = SortedRoutes()
+		data["listeners"] = ListenerNames()
 
 		if len(config.Routes) == 1 {
 			data["info"] = "No routes are defined. It is recommended to define routes before filters to populate the route drop-down menu below."
@@ -270,17 +435,41 @@ func filterHandler(w http.ResponseWriter, req *http.Request) {
 			// Create a new Filter from the form submission.
 			order, _ := strconv.Atoi(req.FormValue("order"))
 			filter := Filter{
-				Id:      id,
-				Order:   order,
-				Name:    req.FormValue("filtername"),
-				To:      req.FormValue("to"),
-				From:    req.FormValue("from"),
-				Origin:  req.FormValue("origin"),
-				Subject: req.FormValue("subject"),
-				RouteId: req.FormValue("route-id"),
+				Id:       id,
+				Order:    order,
+				Name:     req.FormValue("filtername"),
+				To:       req.FormValue("to"),
+				From:     req.FormValue("from"),
+				Origin:   req.FormValue("origin"),
+				Subject:  req.FormValue("subject"),
+				SPF:      req.FormValue("spf"),
+				DKIM:     req.FormValue("dkim"),
+				DMARC:    req.FormValue("dmarc"),
+				Listener: req.FormValue("listener"),
+				RouteId:  req.FormValue("route-id"),
+				Action:   req.FormValue("action"),
+				Reply:    req.FormValue("reply"),
 			}
 			filter.Summary = filter.Summarise()
 			filter.RouteName = config.Routes[filter.RouteId].Name
+			if filter.Action == ActionQuarantine {
+				filter.RouteId = ""
+				filter.RouteName = "Quarantine"
+			}
+			if filter.Action == ActionReject {
+				if filter.Reply == "" {
+					filter.Reply = DefaultRejectReply
+				}
+				if _, err := smtpd.ParseReply(filter.Reply, 550); err != nil {
+					msg = fmt.Sprintf("Failed to save filter %s: %v", filter.Name, err)
+					log.Printf(msg)
+					SetCookie(w, "error", msg)
+					http.Redirect(w, req, "/filters/", http.StatusFound)
+					return
+				}
+				filter.RouteId = ""
+				filter.RouteName = "Reject: " + filter.Reply
+			}
 			config.Filters[id] = filter
 		}
 
@@ -308,7 +497,8 @@ func indexHandler(w http.ResponseWriter, req *http.Request) {
 	}
 
 	data := make(map[string]interface{})
-	data["stats"] = stats
+	data["stats"] = &stats
+	data["throttled"] = throttle.Throttled(time.Now())
 	data["logs"] = logs.Logs
 	data["maxLogs"] = MaxLogs
 
@@ -323,6 +513,178 @@ func indexHandler(w http.ResponseWriter, req *http.Request) {
 	}
 }
 
+// Handler for browsing messages stored by capture routes.
+// Paths are /messages/ for the list, /messages/:id to view a message, /messages/:id/html for
+// its HTML body, /messages/:id/source for the raw message and /messages/:id/attachments/:n
+// to download an attachment.
+func messageHandler(w http.ResponseWriter, req *http.Request) {
+	_, idStr, action := ParsePath(req.URL.Path)
+	id, _ := strconv.Atoi(idStr)
+
+	// Requests for formats such as /messages/1.json come from the MailCatcher compatible API.
+	if strings.Contains(idStr, ".") || action == "parts" || req.Method == "DELETE" {
+		catcherMessageHandler(w, req)
+		return
+	}
+
+	if req.Method == "POST" {
+		method := req.FormValue("_method")
+		var msg string
+		if method == "clear" {
+			captured.Clear()
+			msg = "Deleted all captured messages."
+		}
+		if method == "delete" && captured.Delete(id) {
+			msg = fmt.Sprintf("Deleted message %d.", id)
+		}
+		if msg != "" {
+			log.Printf(msg)
+			SetCookie(w, "info", msg)
+		}
+		http.Redirect(w, req, "/messages/", http.StatusFound)
+		return
+	}
+
+	data := make(map[string]interface{})
+	if idStr != "" {
+		message, ok := captured.Get(id)
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		parsed, err := message.Parse()
+		if err != nil {
+			data["error"] = fmt.Sprintf("Could not parse message: %v", err)
+		}
+
+		switch action {
+		case "":
+			data["message"] = message
+			data["parsed"] = parsed
+		case "html":
+			// Sandbox the HTML body so scripts in captured mail cannot run or load remote content.
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Header().Set("Content-Security-Policy", SandboxPolicy)
+			w.Write([]byte(parsed.HTML))
+			return
+		case "source":
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			w.Write(message.Data)
+			return
+		case "attachments":
+			parts := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+			n := -1
+			if len(parts) == 4 {
+				n, _ = strconv.Atoi(parts[3])
+			}
+			if n < 0 || n >= len(parsed.Attachments) {
+				http.NotFound(w, req)
+				return
+			}
+			attachment := parsed.Attachments[n]
+			filename := attachment.Filename
+			if filename == "" {
+				filename = fmt.Sprintf("attachment-%d", n+1)
+			}
+			w.Header().Set("Content-Type", attachment.ContentType)
+			w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+			w.Header().Set("X-Content-Type-Options", "nosniff")
+			w.Write(attachment.Data)
+			return
+		default:
+			http.NotFound(w, req)
+			return
+		}
+	}
+
+	query := req.FormValue("q")
+	data["query"] = query
+	data["list"] = captured.Search(query)
+	data["maxCaptured"] = MaxCaptured
+
+	// Check for info and error messages passed via cookies. Clear any that are displayed.
+	if msg := GetCookie(w, req, "info"); msg != "" {
+		data["info"] = msg
+	}
+	if msg := GetCookie(w, req, "error"); msg != "" {
+		data["error"] = msg
+	}
+
+	html, _ := Asset("views/messages.html")
+	tmpl, err := template.New("messages").Parse(string(html))
+	if err != nil {
+		log.Println(err)
+	}
+	err = tmpl.Execute(w, data)
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+// Handler for the quarantine page, where held messages can be released or discarded.
+func quarantineHandler(w http.ResponseWriter, req *http.Request) {
+	_, idStr, action := ParsePath(req.URL.Path)
+	id, _ := strconv.Atoi(idStr)
+
+	if req.Method == "POST" {
+		var msg string
+		method := req.FormValue("_method")
+		if method == "release" {
+			m, err := ReleaseMessage(id, req.FormValue("route-id"))
+			if err != nil {
+				msg = fmt.Sprintf("Failed to release message: %v", err)
+				log.Printf(msg)
+				SetCookie(w, "error", msg)
+			} else {
+				msg = fmt.Sprintf("Released message from %s to route %s.", m.From, m.RouteName())
+				SetCookie(w, "info", msg)
+			}
+		}
+		if method == "discard" {
+			m, ok := quarantine.Get(id)
+			if ok && quarantine.Delete(id) {
+				msg = fmt.Sprintf("Discarded quarantined message from %s.", m.From)
+				log.Printf(msg)
+				SetCookie(w, "info", msg)
+			}
+		}
+		http.Redirect(w, req, "/quarantine/", http.StatusFound)
+		return
+	}
+
+	// Show the raw source of a held message.
+	if idStr != "" {
+		m, ok := quarantine.Get(id)
+		if !ok || action != "source" {
+			http.NotFound(w, req)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write(m.Data)
+		return
+	}
+
+	data := make(map[string]interface{})
+	data["list"] = quarantine.Search("")
+	data["routes"] = SortedRoutes()
+	if msg := GetCookie(w, req, "info"); msg != "" {
+		data["info"] = msg
+	}
+	if msg := GetCookie(w, req, "error"); msg != "" {
+		data["error"] = msg
+	}
+
+	html, _ := Asset("views/quarantine.html")
+	tmpl, err := template.New("quarantine").Parse(string(html))
+	if err != nil {
+		log.Println(err)
+	}
+	err = tmpl.Execute(w, data)
+	if err != nil {
+		log.Println(err)
+	}
+}
+
 // Handler for serving static assets (CSS/JS).
 func assetHandler(w http.ResponseWriter, req *http.Request) {
 	path := string(req.URL.Path[1:]) // Strip leading slash
@@ -340,7 +702,14 @@ func assetHandler(w http.ResponseWriter, req *http.Request) {
 }
 
 func main() {
+	// Run as sendmail when called as "mailrouter sendmail" or through a link named sendmail.
+	if filepath.Base(os.Args[0]) == "sendmail" {
+		os.Exit(SendmailMain(os.Args[1:]))
+	}
 	flag.Parse()
+	if flag.Arg(0) == "sendmail" {
+		os.Exit(SendmailMain(flag.Args()[1:]))
+	}
 
 	// Load filters & routes from configuration file.
 	err := LoadConfig()
@@ -368,13 +737,37 @@ func main() {
 	http.HandleFunc("/assets/", assetHandler)
 	http.HandleFunc("/routes/", routeHandler)
 	http.HandleFunc("/filters/", filterHandler)
+	http.HandleFunc("/messages/", messageHandler)
+	http.HandleFunc("/messages", catcherListHandler)
+	http.HandleFunc("/api/", hogHandler)
+	http.HandleFunc("/quarantine/", quarantineHandler)
 	go http.ListenAndServe(*httpAddr, nil)
 
-	// Run SMTP server in the foreground to force an exit if it fails.
-	log.Printf("Mailrouter serving SMTP on %s", *smtpAddr)
-	err = smtpd.ListenAndServe(*smtpAddr, mailHandler, "Mailrouter", "")
+	// Pick up mail left in the spool directory by sendmail mode while Mailrouter was not running.
+	if spool := config.Options["SpoolDirectory"]; spool != "" {
+		if err := CreateSpoolDirectory(spool); err != nil {
+			log.Printf("Could not create spool directory: %v", err)
+		} else {
+			go WatchSpool(spool)
+		}
+	}
+
+	// Apply the DNS server and client limits.
+	ApplyOptions()
+
+	// Run the SMTP listeners, exiting if any of them fails.
+	errs, err := StartListeners()
+	if err != nil {
+		log.Printf("Could not start SMTP listener: %v", err)
+		return
+	}
+
+	// Reload the configuration on SIGHUP, or when it changes if asked to.
+	go WatchConfig()
+
+	err = <-errs
 	if err != nil {
-		log.Printf("smtpd.ListenAndServe error: %v", err)
+		log.Printf("ListenAndServe error: %v", err)
 	}
 
 	log.Println("Exiting.")
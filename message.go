@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+)
+
+// Convert bare LF line endings to CRLF, leaving existing CRLF untouched.
+func NormalizeCRLF(data []byte) []byte {
+	data = bytes.Replace(data, []byte("\r\n"), []byte("\n"), -1)
+	return bytes.Replace(data, []byte("\n"), []byte("\r\n"), -1)
+}
+
+// Split a raw message into its header fields and body.
+// Each header field includes any folded continuation lines, but not the final CRLF.
+func SplitMessage(data []byte) ([]string, []byte) {
+	data = NormalizeCRLF(data)
+	var head []byte
+	var body []byte
+	if i := bytes.Index(data, []byte("\r\n\r\n")); i >= 0 {
+		head, body = data[:i], data[i+4:]
+	} else {
+		head = bytes.TrimSuffix(data, []byte("\r\n"))
+	}
+
+	var fields []string
+	for _, line := range strings.Split(string(head), "\r\n") {
+		if len(fields) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			fields[len(fields)-1] += "\r\n" + line
+			continue
+		}
+		fields = append(fields, line)
+	}
+	return fields, body
+}
+
+// The name of a raw header field.
+func HeaderName(field string) string {
+	if i := strings.Index(field, ":"); i >= 0 {
+		return strings.TrimSpace(field[:i])
+	}
+	return ""
+}
+
+// The raw value of a header field, including any folding.
+func HeaderValue(field string) string {
+	if i := strings.Index(field, ":"); i >= 0 {
+		return field[i+1:]
+	}
+	return ""
+}
+
+// Prepend a header field to a raw message.
+func PrependHeader(data []byte, name string, value string) []byte {
+	field := []byte(name + ": " + value + "\r\n")
+	return append(field, data...)
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+)
+
+// Maximum depth of nested multipart bodies that will be parsed.
+const MaxMIMEDepth = 10
+
+// A message decoded into its text and HTML bodies and attachments.
+type ParsedMessage struct {
+	Header      mail.Header
+	Text        string
+	HTML        string
+	Attachments []Attachment
+}
+
+// A file attached to a message, or an inline part that is neither the text nor HTML body.
+type Attachment struct {
+	Filename    string
+	ContentType string
+	ContentID   string
+	Data        []byte
+}
+
+var wordDecoder = mime.WordDecoder{CharsetReader: func(charset string, input io.Reader) (io.Reader, error) {
+	// Without conversion tables, pass other character sets through undecoded rather than failing.
+	return input, nil
+}}
+
+// Decode RFC 2047 encoded words in a header value, returning the value unchanged if it cannot be decoded.
+func DecodeHeader(value string) string {
+	decoded, err := wordDecoder.DecodeHeader(value)
+	if err != nil {
+		return value
+	}
+	return decoded
+}
+
+// Parse a message into its headers, first text/plain and text/html bodies, and attachments.
+func ParseMessage(data []byte) (ParsedMessage, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		return ParsedMessage{}, err
+	}
+	parsed := ParsedMessage{Header: msg.Header}
+	err = parsed.addPart(msg.Header, msg.Body, 0)
+	return parsed, err
+}
+
+// Either a mail.Header or a textproto.MIMEHeader.
+type partHeader interface {
+	Get(key string) string
+}
+
+func (p *ParsedMessage) addPart(header partHeader, body io.Reader, depth int) error {
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		mediaType, params = "text/plain", map[string]string{}
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") && depth < MaxMIMEDepth {
+		reader := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := reader.NextRawPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if err = p.addPart(part.Header, part, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+
+	content, err := io.ReadAll(decodeTransfer(header.Get("Content-Transfer-Encoding"), body))
+	if err != nil {
+		return err
+	}
+
+	disposition, dparams, _ := mime.ParseMediaType(header.Get("Content-Disposition"))
+	filename := dparams["filename"]
+	if filename == "" {
+		filename = params["name"]
+	}
+	if disposition != "attachment" && filename == "" {
+		if mediaType == "text/plain" && p.Text == "" {
+			p.Text = string(content)
+			return nil
+		}
+		if mediaType == "text/html" && p.HTML == "" {
+			p.HTML = string(content)
+			return nil
+		}
+	}
+
+	p.Attachments = append(p.Attachments, Attachment{
+		Filename:    DecodeHeader(filename),
+		ContentType: mediaType,
+		ContentID:   strings.Trim(header.Get("Content-ID"), "<>"),
+		Data:        content,
+	})
+	return nil
+}
+
+func decodeTransfer(encoding string, body io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, &base64Cleaner{r: body})
+	case "quoted-printable":
+		return quotedprintable.NewReader(body)
+	}
+	return body
+}
+
+// Strips the line breaks and other whitespace found in base64 encoded MIME parts.
+type base64Cleaner struct {
+	r io.Reader
+}
+
+func (c *base64Cleaner) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	j := 0
+	for _, b := range p[:n] {
+		if b != '\r' && b != '\n' && b != ' ' && b != '\t' {
+			p[j] = b
+			j++
+		}
+	}
+	return j, err
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+const testMultipart = "From: sender@example.com\r\n" +
+	"To: recipient@example.com\r\n" +
+	"Subject: =?UTF-8?B?UmVwb3J0IOKAkyBKdW5l?=\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/mixed; boundary=outer\r\n" +
+	"\r\n" +
+	"--outer\r\n" +
+	"Content-Type: multipart/alternative; boundary=inner\r\n" +
+	"\r\n" +
+	"--inner\r\n" +
+	"Content-Type: text/plain; charset=utf-8\r\n" +
+	"Content-Transfer-Encoding: quoted-printable\r\n" +
+	"\r\n" +
+	"Caf=C3=A9 report\r\n" +
+	"--inner\r\n" +
+	"Content-Type: text/html; charset=utf-8\r\n" +
+	"\r\n" +
+	"<p>Report</p>\r\n" +
+	"--inner--\r\n" +
+	"--outer\r\n" +
+	"Content-Type: application/pdf; name=\"report.pdf\"\r\n" +
+	"Content-Disposition: attachment; filename=\"report.pdf\"\r\n" +
+	"Content-Transfer-Encoding: base64\r\n" +
+	"\r\n" +
+	"JVBERi0x\r\n" +
+	"LjQK\r\n" +
+	"--outer--\r\n"
+
+func TestParseMessage(t *testing.T) {
+	parsed, err := ParseMessage([]byte(testMultipart))
+	if err != nil {
+		t.Fatalf("ParseMessage() = %v", err)
+	}
+	if subject := DecodeHeader(parsed.Header.Get("Subject")); subject != "Report – June" {
+		t.Errorf("Subject = %q, want %q", subject, "Report – June")
+	}
+	if parsed.Text != "Café report" {
+		t.Errorf("Text = %q, want %q", parsed.Text, "Café report")
+	}
+	if parsed.HTML != "<p>Report</p>" {
+		t.Errorf("HTML = %q, want %q", parsed.HTML, "<p>Report</p>")
+	}
+	if len(parsed.Attachments) != 1 {
+		t.Fatalf("Attachments = %d, want 1", len(parsed.Attachments))
+	}
+	a := parsed.Attachments[0]
+	if a.Filename != "report.pdf" || a.ContentType != "application/pdf" || string(a.Data) != "%PDF-1.4\n" {
+		t.Errorf("Attachment = %s %s %q, want report.pdf application/pdf %q", a.Filename, a.ContentType, a.Data, "%PDF-1.4\n")
+	}
+
+	// A message without a Content-Type is plain text.
+	parsed, err = ParseMessage([]byte("Subject: plain\r\n\r\nhello\r\n"))
+	if err != nil || strings.TrimSpace(parsed.Text) != "hello" {
+		t.Errorf("ParseMessage(plain) = %q, %v, want hello", parsed.Text, err)
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Default port for direct delivery to mail exchangers.
+const DirectPort = 25
+
+// Timeout for connecting to a mail exchanger.
+const DirectDialTimeout = 30 * time.Second
+
+// Deliver a message directly to the mail exchangers of each recipient domain.
+// Recipients are grouped by domain so each domain receives a single copy of the message.
+func deliverMX(route Route, msg Message) error {
+	port := route.Port
+	if port == 0 {
+		port = DirectPort
+	}
+
+	var domains []string
+	recipients := map[string][]string{}
+	for _, address := range msg.To {
+		domain := strings.ToLower(address[strings.LastIndex(address, "@")+1:])
+		if _, exists := recipients[domain]; !exists {
+			domains = append(domains, domain)
+		}
+		recipients[domain] = append(recipients[domain], address)
+	}
+
+	var failures []RecipientError
+	for _, domain := range domains {
+		domainMsg := msg
+		domainMsg.To = recipients[domain]
+		err := deliverDomain(domain, port, domainMsg)
+		if err != nil {
+			for _, rcpt := range recipients[domain] {
+				failures = append(failures, RecipientError{Recipient: rcpt, Err: err})
+			}
+		}
+	}
+	if err := RecipientErrors(failures); err != nil {
+		return fmt.Errorf("route %s: %w", route.Name, err)
+	}
+	return nil
+}
+
+// Deliver to one domain, trying its mail exchangers in preference order.
+func deliverDomain(domain string, port int, msg Message) error {
+	hosts, err := ResolveMX(domain)
+	if err != nil {
+		return fmt.Errorf("%s: %w", domain, err)
+	}
+
+	var lastErr error
+	missing := 0
+	for _, host := range hosts {
+		ips, err := resolver.LookupIP(host)
+		if err != nil {
+			if IsNotFound(err) {
+				missing++
+			}
+			lastErr = fmt.Errorf("%s: lookup of %s failed: %w", domain, host, err)
+			continue
+		}
+		for _, ip := range ips {
+			addr := net.JoinHostPort(ip.String(), strconv.Itoa(port))
+			err := sendDirect(addr, host, msg)
+			if err == nil {
+				return nil
+			}
+			lastErr = fmt.Errorf("%s: %s (%s): %w", domain, host, addr, err)
+			if !IsTemporary(err) {
+				return lastErr
+			}
+		}
+	}
+	// The domain, or every one of its mail exchangers, has no address records.
+	if missing == len(hosts) {
+		return fmt.Errorf("%s: %w", domain, &DeliveryError{Msg: "5.1.2 domain does not exist", Permanent: true})
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("%s: no addresses found for mail exchangers", domain)
+	}
+	return lastErr
+}
+
+// Find the hosts that accept mail for a domain, most preferred first.
+// A domain without MX records is its own mail exchanger (RFC 5321 section 5.1).
+func ResolveMX(domain string) ([]string, error) {
+	mxs, err := resolver.LookupMX(domain)
+	if err != nil && !IsNotFound(err) {
+		return nil, fmt.Errorf("MX lookup failed: %w", err)
+	}
+	if len(mxs) == 0 {
+		return []string{domain}, nil
+	}
+
+	sort.SliceStable(mxs, func(i, j int) bool {
+		return mxs[i].Pref < mxs[j].Pref
+	})
+	var hosts []string
+	for _, mx := range mxs {
+		host := strings.TrimSuffix(mx.Host, ".")
+		// A null MX record means the domain does not accept mail (RFC 7505).
+		if host == "" {
+			return nil, &DeliveryError{Msg: "domain does not accept mail (null MX)", Permanent: true}
+		}
+		hosts = append(hosts, host)
+	}
+	return hosts, nil
+}
+
+// Send a message to a mail exchanger, using STARTTLS when it is offered.
+func sendDirect(addr string, host string, msg Message) error {
+	conn, err := net.DialTimeout("tcp", addr, DirectDialTimeout)
+	if err != nil {
+		return err
+	}
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if err = c.Hello(LocalHostname()); err != nil {
+		return err
+	}
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		// Encryption between mail exchangers is opportunistic (RFC 7435), so certificates are not verified.
+		if err = c.StartTLS(&tls.Config{ServerName: host, InsecureSkipVerify: true}); err != nil {
+			return err
+		}
+	}
+	if err = sendMessage(c, msg, false); err != nil {
+		return err
+	}
+	return c.Quit()
+}
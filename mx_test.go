@@ -0,0 +1,78 @@
+package main
+
+import (
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestResolveMX(t *testing.T) {
+	tr := useTestResolver(t)
+	tr.mx["example.com"] = []*net.MX{{Host: "mx2.example.com.", Pref: 20}, {Host: "mx1.example.com.", Pref: 10}}
+	tr.mx["null.example.com"] = []*net.MX{{Host: ".", Pref: 0}}
+	tr.err["broken.example.com"] = &net.DNSError{Err: "server misbehaving", Name: "broken.example.com", IsTemporary: true}
+
+	tests := []struct {
+		domain string
+		out    string
+		fail   bool
+	}{
+		{"example.com", "mx1.example.com,mx2.example.com", false},
+		{"example.net", "example.net", false},
+		{"null.example.com", "", true},
+		{"broken.example.com", "", true},
+	}
+	for _, tt := range tests {
+		hosts, err := ResolveMX(tt.domain)
+		if x := strings.Join(hosts, ","); x != tt.out || (err != nil) != tt.fail {
+			t.Errorf("ResolveMX(%s) = %s, %v, want %s, failed %v", tt.domain, x, err, tt.out, tt.fail)
+		}
+	}
+}
+
+func TestDeliverMX(t *testing.T) {
+	server := newTestSMTPServer(t, "250 2.0.0 queued")
+	port := server.ln.Addr().(*net.TCPAddr).Port
+
+	tr := useTestResolver(t)
+	tr.mx["example.com"] = []*net.MX{{Host: "backup.example.com.", Pref: 20}, {Host: "down.example.com.", Pref: 10}}
+	tr.ip["down.example.com"] = []net.IP{net.ParseIP("127.0.0.2")}
+	tr.ip["backup.example.com"] = []net.IP{net.ParseIP("127.0.0.1")}
+	tr.ip["example.net"] = []net.IP{net.ParseIP("127.0.0.1")}
+	tr.mx["null.example.org"] = []*net.MX{{Host: ".", Pref: 0}}
+
+	route := Route{Id: "mx", Name: "mx", Type: RouteMX, Port: port}
+	data := []byte("Subject: test\r\n\r\ntest\r\n")
+	to := []string{"a@example.com", "b@EXAMPLE.com", "c@example.net"}
+	if _, err := Deliver(route, Message{From: "sender@example.com", To: to, Data: data}); err != nil {
+		t.Fatalf("Deliver() = %v, want success", err)
+	}
+
+	// One copy per domain, with the recipients of each domain in one transaction.
+	if n := server.received(); n != 2 {
+		t.Errorf("server received %d messages, want 2", n)
+	}
+	server.Lock()
+	rcpts := 0
+	for _, cmd := range server.commands {
+		if strings.HasPrefix(cmd, "RCPT TO:") {
+			rcpts++
+		}
+	}
+	server.Unlock()
+	if rcpts != 3 {
+		t.Errorf("server received %d RCPT commands, want 3", rcpts)
+	}
+
+	_, err := Deliver(route, Message{From: "sender@example.com", To: []string{"a@null.example.org"}, Data: data})
+	if err == nil || IsTemporary(err) {
+		t.Errorf("Deliver() to null MX domain = %v, want permanent failure", err)
+	}
+
+	// A domain with no MX records whose own name does not exist is not retried.
+	tr.err["nowhere.example.org"] = &net.DNSError{Err: "no such host", Name: "nowhere.example.org", IsNotFound: true}
+	_, err = Deliver(route, Message{From: "sender@example.com", To: []string{"a@nowhere.example.org"}, Data: data})
+	if err == nil || IsTemporary(err) || !strings.Contains(err.Error(), "5.1.2") {
+		t.Errorf("Deliver() to nonexistent domain = %v, want permanent 5.1.2 failure", err)
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Maximum time a pipe command may run before it is killed.
+const PipeTimeout = 5 * time.Minute
+
+// Exit code meaning a temporary failure, from sysexits.h.
+const ExTempFail = 75
+
+// Maximum length of command output included in error messages.
+const PipeMaxOutput = 200
+
+// Values available to templated pipe command arguments, e.g. {{.From}} or {{.Subject}}.
+type PipeArgs struct {
+	From       string
+	To         string // All recipients, comma separated
+	Recipients []string
+	Subject    string
+	Filter     string
+	Route      string
+}
+
+// Run the route's command with the message on its standard input.
+// The command is run directly rather than via a shell, so templated values cannot inject commands.
+// Exit code 75 (EX_TEMPFAIL) is a temporary failure, which is retried; any other non-zero exit code is permanent.
+func deliverPipe(route Route, msg Message) error {
+	args, err := PipeCommand(route, msg)
+	if err != nil {
+		return fmt.Errorf("route %s: %w", route.Name, &DeliveryError{Msg: err.Error(), Err: err, Permanent: true})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), PipeTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdin = bytes.NewReader(msg.Data)
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	err = cmd.Run()
+	if err == nil {
+		return nil
+	}
+
+	detail := strings.TrimSpace(output.String())
+	if len(detail) > PipeMaxOutput {
+		detail = detail[:PipeMaxOutput] + "..."
+	}
+	if detail != "" {
+		detail = ": " + detail
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && ctx.Err() == nil {
+		code := exitErr.ExitCode()
+		return fmt.Errorf("route %s (%s): %w", route.Name, args[0], &DeliveryError{
+			Msg:       fmt.Sprintf("command exited with status %d%s", code, detail),
+			Err:       err,
+			Permanent: code != ExTempFail,
+		})
+	}
+	if ctx.Err() != nil {
+		err = fmt.Errorf("command timed out after %s", PipeTimeout)
+	}
+	// The command could not be started or was killed, which may succeed later.
+	return fmt.Errorf("route %s (%s): %w", route.Name, args[0], &DeliveryError{Msg: err.Error() + detail, Err: err})
+}
+
+// Split the route's command line into arguments and expand the templates in each one.
+func PipeCommand(route Route, msg Message) ([]string, error) {
+	words, err := SplitCommand(route.Command)
+	if err != nil {
+		return nil, err
+	}
+	if len(words) == 0 {
+		return nil, fmt.Errorf("no command configured")
+	}
+
+	values := PipeArgs{
+		From:       msg.From,
+		To:         strings.Join(msg.To, ","),
+		Recipients: msg.To,
+		Subject:    msg.Subject,
+		Filter:     msg.Filter,
+		Route:      route.Name,
+	}
+	args := make([]string, len(words))
+	for i, word := range words {
+		tmpl, err := template.New("arg").Option("missingkey=error").Parse(word)
+		if err != nil {
+			return nil, fmt.Errorf("invalid argument %q: %v", word, err)
+		}
+		var buf bytes.Buffer
+		if err = tmpl.Execute(&buf, values); err != nil {
+			return nil, fmt.Errorf("invalid argument %q: %v", word, err)
+		}
+		args[i] = buf.String()
+	}
+	return args, nil
+}
+
+// Split a command line into words, honouring single quotes, double quotes and backslash escapes.
+func SplitCommand(line string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+	for _, r := range line {
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inWord = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, fmt.Errorf("unterminated quote or escape in command %q", line)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
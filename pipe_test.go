@@ -0,0 +1,171 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSplitCommand(t *testing.T) {
+	tests := []struct {
+		line string
+		out  []string
+		fail bool
+	}{
+		{"", nil, false},
+		{"/bin/cat", []string{"/bin/cat"}, false},
+		{"  process  -v\t--to x ", []string{"process", "-v", "--to", "x"}, false},
+		{`process "two words" 'single $quoted' back\ slash`, []string{"process", "two words", "single $quoted", "back slash"}, false},
+		{`process "" 'it''s'`, []string{"process", "", "its"}, false},
+		{`process "unterminated`, nil, true},
+	}
+	for _, tt := range tests {
+		out, err := SplitCommand(tt.line)
+		if !reflect.DeepEqual(out, tt.out) || (err != nil) != tt.fail {
+			t.Errorf("SplitCommand(%q) = %q, %v, want %q, failed %v", tt.line, out, err, tt.out, tt.fail)
+		}
+	}
+}
+
+func TestPipeCommand(t *testing.T) {
+	route := Route{Name: "Scripts", Command: `process --from {{.From}} --to "{{.To}}" --subject={{.Subject}} {{.Filter}} {{.Route}}`}
+	msg := Message{From: "sender@example.com", To: []string{"a@example.com", "b@example.com"}, Subject: "Hello; rm -rf /", Filter: "Test"}
+	want := []string{"process", "--from", "sender@example.com", "--to", "a@example.com,b@example.com", "--subject=Hello; rm -rf /", "Test", "Scripts"}
+	if args, err := PipeCommand(route, msg); !reflect.DeepEqual(args, want) || err != nil {
+		t.Errorf("PipeCommand() = %q, %v, want %q", args, err, want)
+	}
+
+	route.Command = "process {{.Missing}}"
+	if _, err := PipeCommand(route, msg); err == nil {
+		t.Errorf("PipeCommand() with unknown field succeeded, want error")
+	}
+}
+
+func TestDeliverPipe(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out")
+	data := []byte("Subject: test\r\n\r\ntest\r\n")
+	msg := Message{From: "sender@example.com", To: []string{"recipient@example.com"}, Data: data}
+
+	tests := []struct {
+		command   string
+		fail      bool
+		temporary bool
+	}{
+		{"sh -c 'cat > " + out + "'", false, false},
+		{"sh -c 'exit 75'", true, true},
+		{"sh -c 'echo no such user >&2; exit 67'", true, false},
+		{"/nonexistent/command", true, true},
+		{"", true, false},
+	}
+	for _, tt := range tests {
+		_, err := Deliver(Route{Name: "pipe", Type: RoutePipe, Command: tt.command}, msg)
+		if (err != nil) != tt.fail || (err != nil && IsTemporary(err) != tt.temporary) {
+			t.Errorf("Deliver(%s) = %v, want failed %v, temporary %v", tt.command, err, tt.fail, tt.temporary)
+		}
+		if err != nil && strings.Contains(tt.command, "no such user") && !strings.Contains(err.Error(), "no such user") {
+			t.Errorf("Deliver(%s) = %v, want command output in error", tt.command, err)
+		}
+	}
+
+	if content, _ := os.ReadFile(out); string(content) != string(data) {
+		t.Errorf("command received %q, want %q", content, data)
+	}
+}
+
+// Retry temporary failures quickly for the duration of a test.
+func useTestRetries(t *testing.T, interval time.Duration, maxTime time.Duration) {
+	savedInterval, savedMaxInterval, savedMaxTime := RetryInterval, MaxRetryInterval, MaxRetryTime
+	RetryInterval, MaxRetryInterval, MaxRetryTime = interval, 4*interval, maxTime
+	t.Cleanup(func() { RetryInterval, MaxRetryInterval, MaxRetryTime = savedInterval, savedMaxInterval, savedMaxTime })
+}
+
+// Wait up to 5 seconds for a condition to hold.
+func waitFor(condition func() bool) bool {
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	return condition()
+}
+
+// Return a copy of the log entries, newest first.
+func recentLogs() []Log {
+	logs.RLock()
+	defer logs.RUnlock()
+	return append([]Log(nil), logs.Logs...)
+}
+
+func TestRetryPipe(t *testing.T) {
+	dir := t.TempDir()
+	out, marker := filepath.Join(dir, "out"), filepath.Join(dir, "marker")
+	useTestCapture(t)
+	useTestRetries(t, 10*time.Millisecond, time.Hour)
+	useTestRoutes(t,
+		Route{Id: "DROP", Name: "Drop"},
+		Route{Id: "bounces", Name: "Bounces", Type: RouteCapture},
+		// Fails temporarily the first time it runs, then delivers.
+		Route{Id: "flaky", Name: "Flaky", Type: RoutePipe, Command: "sh -c 'if [ -e " + marker + " ]; then cat > " + out + "; else touch " + marker + "; exit 75; fi'"},
+		Route{Id: "busy", Name: "Busy", Type: RoutePipe, Command: "sh -c 'exit 75'"},
+	)
+	useTestOptions(t, map[string]string{"BounceRoute": "bounces"})
+	data := []byte("Subject: Hello\r\n\r\nHi.\r\n")
+
+	RouteMessage(Message{From: "sender@example.com", To: []string{"rcpt@example.com"}, Data: data, Subject: "Hello"}, "flaky", 0)
+	if !waitFor(func() bool { return recentLogs()[0].Status == "Sent" }) {
+		t.Fatalf("message was not delivered when the command was retried")
+	}
+	if content, _ := os.ReadFile(out); string(content) != string(data) {
+		t.Errorf("command received %q on retry, want %q", content, data)
+	}
+	if l := recentLogs()[1]; l.Route != "Flaky" || l.Status != "Deferred" {
+		t.Errorf("first attempt log = %+v, want Deferred", l)
+	}
+	if n := len(captured.Search("")); n != 0 {
+		t.Errorf("sent %d bounces for a message that was delivered on retry, want 0", n)
+	}
+
+	// A command that keeps failing temporarily is bounced once it has been retried for too long.
+	MaxRetryTime = 50 * time.Millisecond
+	RouteMessage(Message{From: "sender@example.com", To: []string{"rcpt@example.com"}, Data: data, Subject: "Hello"}, "busy", 0)
+	if !waitFor(func() bool { return len(captured.Search("")) > 0 }) {
+		t.Fatalf("no bounce sent for a message that kept failing temporarily")
+	}
+	if b := captured.Search("")[0]; b.Subject != BounceSubject || !strings.Contains(string(b.Data), "Status: 5.4.7") {
+		t.Errorf("bounce %q = %s, want delivery time expired", b.Subject, b.Data)
+	}
+}
+
+func TestRetryDelayWarning(t *testing.T) {
+	useTestCapture(t)
+	useTestRetries(t, 10*time.Millisecond, DelayWarning+200*time.Millisecond)
+	useTestRoutes(t,
+		Route{Id: "DROP", Name: "Drop"},
+		Route{Id: "bounces", Name: "Bounces", Type: RouteCapture},
+		Route{Id: "busy", Name: "Busy", Type: RoutePipe, Command: "sh -c 'exit 75'"},
+	)
+	useTestOptions(t, map[string]string{"BounceRoute": "bounces"})
+
+	// A message that has been retried for DelayWarning warns its sender once, then bounces.
+	deferred := time.Now().Add(-DelayWarning)
+	RouteMessage(Message{From: "sender@example.com", To: []string{"rcpt@example.com"}, Data: []byte("Subject: Hello\r\n\r\nHi.\r\n"), Subject: "Hello", Deferred: deferred}, "busy", 0)
+	subjects := func() map[string]int {
+		count := map[string]int{}
+		for _, m := range captured.Search("") {
+			count[m.Subject]++
+		}
+		return count
+	}
+	if !waitFor(func() bool { return subjects()[BounceSubject] > 0 }) {
+		t.Fatalf("no bounce sent for a message that kept failing temporarily")
+	}
+	if got := subjects(); got[DelaySubject] != 1 {
+		t.Errorf("sent %d delay warnings while retrying, want 1", got[DelaySubject])
+	}
+	warning := captured.Search(DelaySubject)
+	if len(warning) != 1 || !strings.Contains(string(warning[0].Data), "Action: delayed") {
+		t.Errorf("delay warning = %+v, want a delayed DSN", warning)
+	}
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"fmt"
+	"net/smtp"
+	"sync"
+	"time"
+)
+
+// Time an unused pooled connection is kept open. Servers commonly close idle connections
+// after a few minutes (RFC 5321 section 4.5.3.2.7), so close ours well before that.
+const PoolIdleTimeout = 30 * time.Second
+
+// A connection held by a pool, with the number of messages sent over it.
+type pooledClient struct {
+	*smtp.Client
+	sent int
+	idle *time.Timer // Closes the connection if it is unused for too long
+}
+
+// The SMTP connections to a route's server.
+type connPool struct {
+	sync.Mutex
+	cond     *sync.Cond
+	settings string // Route settings the connections were made with
+	max      int    // Maximum connections open at once. Zero is unlimited.
+	open     int    // Connections open, whether idle or in use
+	idle     []*pooledClient
+	closed   bool // Set when the route's settings change, so connections are not reused
+}
+
+func newConnPool(settings string, max int) *connPool {
+	p := &connPool{settings: settings, max: max}
+	p.cond = sync.NewCond(&p.Mutex)
+	return p
+}
+
+// Take an idle connection, resetting it for a new transaction, or open a new connection if the
+// pool is not full. If it is full, wait for a connection to be returned.
+func (p *connPool) acquire(dial func() (*smtp.Client, error)) (*pooledClient, error) {
+	p.Lock()
+	for {
+		if n := len(p.idle); n > 0 {
+			c := p.idle[n-1]
+			p.idle = p.idle[:n-1]
+			if !c.idle.Stop() {
+				// The idle timer has fired and is closing the connection.
+				continue
+			}
+			p.Unlock()
+			if err := c.Reset(); err == nil {
+				return c, nil
+			}
+			c.Close()
+			p.free()
+			p.Lock()
+			continue
+		}
+		if p.max == 0 || p.open < p.max {
+			p.open++
+			p.Unlock()
+			client, err := dial()
+			if err != nil {
+				p.free()
+				return nil, err
+			}
+			return &pooledClient{Client: client}, nil
+		}
+		p.cond.Wait()
+	}
+}
+
+// Give up a connection slot after a connection is closed, waking a sender waiting for one.
+func (p *connPool) free() {
+	p.Lock()
+	p.open--
+	p.cond.Signal()
+	p.Unlock()
+}
+
+// Return a connection to the pool after use, or close it if it should not be reused.
+func (p *connPool) release(c *pooledClient, reuse bool) {
+	p.Lock()
+	if !reuse || p.closed {
+		p.Unlock()
+		c.Quit()
+		c.Close()
+		p.free()
+		return
+	}
+	c.idle = time.AfterFunc(PoolIdleTimeout, func() {
+		p.Lock()
+		for i, idle := range p.idle {
+			if idle == c {
+				p.idle = append(p.idle[:i], p.idle[i+1:]...)
+				break
+			}
+		}
+		p.Unlock()
+		c.Quit()
+		c.Close()
+		p.free()
+	})
+	p.idle = append(p.idle, c)
+	p.cond.Signal()
+	p.Unlock()
+}
+
+// Close the idle connections of a pool that is no longer used. Connections in use are closed when they are returned.
+func (p *connPool) close() {
+	p.Lock()
+	p.closed = true
+	idle := p.idle
+	p.idle = nil
+	p.Unlock()
+	for _, c := range idle {
+		if c.idle.Stop() {
+			c.Quit()
+			c.Close()
+			p.free()
+		}
+	}
+}
+
+// Connection pools for all routes that use them, keyed by route ID.
+type ConnPools struct {
+	sync.Mutex
+	pools map[string]*connPool
+}
+
+var connPools = ConnPools{pools: map[string]*connPool{}}
+
+// Return the pool for a route, replacing it if the route's server or limits have changed.
+func (cp *ConnPools) pool(route Route) *connPool {
+	cp.Lock()
+	defer cp.Unlock()
+	settings := fmt.Sprintf("%s:%d %s %s %s %d", route.Hostname, route.Port, route.AuthType, route.Username, route.Password, route.MaxConnections)
+	p, exists := cp.pools[route.Id]
+	if exists && p.settings == settings {
+		return p
+	}
+	if exists {
+		p.close()
+	}
+	p = newConnPool(settings, route.MaxConnections)
+	cp.pools[route.Id] = p
+	return p
+}
+
+// Send a message via a pooled connection to a route's SMTP server. The connection is kept
+// open for the next message if the route reuses connections and the message was accepted.
+func (cp *ConnPools) Send(route Route, addr string, auth smtp.Auth, msg Message) error {
+	p := cp.pool(route)
+	c, err := p.acquire(func() (*smtp.Client, error) {
+		return dialSMTP(addr, route.Hostname, auth)
+	})
+	if err != nil {
+		return err
+	}
+	err = sendMessage(c.Client, msg, route.XForward)
+	if err == nil {
+		c.sent++
+	}
+	reuse := err == nil && route.ReuseConnections && (route.MaxMessagesPerConnection == 0 || c.sent < route.MaxMessagesPerConnection)
+	p.release(c, reuse)
+	return err
+}
+
+// The state of a route's connection pool for the route listing.
+type PoolStatus struct {
+	Open int
+	Idle int
+}
+
+// Return the number of connections open to the route's server and how many of them are idle.
+func (r Route) PoolStatus() PoolStatus {
+	connPools.Lock()
+	p, exists := connPools.pools[r.Id]
+	connPools.Unlock()
+	if !exists {
+		return PoolStatus{}
+	}
+	p.Lock()
+	defer p.Unlock()
+	return PoolStatus{Open: p.open, Idle: len(p.idle)}
+}
+
+// Report whether deliveries via the route share a pool of connections, either to reuse them
+// or to limit how many are open at once.
+func (r Route) Pooled() bool {
+	return (r.Type == RouteSMTP || r.Type == "") && (r.ReuseConnections || r.MaxConnections > 0)
+}
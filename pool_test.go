@@ -0,0 +1,73 @@
+package main
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestConnPoolReuse(t *testing.T) {
+	server := newTestSMTPServer(t, "250 2.0.0 queued")
+	route := server.route("pooled")
+	route.ReuseConnections = true
+	route.MaxMessagesPerConnection = 2
+
+	msg := Message{From: "sender@example.com", To: []string{"a@example.com"}, Data: []byte("Subject: test\r\n\r\ntest\r\n")}
+	for i := 0; i < 5; i++ {
+		if _, err := Deliver(route, msg); err != nil {
+			t.Fatalf("Deliver() #%d = %v", i, err)
+		}
+	}
+	server.Lock()
+	conns, commands := server.conns, strings.Join(server.commands, "\n")
+	server.Unlock()
+	if conns != 3 {
+		t.Errorf("5 messages at 2 per connection used %d connections, want 3", conns)
+	}
+	if n := strings.Count(commands, "RSET"); n != 2 {
+		t.Errorf("sent RSET %d times, want 2", n)
+	}
+	if status := route.PoolStatus(); status.Open != 1 || status.Idle != 1 {
+		t.Errorf("PoolStatus() = %+v, want 1 open and idle", status)
+	}
+
+	// Changing the route's server settings closes the idle connection.
+	route.MaxConnections = 3
+	if _, err := Deliver(route, msg); err != nil {
+		t.Fatalf("Deliver() after changing settings = %v", err)
+	}
+	if status := route.PoolStatus(); status.Open != 1 || status.Idle != 1 {
+		t.Errorf("PoolStatus() after changing settings = %+v, want 1 open and idle", status)
+	}
+}
+
+func TestConnPoolLimit(t *testing.T) {
+	server := newTestSMTPServer(t, "250 2.0.0 queued")
+	route := server.route("limited")
+	route.MaxConnections = 2
+
+	msg := Message{From: "sender@example.com", To: []string{"a@example.com"}, Data: []byte("Subject: test\r\n\r\ntest\r\n")}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := Deliver(route, msg); err != nil {
+				t.Errorf("Deliver() = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	server.Lock()
+	defer server.Unlock()
+	if server.maxActive > 2 {
+		t.Errorf("%d connections were open at once, want at most 2", server.maxActive)
+	}
+	if server.conns != 8 || len(server.messages) != 8 {
+		t.Errorf("%d connections delivered %d messages, want 8 of each without reuse", server.conns, len(server.messages))
+	}
+	if status := route.PoolStatus(); status.Open != 0 {
+		t.Errorf("PoolStatus() = %+v, want no connections open", status)
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/mhale/mailrouter/smtpd"
+)
+
+// Hold a message in quarantine instead of delivering it, recording the filter that quarantined it.
+// The message can later be released to routeId, the route that would otherwise have applied.
+// Held messages are never discarded to make room, so if the quarantine already holds
+// MaxQuarantined messages the new one is refused with a temporary error.
+func QuarantineMessage(message Message, routeId string, reason string) (CapturedMessage, error) {
+	entry := MessageLog(message, 0)
+	entry.Filter = reason
+	entry.Route = "Quarantine"
+	entry.Status = "Quarantined"
+
+	quarantine.Lock()
+	defer quarantine.Unlock()
+	if max := OptionInt("MaxQuarantined"); max > 0 && len(quarantine.Messages) >= max {
+		err := &smtpd.SMTPError{Code: 452, Message: "4.3.1 Quarantine is full"}
+		log.Printf("Refused message from %s for quarantine by filter %s: %d messages are already held.", message.From, reason, max)
+		entry.Status, entry.Error = "Rejected", err.Error()
+		logs.AddLog(entry)
+		return CapturedMessage{}, err
+	}
+	return quarantine.add(CapturedMessage{
+		From:     message.From,
+		To:       append([]string(nil), message.To...),
+		Subject:  DecodeHeader(message.Subject),
+		Filter:   reason,
+		Origin:   message.Origin,
+		Data:     append([]byte(nil), message.Data...),
+		RouteId:  routeId,
+		LogId:    logs.AddLog(entry),
+		DSN:      message.DSN,
+		Listener: message.Listener,
+		Client:   message.Client,
+	}), nil
+}
+
+// Release a quarantined message for delivery. An empty routeId releases the message to the
+// route that would have applied had it not been quarantined, or the default route if that
+// route no longer exists.
+func ReleaseMessage(id int, routeId string) (CapturedMessage, error) {
+	m, ok := quarantine.Get(id)
+	if !ok {
+		return m, fmt.Errorf("message %d is not in quarantine", id)
+	}
+	if routeId == "" {
+		routeId = m.RouteId
+	}
+	conf := ConfigSnapshot()
+	if _, ok := conf.Routes[routeId]; !ok {
+		if routeId != m.RouteId {
+			return m, fmt.Errorf("route %s does not exist", routeId)
+		}
+		routeId = conf.DefaultRouteId()
+	}
+	// Remove the message first so it cannot be released twice.
+	if !quarantine.Delete(id) {
+		return m, fmt.Errorf("message %d is not in quarantine", id)
+	}
+
+	log.Printf("Releasing quarantined message %d from %s to route %s.", id, m.From, conf.Routes[routeId].Name)
+	message := Message{From: m.From, To: m.To, Data: m.Data, Subject: m.Subject, Filter: m.Filter, Origin: m.Origin, Listener: m.Listener, Client: m.Client, DSN: m.DSN}
+	RouteMessage(message, routeId, m.LogId)
+	m.RouteId = routeId
+	return m, nil
+}
+
+// The name of the route a quarantined message is released to by default.
+func (m CapturedMessage) RouteName() string {
+	config.RLock()
+	defer config.RUnlock()
+	if route, ok := config.Routes[m.RouteId]; ok {
+		return route.Name
+	}
+	return config.Routes[config.DefaultRouteId()].Name
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"net"
+	"testing"
+
+	"github.com/mhale/mailrouter/smtpd"
+)
+
+func TestQuarantine(t *testing.T) {
+	useTestCapture(t)
+	useTestRoutes(t,
+		Route{Id: "DROP", Name: "Drop"},
+		Route{Id: "inbox", Name: "Inbox", Type: RouteCapture, IsDefault: true},
+		Route{Id: "review", Name: "Review", Type: RouteCapture},
+	)
+	savedFilters := config.Filters
+	config.Filters = map[string]Filter{
+		"hold":    {Id: "hold", Order: 100, Name: "Suspicious", Subject: "invoice", Action: ActionQuarantine},
+		"reviews": {Id: "reviews", Order: 200, Name: "Reviews", To: "review@", RouteId: "review"},
+	}
+	t.Cleanup(func() { config.Filters = savedFilters })
+	savedQuarantine := quarantine.Messages
+	quarantine.Messages = nil
+	t.Cleanup(func() { quarantine.Messages = savedQuarantine })
+
+	origin := &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 25}
+	data := []byte("Subject: Your invoice\r\n\r\nPay now.\r\n")
+	client := smtpd.ClientInfo{Name: "app.example.com", Helo: "app", Proto: "ESMTP"}
+	mailHandler(origin, smtpd.Envelope{From: "sender@example.com", To: []string{"review@example.com"}, Client: client}, data)
+	mailHandler(origin, smtpd.Envelope{From: "sender@example.com", To: []string{"someone@example.com"}}, data)
+	mailHandler(origin, smtpd.Envelope{From: "sender@example.com", To: []string{"someone@example.com"}}, []byte("Subject: Hello\r\n\r\nHi.\r\n"))
+
+	held := quarantine.Search("")
+	if len(held) != 2 || len(captured.Search("")) != 1 {
+		t.Fatalf("%d messages held and %d delivered, want 2 held and 1 delivered", len(held), len(captured.Search("")))
+	}
+	toDefault, toReview := held[0], held[1]
+	if toReview.Filter != "Suspicious" || toReview.RouteId != "review" || toDefault.RouteId != "inbox" {
+		t.Errorf("held messages = %s via %s, %s, want Suspicious via review, inbox", toReview.Filter, toReview.RouteId, toDefault.RouteId)
+	}
+	if logs.Logs[2].Id != toReview.LogId || logs.Logs[2].Status != "Quarantined" {
+		t.Errorf("quarantine log = %+v, want id %d and status Quarantined", logs.Logs[2], toReview.LogId)
+	}
+
+	// Release to the route that would otherwise have applied.
+	if _, err := ReleaseMessage(toReview.Id, ""); err != nil {
+		t.Fatalf("ReleaseMessage() = %v", err)
+	}
+	if delivered := captured.Search(""); len(delivered) != 2 || delivered[0].Route != "Review" {
+		t.Errorf("released message delivered to %v, want Review", delivered)
+	} else if delivered[0].Client != client {
+		t.Errorf("released message client = %+v, want %+v", delivered[0].Client, client)
+	}
+	if l := logs.Logs[0]; l.Original != toReview.LogId || l.Status != "Sent" || l.Route != "Review" {
+		t.Errorf("release log = %+v, want Sent via Review linked to log %d", l, toReview.LogId)
+	}
+	if _, err := ReleaseMessage(toReview.Id, ""); err == nil {
+		t.Errorf("ReleaseMessage() twice succeeded, want error")
+	}
+
+	// Release to a chosen route, which must exist.
+	if _, err := ReleaseMessage(toDefault.Id, "missing"); err == nil {
+		t.Errorf("ReleaseMessage() to missing route succeeded, want error")
+	}
+	if _, err := ReleaseMessage(toDefault.Id, "DROP"); err != nil {
+		t.Errorf("ReleaseMessage() to Drop = %v", err)
+	}
+	if l := logs.Logs[0]; l.Original != toDefault.LogId || l.Route != "Drop" {
+		t.Errorf("release log = %+v, want Drop linked to log %d", l, toDefault.LogId)
+	}
+	if len(quarantine.Search("")) != 0 {
+		t.Errorf("quarantine holds %d messages after release, want 0", len(quarantine.Search("")))
+	}
+}
+
+func TestQuarantineFull(t *testing.T) {
+	useTestCapture(t)
+	useTestRoutes(t, Route{Id: "DROP", Name: "Drop", IsDefault: true})
+	useTestOptions(t, map[string]string{"MaxQuarantined": "1"})
+	savedFilters := config.Filters
+	config.Filters = map[string]Filter{"hold": {Id: "hold", Order: 100, Name: "Hold", To: "@example.com", Action: ActionQuarantine}}
+	t.Cleanup(func() { config.Filters = savedFilters })
+	savedQuarantine := quarantine.Messages
+	quarantine.Messages = nil
+	t.Cleanup(func() { quarantine.Messages = savedQuarantine })
+
+	origin := &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 25}
+	for i, want := range []int{0, 452} {
+		err := mailHandler(origin, smtpd.Envelope{From: "sender@example.com", To: []string{"user@example.com"}}, []byte("Subject: Hold\r\n\r\nHi.\r\n"))
+		code := 0
+		if smtpErr, ok := err.(*smtpd.SMTPError); ok {
+			code = smtpErr.Code
+		}
+		if code != want || (err != nil && code == 0) {
+			t.Errorf("message %d to a quarantine holding %d = %v, want %d", i+1, i, err, want)
+		}
+	}
+	if held := quarantine.Search(""); len(held) != 1 || held[0].LogId != logs.Logs[1].Id {
+		t.Errorf("quarantine holds %+v, want the first message kept", held)
+	}
+	if l := logs.Logs[0]; l.Route != "Quarantine" || l.Status != "Rejected" {
+		t.Errorf("log for a full quarantine = %+v, want Rejected", l)
+	}
+}
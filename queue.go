@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// Longest time a queued message sleeps before checking its route's limits again,
+// so changes to the limits take effect promptly.
+const QueuePoll = time.Second
+
+// Time a message can wait in the queue before its sender is warned of the delay.
+const DelayWarning = 4 * time.Hour
+
+// A message waiting to be delivered via a rate limited route.
+type QueuedMessage struct {
+	Message  Message
+	RouteId  string
+	Original int // Log entry of the quarantined message, if it was released
+	Queued   time.Time
+	warned   bool
+}
+
+// Messages waiting for rate limited routes, keyed by route ID, oldest first.
+// Each route with waiting messages has a goroutine delivering them in order.
+type DeliveryQueue struct {
+	sync.Mutex
+	queues map[string][]*QueuedMessage
+}
+
+// Add a message to the queue for its route, starting delivery if the route's queue was empty.
+func (q *DeliveryQueue) Add(message Message, routeId string, original int) {
+	q.Lock()
+	defer q.Unlock()
+	if q.queues == nil {
+		q.queues = map[string][]*QueuedMessage{}
+	}
+	pending, running := q.queues[routeId]
+	q.queues[routeId] = append(pending, &QueuedMessage{Message: message, RouteId: routeId, Original: original, Queued: time.Now()})
+	if !running {
+		go q.run(routeId)
+	}
+}
+
+// Return the number of messages waiting for a route.
+func (q *DeliveryQueue) Len(routeId string) int {
+	q.Lock()
+	defer q.Unlock()
+	return len(q.queues[routeId])
+}
+
+// Return the oldest message waiting for a route, removing it if remove is set.
+// When no messages are left the route's queue is removed, which stops its goroutine.
+func (q *DeliveryQueue) next(routeId string, remove bool) *QueuedMessage {
+	q.Lock()
+	defer q.Unlock()
+	pending := q.queues[routeId]
+	if len(pending) == 0 {
+		delete(q.queues, routeId)
+		return nil
+	}
+	if remove {
+		q.queues[routeId] = pending[1:]
+	}
+	return pending[0]
+}
+
+// Deliver the messages waiting for a route in order, as fast as its rate limits allow.
+func (q *DeliveryQueue) run(routeId string) {
+	for {
+		m := q.next(routeId, false)
+		if m == nil {
+			return
+		}
+		config.RLock()
+		route, exists := config.Routes[routeId]
+		config.RUnlock()
+		if !exists {
+			// The route was deleted while the message waited, so RouteMessage uses the default
+			// route instead.
+			q.next(routeId, true)
+			RouteMessage(m.Message, routeId, m.Original)
+			continue
+		}
+
+		wait := rateLimits.Reserve(route, len(m.Message.Data), time.Now())
+		if wait == 0 {
+			q.next(routeId, true)
+			DeliverMessage(route, m.Message, m.Original)
+			continue
+		}
+		if !m.warned && time.Since(m.Queued) >= DelayWarning {
+			m.warned = true
+			WarnDelay(route, m.Message, fmt.Sprintf("waiting for the rate limits of route %s", route.Name))
+		}
+		if wait > QueuePoll {
+			wait = QueuePoll
+		}
+		time.Sleep(wait)
+	}
+}
+
+// Queue a message for a rate limited route.
+func QueueMessage(route Route, message Message, original int) {
+	log.Printf("Queued mail from %s for rate limited route %s.", message.From, route.Name)
+	queue.Add(message, route.Id, original)
+}
+
+// Return the number of messages waiting to be delivered via the route.
+func (r Route) Queued() int {
+	return queue.Len(r.Id)
+}
+
+// Mail that fails temporarily is tried again after RetryInterval, with the wait doubling after
+// each attempt up to MaxRetryInterval. Recipients still failing after MaxRetryTime are bounced.
+// These are variables so tests can shorten them.
+var (
+	RetryInterval    = 5 * time.Minute
+	MaxRetryInterval = time.Hour
+	MaxRetryTime     = 5 * 24 * time.Hour
+)
+
+// Arrange for the recipients of a message that failed temporarily to be tried again via the
+// route, or give up on them once they have been tried for MaxRetryTime. Once they have been
+// retried for DelayWarning, the sender is warned of the delay. Returns whether any
+// recipients will be tried again, and the delivery error with the recipients given up on
+// failed permanently, so they are bounced.
+func RetryMessage(route Route, message Message, original int, err error) (bool, error) {
+	var pending []string
+	for _, failure := range recipientFailures(message.To, err) {
+		if IsTemporary(failure.Err) {
+			pending = append(pending, failure.Recipient)
+		}
+	}
+	if len(pending) == 0 {
+		return false, err
+	}
+
+	now := time.Now()
+	if message.Deferred.IsZero() {
+		message.Deferred = now
+	}
+	if now.Sub(message.Deferred) >= MaxRetryTime {
+		log.Printf("Giving up on mail from %s via route %s after retrying for %s.", message.From, route.Name, MaxRetryTime)
+		return false, expireFailures(message.To, err)
+	}
+
+	wait := RetryInterval
+	for i := 0; i < message.Attempts && wait < MaxRetryInterval; i++ {
+		wait *= 2
+	}
+	if wait > MaxRetryInterval {
+		wait = MaxRetryInterval
+	}
+	message.To = pending
+	message.Attempts++
+	if !message.warned && now.Sub(message.Deferred) >= DelayWarning {
+		message.warned = true
+		WarnDelay(route, message, fmt.Sprintf("delivery via route %s failed temporarily: %v", route.Name, err))
+	}
+	log.Printf("Retrying mail from %s via route %s in %s.", message.From, route.Name, wait)
+	// The message is routed again, in case the route is rate limited or has been removed.
+	time.AfterFunc(wait, func() { RouteMessage(message, route.Id, original) })
+	return true, err
+}
+
+// Fail the recipients that failed temporarily permanently, as they have been retried for too long.
+func expireFailures(to []string, err error) error {
+	failures := append([]RecipientError(nil), recipientFailures(to, err)...)
+	for i, failure := range failures {
+		if IsTemporary(failure.Err) {
+			// X.4.7 is delivery time expired (RFC 3463).
+			failures[i].Err = &DeliveryError{Msg: fmt.Sprintf("5.4.7 Delivery time expired: %v", failure.Err), Permanent: true}
+		}
+	}
+	return &DeliveryError{Msg: fmt.Sprintf("gave up after %s: %v", MaxRetryTime, err), Err: err, Permanent: true, Failures: failures}
+}
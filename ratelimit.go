@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// A token bucket, which holds up to Capacity tokens and refills at Rate tokens per second.
+type TokenBucket struct {
+	Capacity float64
+	Rate     float64
+	Tokens   float64 // Negative after taking more than the bucket held
+	updated  time.Time
+}
+
+// Create a full token bucket.
+func NewTokenBucket(capacity float64, rate float64, now time.Time) *TokenBucket {
+	return &TokenBucket{Capacity: capacity, Rate: rate, Tokens: capacity, updated: now}
+}
+
+func (b *TokenBucket) refill(now time.Time) {
+	if now.After(b.updated) {
+		b.Tokens = math.Min(b.Capacity, b.Tokens+now.Sub(b.updated).Seconds()*b.Rate)
+		b.updated = now
+	}
+}
+
+// Return how long to wait before n tokens can be taken. More tokens than the bucket can hold
+// may be taken once it is full, so a large message is delayed rather than blocked forever.
+func (b *TokenBucket) Wait(n float64, now time.Time) time.Duration {
+	b.refill(now)
+	need := math.Min(n, b.Capacity) - b.Tokens
+	if need <= 0 {
+		return 0
+	}
+	return time.Duration(math.Ceil(need / b.Rate * float64(time.Second)))
+}
+
+// Take n tokens from the bucket.
+func (b *TokenBucket) Take(n float64, now time.Time) {
+	b.refill(now)
+	b.Tokens -= n
+}
+
+// The rate limits of a route and the token buckets that enforce them.
+type routeLimiter struct {
+	msgsPerSecond float64
+	msgsPerHour   int
+	bytesPerHour  int
+	second        *TokenBucket // Nil if there is no limit
+	hour          *TokenBucket
+	bytes         *TokenBucket
+}
+
+// The buckets of a limiter that are in use, with the size of a message in their units.
+func (l *routeLimiter) buckets(size int) map[*TokenBucket]float64 {
+	buckets := map[*TokenBucket]float64{}
+	if l.second != nil {
+		buckets[l.second] = 1
+	}
+	if l.hour != nil {
+		buckets[l.hour] = 1
+	}
+	if l.bytes != nil {
+		buckets[l.bytes] = float64(size)
+	}
+	return buckets
+}
+
+// Rate limiters for all routes, keyed by route ID.
+type RateLimits struct {
+	sync.Mutex
+	limiters map[string]*routeLimiter
+}
+
+var rateLimits = RateLimits{limiters: map[string]*routeLimiter{}}
+
+// Return the limiter for a route, creating it with full buckets if the route's limits have changed.
+// The caller must hold the lock.
+func (rl *RateLimits) limiter(route Route, now time.Time) *routeLimiter {
+	l, exists := rl.limiters[route.Id]
+	if exists && l.msgsPerSecond == route.MsgsPerSecond && l.msgsPerHour == route.MsgsPerHour && l.bytesPerHour == route.BytesPerHour {
+		return l
+	}
+	l = &routeLimiter{msgsPerSecond: route.MsgsPerSecond, msgsPerHour: route.MsgsPerHour, bytesPerHour: route.BytesPerHour}
+	if route.MsgsPerSecond > 0 {
+		// Allow a burst of one second's messages, or one message if the limit is below one per second.
+		l.second = NewTokenBucket(math.Max(1, route.MsgsPerSecond), route.MsgsPerSecond, now)
+	}
+	if route.MsgsPerHour > 0 {
+		l.hour = NewTokenBucket(float64(route.MsgsPerHour), float64(route.MsgsPerHour)/3600, now)
+	}
+	if route.BytesPerHour > 0 {
+		l.bytes = NewTokenBucket(float64(route.BytesPerHour), float64(route.BytesPerHour)/3600, now)
+	}
+	rl.limiters[route.Id] = l
+	return l
+}
+
+// Reserve capacity to send a message of the given size via a route. If the route's limits allow
+// the message now, the capacity is taken and zero is returned. Otherwise nothing is taken and the
+// time to wait before trying again is returned.
+func (rl *RateLimits) Reserve(route Route, size int, now time.Time) time.Duration {
+	rl.Lock()
+	defer rl.Unlock()
+
+	buckets := rl.limiter(route, now).buckets(size)
+	var wait time.Duration
+	for bucket, n := range buckets {
+		if w := bucket.Wait(n, now); w > wait {
+			wait = w
+		}
+	}
+	if wait > 0 {
+		return wait
+	}
+	for bucket, n := range buckets {
+		bucket.Take(n, now)
+	}
+	return 0
+}
+
+// The state of one of a route's rate limits for the route listing.
+type LimitStatus struct {
+	Limit     string // e.g. "100 messages per hour"
+	Available string // Capacity that could be used now
+}
+
+// List the rate limits of a route with the capacity currently available under each.
+func (r Route) LimitStatus() []LimitStatus {
+	rateLimits.Lock()
+	defer rateLimits.Unlock()
+
+	now := time.Now()
+	l := rateLimits.limiter(r, now)
+	var status []LimitStatus
+	if l.second != nil {
+		l.second.refill(now)
+		status = append(status, LimitStatus{fmt.Sprintf("%g messages per second", r.MsgsPerSecond), fmt.Sprintf("%.1f messages", math.Max(0, l.second.Tokens))})
+	}
+	if l.hour != nil {
+		l.hour.refill(now)
+		status = append(status, LimitStatus{fmt.Sprintf("%d messages per hour", r.MsgsPerHour), fmt.Sprintf("%.0f messages", math.Floor(math.Max(0, l.hour.Tokens)))})
+	}
+	if l.bytes != nil {
+		l.bytes.refill(now)
+		status = append(status, LimitStatus{FormatSize(r.BytesPerHour) + " per hour", FormatSize(int(math.Max(0, l.bytes.Tokens)))})
+	}
+	return status
+}
+
+// Report whether the route has any rate limits, so that mail for it is queued.
+func (r Route) RateLimited() bool {
+	return r.MsgsPerSecond > 0 || r.MsgsPerHour > 0 || r.BytesPerHour > 0
+}
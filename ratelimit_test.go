@@ -0,0 +1,106 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTokenBucket(t *testing.T) {
+	start := time.Now()
+	b := NewTokenBucket(2, 0.5, start)
+	tests := []struct {
+		after time.Duration
+		n     float64
+		wait  time.Duration
+	}{
+		{0, 1, 0},
+		{0, 1, 0},
+		{0, 1, 2 * time.Second},
+		{time.Second, 1, time.Second},
+		{2 * time.Second, 1, 0},
+		{2 * time.Second, 5, 4 * time.Second},
+		{10 * time.Second, 5, 0},
+		{10 * time.Second, 1, 8 * time.Second},
+	}
+	for _, tt := range tests {
+		now := start.Add(tt.after)
+		wait := b.Wait(tt.n, now)
+		if wait != tt.wait {
+			t.Errorf("Wait(%g) after %v = %v, want %v", tt.n, tt.after, wait, tt.wait)
+		}
+		if wait == 0 {
+			b.Take(tt.n, now)
+		}
+	}
+}
+
+func TestRateLimitsReserve(t *testing.T) {
+	limits := RateLimits{limiters: map[string]*routeLimiter{}}
+	now := time.Now()
+	route := Route{Id: "limited", MsgsPerSecond: 10, MsgsPerHour: 3, BytesPerHour: 1000}
+	tests := []struct {
+		size    int
+		delayed bool
+	}{
+		{400, false},
+		{400, false},
+		{400, true}, // Over the byte limit
+		{100, false},
+		{100, true}, // Over the message limit
+	}
+	for i, tt := range tests {
+		if wait := limits.Reserve(route, tt.size, now); (wait > 0) != tt.delayed {
+			t.Errorf("Reserve(%d) #%d = %v, want delayed %v", tt.size, i, wait, tt.delayed)
+		}
+	}
+
+	// Changing the limits starts again with full buckets.
+	route.MsgsPerHour = 10
+	if wait := limits.Reserve(route, 100, now); wait != 0 {
+		t.Errorf("Reserve() after changing limits = %v, want 0", wait)
+	}
+	if wait := limits.Reserve(Route{Id: "unlimited"}, 1000000, now); wait != 0 {
+		t.Errorf("Reserve() for unlimited route = %v, want 0", wait)
+	}
+}
+
+func TestQueueMessage(t *testing.T) {
+	useTestCapture(t)
+	useTestRoutes(t,
+		Route{Id: "DROP", Name: "Drop"},
+		Route{Id: "limited", Name: "Limited", Type: RouteCapture, MsgsPerHour: 2},
+	)
+	data := []byte("Subject: Hello\r\n\r\nHi.\r\n")
+	for i := 0; i < 3; i++ {
+		RouteMessage(Message{From: "sender@example.com", To: []string{"a@example.com"}, Data: data}, "limited", 0)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(captured.Search("")) < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	route := config.Routes["limited"]
+	if n := len(captured.Search("")); n != 2 {
+		t.Errorf("delivered %d messages, want 2 within the limit", n)
+	}
+	if n := route.Queued(); n != 1 {
+		t.Errorf("Queued() = %d, want 1", n)
+	}
+	status := route.LimitStatus()
+	if len(status) != 1 || status[0].Limit != "2 messages per hour" || status[0].Available != "0 messages" {
+		t.Errorf("LimitStatus() = %+v, want 0 messages available of 2 per hour", status)
+	}
+
+	// Lifting the limit lets the queued message through.
+	route.MsgsPerHour = 0
+	config.Lock()
+	config.Routes["limited"] = route
+	config.Unlock()
+	deadline = time.Now().Add(5 * time.Second)
+	for route.Queued() > 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := len(captured.Search("")); n != 3 {
+		t.Errorf("delivered %d messages after lifting the limit, want 3", n)
+	}
+}
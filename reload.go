@@ -0,0 +1,108 @@
+package main
+
+import (
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Time between checks of the configuration file for changes, when ReloadOnChange is set.
+const ConfigPoll = 5 * time.Second
+
+// The modification time of the configuration file when it was last loaded or saved, so the
+// watcher can tell when something else has changed it.
+var configModTime struct {
+	sync.Mutex
+	t time.Time
+}
+
+// Record the modification time of the configuration file as it is now.
+func recordConfigModTime() {
+	var t time.Time
+	if info, err := os.Stat(*confFile); err == nil {
+		t = info.ModTime()
+	}
+	configModTime.Lock()
+	defer configModTime.Unlock()
+	configModTime.t = t
+}
+
+// Report whether the configuration file has changed since it was last loaded or saved.
+func configChanged() bool {
+	info, err := os.Stat(*confFile)
+	if err != nil {
+		return false
+	}
+	configModTime.Lock()
+	defer configModTime.Unlock()
+	return !info.ModTime().Equal(configModTime.t)
+}
+
+// Apply the options that are read once rather than each time they are used.
+func ApplyOptions() {
+	conf := ConfigSnapshot()
+
+	// Direct DNS lookups to the configured server, if any.
+	resolver.Set(NewDNSResolver(conf.Options["DNSServer"]))
+
+	// Limit the connections and mail accepted from each client address and network.
+	throttle.SetLimits(
+		ClientLimits{conf.OptionInt("MaxConnectionsPerIP"), conf.OptionInt("ConnectionsPerMinutePerIP"), conf.OptionInt("MessagesPerMinutePerIP")},
+		ClientLimits{conf.OptionInt("MaxConnectionsPerNetwork"), conf.OptionInt("ConnectionsPerMinutePerNetwork"), conf.OptionInt("MessagesPerMinutePerNetwork")},
+		conf.OptionInt("NetworkPrefixIPv4"), conf.OptionInt("NetworkPrefixIPv6"),
+	)
+}
+
+// Reload the configuration file. If the new configuration cannot be read or is invalid, the
+// current configuration is kept and the error returned. Otherwise it replaces the current
+// configuration at once, and the listeners are updated without dropping SMTP sessions.
+func ReloadConfig() error {
+	// Record the time first, so a change made while reloading is picked up by the next check.
+	recordConfigModTime()
+	c, err := ReadConfig(*confFile)
+	if err == nil {
+		err = c.Validate()
+	}
+	if err != nil {
+		return err
+	}
+
+	config.Lock()
+	config.Routes, config.Filters, config.Options, config.Listeners = c.Routes, c.Filters, c.Options, c.Listeners
+	config.Unlock()
+
+	ApplyOptions()
+	UpdateListeners()
+	// Subtract 1 from config.Routes to account for Drop route.
+	log.Printf("Reloaded %d routes, %d filters and %d listeners.", len(c.Routes)-1, len(c.Filters), len(Listeners()))
+	return nil
+}
+
+// Reload the configuration when the process receives SIGHUP, and when the configuration file
+// changes if the ReloadOnChange option is set.
+func WatchConfig() {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	poll := time.NewTicker(ConfigPoll)
+	defer poll.Stop()
+	for {
+		select {
+		case <-hup:
+			log.Printf("Received SIGHUP, reloading configuration file %s.", *confFile)
+		case <-poll.C:
+			config.RLock()
+			watch := config.Options["ReloadOnChange"] == "true"
+			config.RUnlock()
+			if !watch || !configChanged() {
+				continue
+			}
+			log.Printf("Configuration file %s changed, reloading it.", *confFile)
+		}
+		if err := ReloadConfig(); err != nil {
+			log.Printf("Could not reload configuration file, keeping the current configuration: %v", err)
+		}
+	}
+}
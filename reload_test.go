@@ -0,0 +1,273 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mhale/mailrouter/smtpd"
+)
+
+// Use a temporary configuration file and a fresh configuration for the duration of a test,
+// stopping any listeners the test starts.
+func useTestConfigFile(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "mailrouter.conf")
+	savedFile, saved := *confFile, CloneConfig()
+	*confFile = path
+	t.Cleanup(func() {
+		*confFile = savedFile
+		config.Routes, config.Filters, config.Options, config.Listeners = saved.Routes, saved.Filters, saved.Options, saved.Listeners
+		running.Lock()
+		defer running.Unlock()
+		for name, r := range running.listeners {
+			r.stopped = true
+			r.ln.Close()
+			delete(running.listeners, name)
+		}
+	})
+	return path
+}
+
+// Modification time of the last configuration file written by writeTestConfig.
+var testConfigTime = time.Now()
+
+// Write a configuration file with a modification time after any earlier one, as file times
+// may be too coarse to tell quick changes apart.
+func writeTestConfig(t *testing.T, path string, data string) {
+	if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	testConfigTime = testConfigTime.Add(time.Second)
+	os.Chtimes(path, testConfigTime, testConfigTime)
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		config string
+		err    string
+	}{
+		{`{"Listeners": [{"Name": "a", "Addr": ":2525"}, {"Name": "b", "Socket": "/run/mailrouter.sock"}]}`, ""},
+		{`{"Listeners": [{"Addr": ":2525"}]}`, "listener on :2525 has no name"},
+		{`{"Listeners": [{"Name": "a", "Addr": ":2525"}, {"Name": "a", "Addr": ":2526"}]}`, "listener name a is used more than once"},
+		{`{"Listeners": [{"Name": "a"}]}`, "listener a has no address or socket"},
+		{`{"Listeners": [{"Name": "a", "Addr": ":2525", "RequireTLS": true}]}`, "listener a requires TLS but has no certificate"},
+		{`{"Options": {"MaxConnectionsPerIP": "ten"}}`, `option MaxConnectionsPerIP must be a number, not "ten"`},
+		{`{"Options": {"NetworkPrefixIPv4": "-1"}}`, `option NetworkPrefixIPv4 must be a number, not "-1"`},
+		{`{"Options": {"MaxRecipientsPerMessage": ""}}`, ""},
+		{`{"Options": {"AllowClients": "192.0.2.0/24, 2001:db8::1", "DenyClients": "192.0.2.1"}}`, ""},
+		{`{"Options": {"AllowClients": "10.0.0.0/33"}}`, `option AllowClients: "10.0.0.0/33" is not a network or address`},
+		{`{"Options": {"DenyClients": "192.0.2.1, example.com"}}`, `option DenyClients: "example.com" is not a network or address`},
+		{`{"Listeners": [{"Name": "a", "Addr": ":2525", "TrustedProxies": ["10.0.0.5/40"], "XClient": true}]}`, `listener a has invalid trusted proxies: "10.0.0.5/40" is not a network or address`},
+	}
+	path := filepath.Join(t.TempDir(), "mailrouter.conf")
+	for _, tt := range tests {
+		ioutil.WriteFile(path, []byte(tt.config), 0644)
+		c, err := ReadConfig(path)
+		if err != nil {
+			t.Fatalf("ReadConfig(%s) = %v", tt.config, err)
+		}
+		err = c.Validate()
+		if (err == nil && tt.err != "") || (err != nil && err.Error() != tt.err) {
+			t.Errorf("Validate(%s) = %v, want %q", tt.config, err, tt.err)
+		}
+	}
+}
+
+// A configuration's listeners are checked with its own options, not the ones in use.
+func TestConfigValidateOwnOptions(t *testing.T) {
+	useTestOptions(t, map[string]string{"AllowClients": "192.0.2.0/24"})
+	c := &Config{Options: map[string]string{"MaxRecipientsPerMessage": "10", "RecipientDomains": "example.org"}, Listeners: []Listener{{Name: "a", Addr: ":2525"}}}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Validate() = %v", err)
+	}
+	srv, err := c.Listeners[0].Server(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if srv.AllowClients != nil || srv.MaxRecipients != 10 || len(srv.RecipientDomains) != 1 || srv.RecipientDomains[0] != "example.org" {
+		t.Errorf("Server() = allow %v, max recipients %d, domains %v, want the options of its config", srv.AllowClients, srv.MaxRecipients, srv.RecipientDomains)
+	}
+}
+
+func TestReloadConfig(t *testing.T) {
+	path := useTestConfigFile(t)
+	listeners := `"Listeners": [{"Name": "test", "Addr": "127.0.0.1:0"}]`
+	writeTestConfig(t, path, `{"Routes": {"a": {"Id": "a", "Name": "A", "IsDefault": true}}, `+listeners+`}`)
+	if err := LoadConfig(); err != nil {
+		t.Fatal(err)
+	}
+	if configChanged() {
+		t.Errorf("configChanged() = true after loading the file")
+	}
+
+	// An invalid configuration is not used.
+	for _, invalid := range []string{`{"Routes": `, `{"Options": {"MessagesPerMinutePerIP": "many"}, ` + listeners + `}`} {
+		writeTestConfig(t, path, invalid)
+		if !configChanged() {
+			t.Errorf("configChanged() = false after the file changed")
+		}
+		if err := ReloadConfig(); err == nil {
+			t.Errorf("ReloadConfig() with %s succeeded, want an error", invalid)
+		}
+		if _, exists := config.Routes["a"]; !exists || config.DefaultRouteId() != "a" {
+			t.Errorf("routes after an invalid reload = %v, want the current routes kept", config.Routes)
+		}
+		if configChanged() {
+			t.Errorf("configChanged() = true after a failed reload, want the file not retried until it changes")
+		}
+	}
+
+	writeTestConfig(t, path, `{"Routes": {"b": {"Id": "b", "Name": "B"}}, "Options": {"MaxConnectionsPerIP": "3"}, `+listeners+`}`)
+	if err := ReloadConfig(); err != nil {
+		t.Fatalf("ReloadConfig() = %v", err)
+	}
+	if _, exists := config.Routes["a"]; exists || config.Routes["b"].Name != "B" || config.DefaultRouteId() != "DROP" {
+		t.Errorf("routes after reload = %v, want B and Drop as the default", config.Routes)
+	}
+	if throttle.PerIP.MaxConnections != 3 {
+		t.Errorf("throttle limit after reload = %d, want 3", throttle.PerIP.MaxConnections)
+	}
+
+	// Saving from the web interface is not mistaken for a change to reload.
+	if err := SaveConfig(); err != nil {
+		t.Fatal(err)
+	}
+	if configChanged() {
+		t.Errorf("configChanged() = true after saving the file")
+	}
+}
+
+func TestReloadWhileRouting(t *testing.T) {
+	path := useTestConfigFile(t)
+	useTestCapture(t)
+	routes := `"a": {"Id": "a", "Name": "A", "Type": "capture", "IsDefault": true}`
+	filters := `"Filters": {"1": {"Id": "1", "Name": "To B", "Order": 1, "To": "b@", "RouteId": "b"}}`
+	listeners := `"Listeners": [{"Name": "test", "Addr": "127.0.0.1:0"}]`
+	withB := `{"Routes": {` + routes + `, "b": {"Id": "b", "Name": "B", "Type": "capture"}}, ` + filters + `, ` + listeners + `}`
+	withoutB := `{"Routes": {` + routes + `}, ` + filters + `, ` + listeners + `}`
+	writeTestConfig(t, path, withB)
+	if err := LoadConfig(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Mail for a route removed while the message is handled goes to the default route.
+	const n = 20
+	done := make(chan bool)
+	go func() {
+		for i := 0; i < n; i++ {
+			writeTestConfig(t, path, []string{withoutB, withB}[i%2])
+			ReloadConfig()
+		}
+		done <- true
+	}()
+	origin := &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 25}
+	for i := 0; i < n; i++ {
+		mailHandler(origin, smtpd.Envelope{From: "sender@example.com", To: []string{"b@example.com"}, Listener: "test"}, []byte("Subject: test\r\n\r\ntest\r\n"))
+	}
+	<-done
+	if got := len(captured.Search("")); got != n {
+		t.Errorf("captured %d messages while reloading, want %d", got, n)
+	}
+}
+
+// Run with -race: a group reads its members' routes while reloads replace them.
+func TestReloadWhileDeliveringToGroup(t *testing.T) {
+	path := useTestConfigFile(t)
+	useTestCapture(t)
+	busy := newTestSMTPServer(t, "451 4.3.0 try again later").route("busy")
+	routes := fmt.Sprintf(`"Routes": {"busy": {"Id": "busy", "Name": "Busy", "Type": "smtp", "Hostname": %q, "Port": %d},
+		"capture": {"Id": "capture", "Name": "Capture", "Type": "capture"},
+		"group": {"Id": "group", "Name": "Group", "Type": "failover", "IsDefault": true,
+			"Members": [{"RouteId": "busy", "Order": 1}, {"RouteId": "capture", "Order": 2}]}}`, busy.Hostname, busy.Port)
+	configs := []string{
+		`{` + routes + `, "Options": {"MaxRecipientsPerMessage": "10"}}`,
+		`{` + routes + `, "Options": {"MaxRecipientsPerMessage": "20"}}`,
+	}
+	writeTestConfig(t, path, configs[0])
+	if err := LoadConfig(); err != nil {
+		t.Fatal(err)
+	}
+
+	const n = 20
+	done := make(chan bool)
+	go func() {
+		for i := 0; i < n; i++ {
+			writeTestConfig(t, path, configs[i%2])
+			if err := ReloadConfig(); err != nil {
+				t.Error(err)
+			}
+		}
+		done <- true
+	}()
+	for i := 0; i < n; i++ {
+		RouteMessage(Message{From: "sender@example.com", To: []string{"rcpt@example.com"}, Data: []byte("Subject: test\r\n\r\ntest\r\n")}, "group", 0)
+	}
+	<-done
+	if got := len(captured.Search("")); got != n {
+		t.Errorf("captured %d messages via the group while reloading, want %d", got, n)
+	}
+}
+
+func TestUpdateListeners(t *testing.T) {
+	useTestConfigFile(t)
+	useTestOptions(t, map[string]string{"RecipientDomains": "example.com"})
+	config.Listeners = []Listener{{Name: "kept", Addr: "127.0.0.1:0"}, {Name: "removed", Addr: "127.0.0.1:0"}}
+	if _, err := StartListeners(); err != nil {
+		t.Fatal(err)
+	}
+	running.Lock()
+	kept, removed := running.listeners["kept"].ln.Addr().String(), running.listeners["removed"].ln.Addr().String()
+	running.Unlock()
+
+	// A session that started before the reload carries on with its settings.
+	conn, err := net.Dial("tcp", kept)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	before := textproto.NewConn(conn)
+	before.ReadResponse(220)
+
+	config.Options["RecipientDomains"] = "example.org"
+	config.Listeners = []Listener{{Name: "kept", Addr: "127.0.0.1:0"}, {Name: "added", Addr: "127.0.0.1:0"}}
+	UpdateListeners()
+	running.Lock()
+	_, hasRemoved := running.listeners["removed"]
+	_, hasAdded := running.listeners["added"]
+	running.Unlock()
+	if hasRemoved || !hasAdded {
+		t.Errorf("running listeners after reload: removed %v, added %v, want only added", hasRemoved, hasAdded)
+	}
+	if conn, err := net.Dial("tcp", removed); err == nil {
+		conn.Close()
+		t.Errorf("removed listener still accepts connections")
+	}
+
+	rcpt := func(text *textproto.Conn, to string) int {
+		for _, cmd := range []string{"EHLO app.example.com", "MAIL FROM:<app@example.com>"} {
+			text.PrintfLine("%s", cmd)
+			text.ReadResponse(250)
+		}
+		text.PrintfLine("RCPT TO:<%s>", to)
+		code, _, _ := text.ReadResponse(0)
+		return code
+	}
+	if code := rcpt(before, "user@example.com"); code != 250 {
+		t.Errorf("RCPT in a session started before the reload = %d, want 250 with the old recipient domains", code)
+	}
+	conn, err = net.Dial("tcp", kept)
+	if err != nil {
+		t.Fatalf("kept listener does not accept connections after reload: %v", err)
+	}
+	defer conn.Close()
+	after := textproto.NewConn(conn)
+	after.ReadResponse(220)
+	if code := rcpt(after, "user@example.com"); code != 550 {
+		t.Errorf("RCPT in a session started after the reload = %d, want 550 with the new recipient domains", code)
+	}
+}
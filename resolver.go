@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+)
+
+// Timeout for a single DNS query.
+const DNSTimeout = 10 * time.Second
+
+// Resolver performs the DNS lookups needed for mail authentication and delivery.
+// It is an interface so that lookups can be directed at a local test DNS server or replaced entirely in tests.
+type Resolver interface {
+	LookupTXT(name string) ([]string, error)
+	LookupMX(name string) ([]*net.MX, error)
+	LookupIP(host string) ([]net.IP, error)
+}
+
+// The resolver used for all DNS lookups. ApplyOptions replaces the resolver behind it when
+// the configuration is loaded or reloaded.
+var resolver = &SharedResolver{r: NewDNSResolver("")}
+
+// SharedResolver is a Resolver that passes lookups to another, which can be replaced while
+// lookups are in progress.
+type SharedResolver struct {
+	mu sync.RWMutex
+	r  Resolver
+}
+
+// Return the resolver that lookups are passed to.
+func (s *SharedResolver) Get() Resolver {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.r
+}
+
+// Pass later lookups to r. Lookups already in progress finish with the old resolver.
+func (s *SharedResolver) Set(r Resolver) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.r = r
+}
+
+func (s *SharedResolver) LookupTXT(name string) ([]string, error) {
+	return s.Get().LookupTXT(name)
+}
+
+func (s *SharedResolver) LookupMX(name string) ([]*net.MX, error) {
+	return s.Get().LookupMX(name)
+}
+
+func (s *SharedResolver) LookupIP(host string) ([]net.IP, error) {
+	return s.Get().LookupIP(host)
+}
+
+// DNSResolver is a Resolver backed by the Go DNS client.
+type DNSResolver struct {
+	r *net.Resolver
+}
+
+// Create a resolver that queries the given DNS server address (e.g. "127.0.0.1:5353").
+// If server is empty, the system resolver configuration is used.
+func NewDNSResolver(server string) *DNSResolver {
+	r := &net.Resolver{}
+	if server != "" {
+		if _, _, err := net.SplitHostPort(server); err != nil {
+			server = net.JoinHostPort(server, "53")
+		}
+		r.PreferGo = true
+		r.Dial = func(ctx context.Context, network, address string) (net.Conn, error) {
+			d := net.Dialer{Timeout: DNSTimeout}
+			return d.DialContext(ctx, network, server)
+		}
+	}
+	return &DNSResolver{r: r}
+}
+
+func (dr *DNSResolver) LookupTXT(name string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DNSTimeout)
+	defer cancel()
+	return dr.r.LookupTXT(ctx, name)
+}
+
+func (dr *DNSResolver) LookupMX(name string) ([]*net.MX, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DNSTimeout)
+	defer cancel()
+	return dr.r.LookupMX(ctx, name)
+}
+
+func (dr *DNSResolver) LookupIP(host string) ([]net.IP, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DNSTimeout)
+	defer cancel()
+	addrs, err := dr.r.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	ips := make([]net.IP, len(addrs))
+	for i, addr := range addrs {
+		ips[i] = addr.IP
+	}
+	return ips, nil
+}
+
+// Report whether a lookup error means the name or record does not exist, as opposed to a temporary failure.
+func IsNotFound(err error) bool {
+	if dnsErr, ok := err.(*net.DNSError); ok {
+		return dnsErr.IsNotFound
+	}
+	return false
+}
@@ -1,19 +1,142 @@
 package main
 
 import (
+	"fmt"
+	"net/http"
 	"sort"
+	"strconv"
+)
+
+// Route types. Routes without a type are treated as SMTP routes.
+const (
+	RouteSMTP     = "smtp"
+	RouteFailover = "failover"
+	RouteBalance  = "balance"
+	RouteMX       = "mx"
+	RouteLMTP     = "lmtp"
+	RouteMaildir  = "maildir"
+	RouteMbox     = "mbox"
+	RoutePipe     = "pipe"
+	RouteWebhook  = "webhook"
+	RouteCapture  = "capture"
+)
+
+// Load balancing strategies for balance route groups.
+const (
+	BalanceRoundRobin       = "roundrobin"
+	BalanceLeastOutstanding = "leastoutstanding"
 )
 
 type Route struct {
-	Id        string
-	Name      string
-	To        string
-	Hostname  string
-	Port      int
-	AuthType  string
-	Username  string
-	Password  string
-	IsDefault bool
+	Id              string
+	Name            string
+	Type            string
+	To              string
+	Hostname        string
+	Port            int
+	Path            string            // Filesystem path for routes that use a socket or file
+	Command         string            // Command line for pipe routes
+	URL             string            // Webhook URL
+	Format          string            // Webhook body format, raw or json
+	Headers         map[string]string // Extra webhook request headers
+	Secret          string            // Webhook HMAC signing key
+	Timeout         int               // Webhook timeout in seconds
+	AuthType        string
+	Username        string
+	Password        string
+	Members         []RouteMember // Member routes of a route group
+	Strategy        string        // Load balancing strategy for balance groups
+	IsDefault       bool
+	SuppressBounces bool    // Don't bounce mail that fails permanently via this route
+	MsgsPerSecond   float64 // Rate limits. Zero is unlimited.
+	MsgsPerHour     int
+	BytesPerHour    int
+
+	// SMTP connection pooling
+	ReuseConnections         bool // Keep connections open for later messages
+	MaxConnections           int  // Zero is unlimited
+	MaxMessagesPerConnection int  // Zero is unlimited
+
+	XForward bool // Pass the original client's details to servers that support XFORWARD
+}
+
+// A route that belongs to a route group.
+type RouteMember struct {
+	RouteId string
+	Order   int
+	Weight  int // Relative share of traffic in a balance group. Zero drains the member.
+}
+
+// Report whether the route delivers via other routes rather than directly.
+func (r Route) IsGroup() bool {
+	return r.Type == RouteFailover || r.Type == RouteBalance
+}
+
+// Return the members of a route group in the order they should be tried.
+func (r Route) SortedMembers() []RouteMember {
+	members := make([]RouteMember, len(r.Members))
+	copy(members, r.Members)
+	sort.SliceStable(members, func(i, j int) bool {
+		return members[i].Order < members[j].Order
+	})
+	return members
+}
+
+// Describe the route for the route listing.
+func (r Route) Summary() string {
+	if r.Type == RouteFailover {
+		return "Failover group"
+	}
+	if r.Type == RouteBalance {
+		if r.Strategy == BalanceLeastOutstanding {
+			return "Load balanced group (least outstanding)"
+		}
+		return "Load balanced group (round robin)"
+	}
+	if r.Type == RouteMX {
+		port := r.Port
+		if port == 0 {
+			port = DirectPort
+		}
+		return fmt.Sprintf("Direct delivery (MX) on port %d", port)
+	}
+	if r.Type == RouteLMTP {
+		if r.Path != "" {
+			return fmt.Sprintf("LMTP %s", r.Path)
+		}
+		port := r.Port
+		if port == 0 {
+			port = LMTPPort
+		}
+		return fmt.Sprintf("LMTP %s:%d", r.Hostname, port)
+	}
+	if r.Type == RouteMaildir {
+		return fmt.Sprintf("Maildir %s", r.Path)
+	}
+	if r.Type == RouteMbox {
+		return fmt.Sprintf("mbox %s", r.Path)
+	}
+	if r.Type == RoutePipe {
+		return fmt.Sprintf("Pipe to %s", r.Command)
+	}
+	if r.Type == RouteWebhook {
+		if r.Format == WebhookJSON {
+			return fmt.Sprintf("Webhook (JSON) %s", r.URL)
+		}
+		return fmt.Sprintf("Webhook %s", r.URL)
+	}
+	if r.Type == RouteCapture {
+		return "Capture to Messages page"
+	}
+	if r.Id == "DROP" {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d", r.Hostname, r.Port)
+}
+
+// Format the webhook request headers for the route form.
+func (r Route) HeaderText() string {
+	return FormatHeaders(r.Headers)
 }
 
 type RouteList []Route
@@ -48,3 +171,101 @@ func SortedRoutes() RouteList {
 	sort.Sort(rl)
 	return rl
 }
+
+// A candidate member route for the route group form, with its settings in the group being edited.
+type MemberOption struct {
+	Route    Route
+	IsMember bool
+	Order    int
+	Weight   int
+}
+
+// List the routes that can be members of a group, i.e. all routes that deliver directly.
+func MemberCandidates(group Route) []MemberOption {
+	var options []MemberOption
+	for _, route := range SortedRoutes() {
+		if route.IsGroup() || route.Id == "DROP" || route.Id == group.Id {
+			continue
+		}
+		option := MemberOption{Route: route}
+		for _, member := range group.Members {
+			if member.RouteId == route.Id {
+				option.IsMember = true
+				option.Order = member.Order
+				option.Weight = member.Weight
+			}
+		}
+		options = append(options, option)
+	}
+	return options
+}
+
+// Read the group members from a route form submission.
+// A member is included when its order or weight field is filled in. The weight defaults to 1.
+func ParseMembers(req *http.Request, groupId string) []RouteMember {
+	var members []RouteMember
+	for _, route := range SortedRoutes() {
+		orderValue := req.FormValue("member-" + route.Id)
+		weightValue := req.FormValue("weight-" + route.Id)
+		if orderValue == "" && weightValue == "" {
+			continue
+		}
+		if route.IsGroup() || route.Id == "DROP" || route.Id == groupId {
+			continue
+		}
+		order, _ := strconv.Atoi(orderValue)
+		weight := 1
+		if weightValue != "" {
+			weight, _ = strconv.Atoi(weightValue)
+			if weight < 0 {
+				weight = 0
+			}
+		}
+		members = append(members, RouteMember{RouteId: route.Id, Order: order, Weight: weight})
+	}
+	return members
+}
+
+// The state of a group member for the route listing.
+type MemberStatus struct {
+	Name        string
+	Weight      int
+	Sent        int
+	Failed      int
+	Outstanding int
+}
+
+// List the members of a route group with their delivery counts.
+func (r Route) MemberStatus() []MemberStatus {
+	config.RLock()
+	defer config.RUnlock()
+	var status []MemberStatus
+	for _, member := range r.SortedMembers() {
+		counts := groupStats.Get(r.Id, member.RouteId)
+		status = append(status, MemberStatus{
+			Name:        config.Routes[member.RouteId].Name,
+			Weight:      member.Weight,
+			Sent:        counts.Sent,
+			Failed:      counts.Failed,
+			Outstanding: counts.Outstanding,
+		})
+	}
+	return status
+}
+
+// Remove a deleted route from any groups it belongs to.
+func RemoveMember(routeId string) {
+	for id, route := range config.Routes {
+		if !route.IsGroup() {
+			continue
+		}
+		var members []RouteMember
+		for _, member := range route.Members {
+			if member.RouteId != routeId {
+				members = append(members, member)
+			}
+		}
+		route.Members = members
+		config.Routes[id] = route
+	}
+}
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"os/user"
+	"strings"
+	"time"
+)
+
+// Exit statuses of sendmail mode, from sysexits.h.
+const (
+	ExitUsage       = 64 // Invalid command line
+	ExitDataErr     = 65 // Invalid message or no recipients
+	ExitUnavailable = 69 // Message rejected
+	ExitTempFail    = 75 // Message could be neither submitted nor spooled
+)
+
+// Time allowed to connect to the running mailrouter before spooling the message instead.
+const SendmailTimeout = 10 * time.Second
+
+// Command line options of sendmail mode.
+type SendmailOptions struct {
+	From        string // Envelope sender given with -f or -r
+	FullName    string // Sender's name given with -F, used if the message has no From header
+	ReadHeaders bool   // -t: add recipients from the To, Cc and Bcc headers
+	IgnoreDots  bool   // -i or -oi: a line with a single dot does not end the message
+	Recipients  []string
+}
+
+// Parse the command line of sendmail mode. The flags used by applications that call
+// /usr/sbin/sendmail are supported, and options that only affect the behaviour of a full
+// sendmail, such as -oem or -odb, are accepted and ignored.
+func ParseSendmailArgs(args []string) (SendmailOptions, error) {
+	var opts SendmailOptions
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			opts.Recipients = append(opts.Recipients, splitRecipients(args[i+1:])...)
+			break
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			opts.Recipients = append(opts.Recipients, splitRecipients(args[i:i+1])...)
+			continue
+		}
+
+		// Options with a value take it from the rest of the argument or the next argument.
+		value := func() (string, error) {
+			if len(arg) > 2 {
+				return arg[2:], nil
+			}
+			if i+1 >= len(args) {
+				return "", fmt.Errorf("option %s requires a value", arg)
+			}
+			i++
+			return args[i], nil
+		}
+		var err error
+		switch {
+		case arg == "-t":
+			opts.ReadHeaders = true
+		case arg == "-i" || arg == "-oi":
+			opts.IgnoreDots = true
+		case arg[1] == 'f' || arg[1] == 'r':
+			opts.From, err = value()
+		case arg[1] == 'F':
+			opts.FullName, err = value()
+		case arg == "-bm" || arg == "-v" || arg == "-G" || arg == "-U" || arg[1] == 'o' || arg[1] == 'e':
+			// Delivery mode, verbosity, and other options that don't apply.
+		case strings.ContainsRune("BCLNRVX", rune(arg[1])):
+			// Options with a value that don't apply, such as -B 8BITMIME.
+			_, err = value()
+		default:
+			err = fmt.Errorf("unsupported option %s", arg)
+		}
+		if err != nil {
+			return opts, err
+		}
+	}
+	return opts, nil
+}
+
+// Split arguments that may each hold several comma separated recipients.
+func splitRecipients(args []string) []string {
+	var recipients []string
+	for _, arg := range args {
+		for _, rcpt := range strings.Split(arg, ",") {
+			if rcpt = strings.TrimSpace(rcpt); rcpt != "" {
+				recipients = append(recipients, rcpt)
+			}
+		}
+	}
+	return recipients
+}
+
+// Read a message as sendmail does: up to the end of input, or a line with a single dot unless
+// dots are ignored. Line endings are converted to CRLF.
+func ReadSendmailMessage(r io.Reader, ignoreDots bool) ([]byte, error) {
+	var data []byte
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if line == "" && err == io.EOF {
+			break
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "." && !ignoreDots {
+			break
+		}
+		data = append(data, line+"\r\n"...)
+		if err == io.EOF {
+			break
+		}
+	}
+	return data, nil
+}
+
+// Prepare a message for submission: add the recipients in its headers if asked to, remove any
+// Bcc header, and add the From, Date and Message-ID headers if the message has none.
+// Returns the message and its recipients.
+func PrepareSendmailMessage(data []byte, opts SendmailOptions, hostname string) ([]byte, []string, error) {
+	fields, body := SplitMessage(data)
+	recipients := append([]string(nil), opts.Recipients...)
+	present := map[string]bool{}
+	var kept []string
+	for _, field := range fields {
+		name := strings.ToLower(HeaderName(field))
+		present[name] = true
+		if opts.ReadHeaders && (name == "to" || name == "cc" || name == "bcc") {
+			addrs, err := mail.ParseAddressList(strings.TrimSpace(strings.Replace(HeaderValue(field), "\r\n", "", -1)))
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid %s header: %v", HeaderName(field), err)
+			}
+			for _, addr := range addrs {
+				recipients = append(recipients, addr.Address)
+			}
+		}
+		if name != "bcc" {
+			kept = append(kept, field)
+		}
+	}
+	if len(recipients) == 0 {
+		return nil, nil, errors.New("no recipients given")
+	}
+
+	data = []byte(strings.Join(kept, "\r\n") + "\r\n\r\n")
+	data = append(data, body...)
+	if !present["message-id"] {
+		data = PrependHeader(data, "Message-ID", fmt.Sprintf("<%d.%d@%s>", time.Now().UnixNano(), os.Getpid(), hostname))
+	}
+	if !present["date"] {
+		data = PrependHeader(data, "Date", time.Now().Format(time.RFC1123Z))
+	}
+	if !present["from"] {
+		from := (&mail.Address{Name: opts.FullName, Address: opts.From}).String()
+		data = PrependHeader(data, "From", from)
+	}
+	return data, recipients, nil
+}
+
+// Return the listener that sendmail mode submits mail to: the first one on a Unix socket,
+// or the first listener if none use a socket.
+func SendmailListener() Listener {
+	listeners := Listeners()
+	for _, l := range listeners {
+		if l.Socket != "" {
+			return l
+		}
+	}
+	return listeners[0]
+}
+
+// Return the network and address to connect to a listener on this machine.
+func (l Listener) LocalAddress() (string, string) {
+	if l.Socket != "" {
+		return "unix", l.Socket
+	}
+	host, port, err := net.SplitHostPort(l.Addr)
+	if err != nil {
+		return "tcp", l.Addr
+	}
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		host = "127.0.0.1"
+	}
+	return "tcp", net.JoinHostPort(host, port)
+}
+
+// Submit a message over SMTP to a running mailrouter.
+func SubmitMessage(network string, addr string, hostname string, from string, to []string, data []byte) error {
+	conn, err := net.DialTimeout(network, addr, SendmailTimeout)
+	if err != nil {
+		return err
+	}
+	c, err := smtp.NewClient(conn, "localhost")
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+	if err = c.Hello(hostname); err != nil {
+		return err
+	}
+	if err = c.Mail(from); err != nil {
+		return err
+	}
+	for _, rcpt := range to {
+		if err = c.Rcpt(rcpt); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err = w.Write(data); err != nil {
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// Report whether an error from SubmitMessage is a permanent (5xx) rejection, so the message
+// should not be spooled for another attempt.
+func IsRejection(err error) bool {
+	var protoErr *textproto.Error
+	return errors.As(err, &protoErr) && protoErr.Code >= 500
+}
+
+// Run sendmail mode with the arguments that follow "sendmail", returning the exit status.
+// The message is read from standard input and submitted to the running mailrouter. If it
+// cannot be reached, the message is left in the spool directory for it to pick up.
+func SendmailMain(args []string) int {
+	log.SetFlags(0)
+	log.SetPrefix("sendmail: ")
+	opts, err := ParseSendmailArgs(args)
+	if err != nil {
+		log.Print(err)
+		return ExitUsage
+	}
+	LoadConfig()
+
+	hostname, _ := os.Hostname()
+	if opts.From == "" {
+		username := "nobody"
+		if u, err := user.Current(); err == nil {
+			username = u.Username
+		}
+		opts.From = username + "@" + hostname
+	}
+	data, err := ReadSendmailMessage(os.Stdin, opts.IgnoreDots)
+	if err != nil {
+		log.Printf("Could not read message: %v", err)
+		return ExitTempFail
+	}
+	data, recipients, err := PrepareSendmailMessage(data, opts, hostname)
+	if err != nil {
+		log.Print(err)
+		return ExitDataErr
+	}
+
+	listener := SendmailListener()
+	network, addr := listener.LocalAddress()
+	err = SubmitMessage(network, addr, hostname, opts.From, recipients, data)
+	if err == nil {
+		return 0
+	}
+	if IsRejection(err) {
+		log.Printf("Message rejected: %v", err)
+		return ExitUnavailable
+	}
+
+	spool := config.Options["SpoolDirectory"]
+	if spool == "" {
+		log.Printf("Could not submit message: %v", err)
+		return ExitTempFail
+	}
+	name, spoolErr := SpoolMessage(spool, SpooledMessage{From: opts.From, To: recipients, Data: data})
+	if spoolErr != nil {
+		log.Printf("Could not submit message (%v) or spool it: %v", err, spoolErr)
+		return ExitTempFail
+	}
+	log.Printf("Mailrouter is not available (%v), so the message was spooled as %s.", err, name)
+	return 0
+}
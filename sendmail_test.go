@@ -0,0 +1,217 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mhale/mailrouter/smtpd"
+)
+
+func TestParseSendmailArgs(t *testing.T) {
+	tests := []struct {
+		args []string
+		want SendmailOptions
+		err  string
+	}{
+		{[]string{"user@example.com"}, SendmailOptions{Recipients: []string{"user@example.com"}}, ""},
+		{[]string{"-t", "-i"}, SendmailOptions{ReadHeaders: true, IgnoreDots: true}, ""},
+		{[]string{"-oi", "-f", "app@example.com", "a@example.com,b@example.com"}, SendmailOptions{From: "app@example.com", IgnoreDots: true, Recipients: []string{"a@example.com", "b@example.com"}}, ""},
+		{[]string{"-fapp@example.com", "-FWeb App", "-oem", "-odi", "a@example.com"}, SendmailOptions{From: "app@example.com", FullName: "Web App", Recipients: []string{"a@example.com"}}, ""},
+		{[]string{"-r", "app@example.com", "-B", "8BITMIME", "-v", "--", "-odd@example.com"}, SendmailOptions{From: "app@example.com", Recipients: []string{"-odd@example.com"}}, ""},
+		{[]string{"-f"}, SendmailOptions{}, "option -f requires a value"},
+		{[]string{"-bs"}, SendmailOptions{}, "unsupported option -bs"},
+	}
+	for _, tt := range tests {
+		got, err := ParseSendmailArgs(tt.args)
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("ParseSendmailArgs(%q) = %v, want error %q", tt.args, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || got.From != tt.want.From || got.FullName != tt.want.FullName || got.ReadHeaders != tt.want.ReadHeaders ||
+			got.IgnoreDots != tt.want.IgnoreDots || strings.Join(got.Recipients, " ") != strings.Join(tt.want.Recipients, " ") {
+			t.Errorf("ParseSendmailArgs(%q) = %+v, %v, want %+v", tt.args, got, err, tt.want)
+		}
+	}
+}
+
+func TestReadSendmailMessage(t *testing.T) {
+	input := "Subject: dots\n\nfirst\n.\nafter\n"
+	tests := []struct {
+		ignoreDots bool
+		want       string
+	}{
+		{false, "Subject: dots\r\n\r\nfirst\r\n"},
+		{true, "Subject: dots\r\n\r\nfirst\r\n.\r\nafter\r\n"},
+	}
+	for _, tt := range tests {
+		got, err := ReadSendmailMessage(strings.NewReader(input), tt.ignoreDots)
+		if err != nil || string(got) != tt.want {
+			t.Errorf("ReadSendmailMessage(ignoreDots %v) = %q, %v, want %q", tt.ignoreDots, got, err, tt.want)
+		}
+	}
+}
+
+func TestPrepareSendmailMessage(t *testing.T) {
+	data := []byte("To: A <a@example.com>,\r\n b@example.com\r\nBcc: hidden@example.com\r\nSubject: Report\r\n\r\nBody.\r\n")
+	opts := SendmailOptions{From: "app@example.com", FullName: "Web App", ReadHeaders: true, Recipients: []string{"c@example.com"}}
+	got, to, err := PrepareSendmailMessage(data, opts, "host.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(to, " ") != "c@example.com a@example.com b@example.com hidden@example.com" {
+		t.Errorf("recipients = %v, want c, a, b and hidden", to)
+	}
+	message := string(got)
+	for _, s := range []string{"From: \"Web App\" <app@example.com>\r\nDate: ", "\r\nMessage-ID: <", "@host.example.com>\r\nTo: A <a@example.com>,\r\n b@example.com\r\nSubject: Report\r\n\r\nBody.\r\n"} {
+		if !strings.Contains(message, s) {
+			t.Errorf("message = %q, want it to contain %q", message, s)
+		}
+	}
+	if strings.Contains(message, "hidden") {
+		t.Errorf("message = %q, want the Bcc header removed", message)
+	}
+
+	if _, _, err := PrepareSendmailMessage([]byte("Subject: none\r\n\r\n"), SendmailOptions{}, "host"); err == nil {
+		t.Errorf("PrepareSendmailMessage() without recipients succeeded, want an error")
+	}
+}
+
+func TestSendmailSubmitAndSpool(t *testing.T) {
+	useTestCapture(t)
+	useTestRoutes(t,
+		Route{Id: "DROP", Name: "Drop"},
+		Route{Id: "local", Name: "Local", Type: RouteCapture, IsDefault: true},
+	)
+	dir := t.TempDir()
+	socket := filepath.Join(dir, "smtp.sock")
+	useTestListeners(t, Listener{Name: "local", Socket: socket})
+	spool := filepath.Join(dir, "spool")
+	if err := CreateSpoolDirectory(spool); err != nil {
+		t.Fatal(err)
+	}
+	data := []byte("Subject: Cron\r\n\r\nDone.\r\n")
+
+	// With mailrouter down, the message can only be spooled.
+	err := SubmitMessage("unix", socket, "host", "cron@example.com", []string{"root@example.com"}, data)
+	if err == nil || IsRejection(err) {
+		t.Fatalf("SubmitMessage() with no server = %v, want a temporary error", err)
+	}
+	name, err := SpoolMessage(spool, SpooledMessage{From: "cron@example.com", To: []string{"root@example.com"}, Data: data})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := SpoolMessage("", SpooledMessage{From: "cron@example.com", To: []string{"root@example.com"}, Data: data}); err == nil {
+		t.Error("SpoolMessage() with no spool directory succeeded, want an error")
+	}
+	if files, _ := filepath.Glob(filepath.Join(spool, "*")); len(files) != 1 || filepath.Base(files[0]) != name {
+		t.Errorf("spool directory holds %v, want only %s", files, name)
+	}
+	ProcessSpool(spool)
+	if files, _ := filepath.Glob(filepath.Join(spool, "*")); len(files) != 0 {
+		t.Errorf("spool directory holds %v after processing, want none", files)
+	}
+	messages := captured.Search("")
+	if len(messages) != 1 || messages[0].Listener != "local" || !strings.HasPrefix(string(messages[0].Data), "Received: by ") {
+		t.Fatalf("captured %+v, want the spooled message with a Received header", messages)
+	}
+
+	// With mailrouter running, the message is submitted over the socket.
+	srv := &smtpd.Server{Name: "local", Socket: socket, SocketMode: 0600, Hostname: "mx.test", Handler: mailHandler}
+	ln, err := srv.Listen()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.Serve(ln)
+	t.Cleanup(func() { ln.Close() })
+	if err := SubmitMessage("unix", socket, "host", "cron@example.com", []string{"root@example.com"}, data); err != nil {
+		t.Fatalf("SubmitMessage() = %v", err)
+	}
+	if n := len(captured.Search("")); n != 2 {
+		t.Errorf("captured %d messages, want 2", n)
+	}
+}
+
+// Use listeners for the duration of a test.
+func useTestListeners(t *testing.T, listeners ...Listener) {
+	saved := config.Listeners
+	config.Listeners = listeners
+	t.Cleanup(func() { config.Listeners = saved })
+}
+
+func TestProcessSpoolChecks(t *testing.T) {
+	useTestCapture(t)
+	useTestRoutes(t,
+		Route{Id: "DROP", Name: "Drop"},
+		Route{Id: "local", Name: "Local", Type: RouteCapture, IsDefault: true},
+	)
+	useTestOptions(t, map[string]string{"RecipientDomains": "example.com"})
+	savedFilters := config.Filters
+	config.Filters = map[string]Filter{
+		"1": {Id: "1", Name: "Owner", Order: 1, Origin: fmt.Sprintf("uid:%d", os.Getuid()), To: "blocked@example.com", Action: ActionReject},
+	}
+	t.Cleanup(func() { config.Filters = savedFilters })
+	dir := t.TempDir()
+	spool := filepath.Join(dir, "spool")
+	if err := CreateSpoolDirectory(spool); err != nil {
+		t.Fatal(err)
+	}
+	spoolMessage := func(to ...string) {
+		data := []byte("Subject: Cron\r\n\r\nDone.\r\n")
+		if _, err := SpoolMessage(spool, SpooledMessage{From: "cron@example.com", To: to, Data: data}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Recipients the listener would refuse are removed, including one refused by a filter
+	// matching the owner of the spool file. A link, which could point at a file owned by
+	// someone else, is not read.
+	useTestListeners(t, Listener{Name: "local", Socket: filepath.Join(dir, "smtp.sock")})
+	spoolMessage("root@example.com", "user@elsewhere.org", "blocked@example.com")
+	spoolMessage("user@elsewhere.org")
+	ioutil.WriteFile(filepath.Join(dir, "other.json"), []byte(`{"From": "a@example.com", "To": ["root@example.com"]}`), 0644)
+	os.Symlink(filepath.Join(dir, "other.json"), filepath.Join(spool, "link"+SpoolExt))
+	ProcessSpool(spool)
+	if files, _ := filepath.Glob(filepath.Join(spool, "*")); len(files) != 0 {
+		t.Errorf("spool directory holds %v after processing, want none", files)
+	}
+	messages := captured.Search("")
+	if len(messages) != 1 || strings.Join(messages[0].To, ",") != "root@example.com" {
+		t.Errorf("captured %+v, want one message to root@example.com", messages)
+	}
+
+	// Spooled mail can't authenticate, so a listener requiring it rejects the message.
+	useTestListeners(t, Listener{Name: "local", Socket: filepath.Join(dir, "smtp.sock"), Users: map[string]string{"app": "secret"}, RequireAuth: true})
+	spoolMessage("root@example.com")
+	ProcessSpool(spool)
+	if files, _ := filepath.Glob(filepath.Join(spool, "*")); len(files) != 0 {
+		t.Errorf("spool directory holds %v after processing, want none", files)
+	}
+	if n := len(captured.Search("")); n != 1 {
+		t.Errorf("captured %d messages, want the message for a listener requiring authentication rejected", n)
+	}
+}
+
+func TestListenerLocalAddress(t *testing.T) {
+	tests := []struct {
+		l       Listener
+		network string
+		addr    string
+	}{
+		{Listener{Addr: ":2525"}, "tcp", "127.0.0.1:2525"},
+		{Listener{Addr: "0.0.0.0:25"}, "tcp", "127.0.0.1:25"},
+		{Listener{Addr: "[::]:25"}, "tcp", "127.0.0.1:25"},
+		{Listener{Addr: "192.0.2.1:25"}, "tcp", "192.0.2.1:25"},
+		{Listener{Addr: ":2525", Socket: "/run/mailrouter.sock"}, "unix", "/run/mailrouter.sock"},
+	}
+	for _, tt := range tests {
+		if network, addr := tt.l.LocalAddress(); network != tt.network || addr != tt.addr {
+			t.Errorf("Listener{%s %s}.LocalAddress() = %s %s, want %s %s", tt.l.Addr, tt.l.Socket, network, addr, tt.network, tt.addr)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package smtpd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Conditions for the NOTIFY parameter of RCPT, from RFC 3461 section 4.1.
+const (
+	NotifyNever   = "NEVER"
+	NotifySuccess = "SUCCESS"
+	NotifyFailure = "FAILURE"
+	NotifyDelay   = "DELAY"
+)
+
+// Values for the RET parameter of MAIL, from RFC 3461 section 4.3.
+const (
+	RetFull    = "FULL"
+	RetHeaders = "HDRS"
+)
+
+// Maximum length of an ENVID parameter, from RFC 3461 section 4.4.
+const MaxEnvIdLength = 100
+
+// Parameters given with the DSN extension (RFC 3461), asking for delivery status notifications.
+type DSNParams struct {
+	Ret    string            // FULL or HDRS: how much of the message to return if delivery fails
+	EnvId  string            // Envelope identifier to include in notifications
+	Notify map[string]string // NOTIFY parameter of each recipient that gave one, e.g. "SUCCESS,FAILURE"
+	ORcpt  map[string]string // Original recipient of each recipient that gave one, e.g. "rfc822;user@example.com"
+}
+
+// Parse a NOTIFY parameter, which is NEVER or a list of SUCCESS, FAILURE and DELAY.
+// Returns the value in upper case.
+func ParseNotify(value string) (string, bool) {
+	value = strings.ToUpper(value)
+	if value == NotifyNever {
+		return value, true
+	}
+	for _, c := range strings.Split(value, ",") {
+		if c != NotifySuccess && c != NotifyFailure && c != NotifyDelay {
+			return "", false
+		}
+	}
+	return value, true
+}
+
+// Parse an ORCPT parameter such as "rfc822;user+2Bfolder@example.com", decoding the address.
+func ParseORcpt(value string) (string, bool) {
+	i := strings.IndexByte(value, ';')
+	if i <= 0 {
+		return "", false
+	}
+	addr, err := DecodeXtext(value[i+1:])
+	if err != nil || addr == "" {
+		return "", false
+	}
+	return value[:i] + ";" + addr, true
+}
+
+// Decode xtext (RFC 3461 section 4), in which "+" and a two digit hex code stands for a character.
+func DecodeXtext(s string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < '!' || c > '~' || c == '=' {
+			return "", fmt.Errorf("invalid character %q in xtext", c)
+		}
+		if c != '+' {
+			b.WriteByte(c)
+			continue
+		}
+		if i+2 >= len(s) {
+			return "", fmt.Errorf("incomplete hex code in xtext")
+		}
+		n, err := strconv.ParseUint(s[i+1:i+3], 16, 8)
+		if err != nil || strings.ToUpper(s[i+1:i+3]) != s[i+1:i+3] {
+			return "", fmt.Errorf("invalid hex code %q in xtext", s[i+1:i+3])
+		}
+		b.WriteByte(byte(n))
+		i += 2
+	}
+	return b.String(), nil
+}
+
+// Encode a string as xtext, replacing "+", "=" and characters outside printable ASCII with hex codes.
+func EncodeXtext(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < '!' || c > '~' || c == '+' || c == '=' {
+			fmt.Fprintf(&b, "+%02X", c)
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
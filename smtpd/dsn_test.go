@@ -0,0 +1,28 @@
+package smtpd
+
+import "testing"
+
+func TestXtext(t *testing.T) {
+	tests := []struct {
+		decoded string
+		encoded string
+	}{
+		{"user@example.com", "user@example.com"},
+		{"user+box@example.com", "user+2Bbox@example.com"},
+		{"a=b c", "a+3Db+20c"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if out := EncodeXtext(tt.decoded); out != tt.encoded {
+			t.Errorf("EncodeXtext(%q) = %q, want %q", tt.decoded, out, tt.encoded)
+		}
+		if out, err := DecodeXtext(tt.encoded); err != nil || out != tt.decoded {
+			t.Errorf("DecodeXtext(%q) = %q, %v, want %q", tt.encoded, out, err, tt.decoded)
+		}
+	}
+	for _, bad := range []string{"a+2", "a+zz", "a+2b", "a=b", "a b"} {
+		if _, err := DecodeXtext(bad); err == nil {
+			t.Errorf("DecodeXtext(%q) succeeded, want error", bad)
+		}
+	}
+}
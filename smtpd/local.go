@@ -0,0 +1,70 @@
+package smtpd
+
+import (
+	"fmt"
+	"net"
+	"os"
+)
+
+// The origin of a client connected over a Unix socket, with the credentials of its process if
+// the platform reports them.
+type LocalAddr struct {
+	Path  string // Path of the socket
+	Uid   int
+	Gid   int
+	Pid   int
+	Known bool // Set if Uid, Gid and Pid were read from the socket
+}
+
+func (a *LocalAddr) Network() string {
+	return "unix"
+}
+
+func (a *LocalAddr) String() string {
+	if !a.Known {
+		return "local"
+	}
+	return fmt.Sprintf("local uid=%d gid=%d", a.Uid, a.Gid)
+}
+
+// Return the origin of a client connected over a Unix socket.
+func localOrigin(conn *net.UnixConn, path string) *LocalAddr {
+	addr := &LocalAddr{Path: path}
+	uid, gid, pid, err := peerCredentials(conn)
+	if err == nil {
+		addr.Uid, addr.Gid, addr.Pid, addr.Known = uid, gid, pid, true
+	}
+	return addr
+}
+
+// Return the IP address of an origin, or nil if it is not an IP address, such as a local client.
+func OriginIP(origin net.Addr) net.IP {
+	switch addr := origin.(type) {
+	case *net.TCPAddr:
+		return addr.IP
+	case *LocalAddr, *net.UnixAddr, nil:
+		return nil
+	}
+	host, _, err := net.SplitHostPort(origin.String())
+	if err != nil {
+		return nil
+	}
+	return net.ParseIP(host)
+}
+
+// Listen on a Unix socket, giving it the permissions mode. A socket left behind by an earlier
+// run is removed first, but any other kind of file at the path is left alone.
+func listenUnix(path string, mode os.FileMode) (net.Listener, error) {
+	if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		os.Remove(path)
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		ln.Close()
+		return nil, err
+	}
+	return ln, nil
+}
@@ -0,0 +1,29 @@
+//go:build linux
+// +build linux
+
+package smtpd
+
+import (
+	"net"
+	"syscall"
+)
+
+// Read the user, group and process IDs of the process at the other end of a Unix socket.
+func peerCredentials(conn *net.UnixConn) (int, int, int, error) {
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	var cred *syscall.Ucred
+	var credErr error
+	err = raw.Control(func(fd uintptr) {
+		cred, credErr = syscall.GetsockoptUcred(int(fd), syscall.SOL_SOCKET, syscall.SO_PEERCRED)
+	})
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	if credErr != nil {
+		return 0, 0, 0, credErr
+	}
+	return int(cred.Uid), int(cred.Gid), int(cred.Pid), nil
+}
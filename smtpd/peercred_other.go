@@ -0,0 +1,14 @@
+//go:build !linux
+// +build !linux
+
+package smtpd
+
+import (
+	"errors"
+	"net"
+)
+
+// SO_PEERCRED is only available on Linux, so local clients elsewhere are not identified.
+func peerCredentials(conn *net.UnixConn) (int, int, int, error) {
+	return 0, 0, 0, errors.New("peer credentials are not supported on this platform")
+}
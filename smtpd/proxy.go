@@ -0,0 +1,126 @@
+package smtpd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// Signature at the start of a version 2 PROXY protocol header.
+var proxyV2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+// Length of the longest version 1 PROXY protocol header, including CRLF.
+const proxyV1MaxLength = 107
+
+// Read a PROXY protocol header, version 1 or 2, from a connection accepted from a proxy such as
+// HAProxy, and return the address of the client the proxy accepted its connection from.
+// Returns nil if the header gives no client address, as for the proxy's own health checks.
+func ReadProxyHeader(br *bufio.Reader) (net.Addr, error) {
+	start, err := br.Peek(len(proxyV2Signature))
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Equal(start, proxyV2Signature) {
+		return readProxyV2(br)
+	}
+	if bytes.HasPrefix(start, []byte("PROXY ")) {
+		return readProxyV1(br)
+	}
+	return nil, errors.New("no PROXY protocol header")
+}
+
+// Read a version 1 header, e.g. "PROXY TCP4 192.0.2.1 198.51.100.1 56324 25".
+func readProxyV1(br *bufio.Reader) (net.Addr, error) {
+	var line []byte
+	for len(line) < proxyV1MaxLength && !bytes.HasSuffix(line, []byte("\r\n")) {
+		b, err := br.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		line = append(line, b)
+	}
+	if !bytes.HasSuffix(line, []byte("\r\n")) {
+		return nil, errors.New("PROXY header too long")
+	}
+	fields := strings.Split(string(line[:len(line)-2]), " ")
+	if len(fields) >= 2 && fields[1] == "UNKNOWN" {
+		return nil, nil
+	}
+	if len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6") {
+		return nil, fmt.Errorf("invalid PROXY header %q", line)
+	}
+	ip := net.ParseIP(fields[2])
+	port, err := strconv.ParseUint(fields[4], 10, 16)
+	if ip == nil || err != nil || (fields[1] == "TCP4") != (ip.To4() != nil) {
+		return nil, fmt.Errorf("invalid PROXY header %q", line)
+	}
+	return &net.TCPAddr{IP: ip, Port: int(port)}, nil
+}
+
+// Read a version 2 header: the signature, version and command, address family, the length of
+// the rest of the header, and then the addresses and any TLVs, which are ignored.
+func readProxyV2(br *bufio.Reader) (net.Addr, error) {
+	header := make([]byte, 16)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return nil, err
+	}
+	if header[12]>>4 != 2 {
+		return nil, fmt.Errorf("unsupported PROXY protocol version %d", header[12]>>4)
+	}
+	payload := make([]byte, binary.BigEndian.Uint16(header[14:]))
+	if _, err := io.ReadFull(br, payload); err != nil {
+		return nil, err
+	}
+	switch header[12] & 0x0f {
+	case 0x0: // LOCAL
+		return nil, nil
+	case 0x1: // PROXY
+	default:
+		return nil, fmt.Errorf("unsupported PROXY command %d", header[12]&0x0f)
+	}
+	switch header[13] {
+	case 0x11: // TCP over IPv4
+		if len(payload) < 12 {
+			return nil, errors.New("PROXY header too short")
+		}
+		return &net.TCPAddr{IP: net.IP(payload[0:4]), Port: int(binary.BigEndian.Uint16(payload[8:]))}, nil
+	case 0x21: // TCP over IPv6
+		if len(payload) < 36 {
+			return nil, errors.New("PROXY header too short")
+		}
+		return &net.TCPAddr{IP: net.IP(payload[0:16]), Port: int(binary.BigEndian.Uint16(payload[32:]))}, nil
+	}
+	// Other families, such as UDP or Unix sockets, have no client address to use.
+	return nil, nil
+}
+
+// Parse the attributes of an XCLIENT command, e.g. "ADDR=192.0.2.1 PORT=56324", keyed in upper
+// case with their xtext decoded. Values of [UNAVAILABLE] or [TEMPUNAVAIL] are returned as empty.
+func ParseXClient(args string) (map[string]string, error) {
+	attrs := map[string]string{}
+	for _, field := range strings.Fields(args) {
+		i := strings.IndexByte(field, '=')
+		if i < 0 {
+			return nil, fmt.Errorf("invalid XCLIENT attribute %q", field)
+		}
+		name := strings.ToUpper(field[:i])
+		value, err := DecodeXtext(field[i+1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid XCLIENT %s value", name)
+		}
+		if value == "[UNAVAILABLE]" || value == "[TEMPUNAVAIL]" {
+			value = ""
+		}
+		attrs[name] = value
+	}
+	if len(attrs) == 0 {
+		return nil, errors.New("no XCLIENT attributes")
+	}
+	return attrs, nil
+}
@@ -0,0 +1,206 @@
+package smtpd
+
+import (
+	"bufio"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+)
+
+func TestReadProxyHeader(t *testing.T) {
+	v2 := func(command byte, family byte, payload string) string {
+		return string(proxyV2Signature) + string([]byte{0x20 | command, family, 0, byte(len(payload))}) + payload
+	}
+	tests := []struct {
+		header string
+		want   string
+		err    bool
+	}{
+		{"PROXY TCP4 192.0.2.1 198.51.100.1 56324 25\r\n", "192.0.2.1:56324", false},
+		{"PROXY TCP6 2001:db8::1 2001:db8::2 56324 25\r\n", "[2001:db8::1]:56324", false},
+		{"PROXY UNKNOWN\r\n", "", false},
+		{"PROXY TCP4 2001:db8::1 198.51.100.1 56324 25\r\n", "", true},
+		{"PROXY TCP4 192.0.2.1 198.51.100.1 99999 25\r\n", "", true},
+		{"PROXY TCP4 192.0.2.1 198.51.100.1 56324 25" + strings.Repeat(" ", 100) + "\r\n", "", true},
+		{"EHLO client.example.com\r\n", "", true},
+		{v2(1, 0x11, "\xc0\x00\x02\x01\xc6\x33\x64\x01\xdc\x04\x00\x19"), "192.0.2.1:56324", false},
+		{v2(1, 0x11, "\xc0\x00\x02\x01\xc6\x33\x64\x01\xdc\x04\x00\x19\x04\x00\x01x"), "192.0.2.1:56324", false},
+		{v2(1, 0x21, "\x20\x01\x0d\xb8"+strings.Repeat("\x00", 11)+"\x01\x20\x01\x0d\xb8"+strings.Repeat("\x00", 11)+"\x02\xdc\x04\x00\x19"), "[2001:db8::1]:56324", false},
+		{v2(0, 0x00, ""), "", false},
+		{v2(1, 0x11, "\xc0\x00\x02\x01"), "", true},
+		{v2(2, 0x11, "\xc0\x00\x02\x01\xc6\x33\x64\x01\xdc\x04\x00\x19"), "", true},
+	}
+	for _, tt := range tests {
+		br := bufio.NewReader(strings.NewReader(tt.header + "EHLO client.example.com\r\n"))
+		addr, err := ReadProxyHeader(br)
+		got := ""
+		if addr != nil {
+			got = addr.String()
+		}
+		if (err != nil) != tt.err || got != tt.want {
+			t.Errorf("ReadProxyHeader(%q) = %s, %v, want %q", tt.header, got, err, tt.want)
+			continue
+		}
+		if rest, _ := br.ReadString('\n'); err == nil && rest != "EHLO client.example.com\r\n" {
+			t.Errorf("ReadProxyHeader(%q) left %q unread, want the EHLO command", tt.header, rest)
+		}
+	}
+}
+
+func TestParseXClient(t *testing.T) {
+	tests := []struct {
+		args string
+		want map[string]string
+	}{
+		{"ADDR=192.0.2.1 port=56324", map[string]string{"ADDR": "192.0.2.1", "PORT": "56324"}},
+		{"NAME=[UNAVAILABLE] ADDR=IPV6:2001:db8::1", map[string]string{"NAME": "", "ADDR": "IPV6:2001:db8::1"}},
+		{"NAME=app+2Eexample.com", map[string]string{"NAME": "app.example.com"}},
+		{"ADDR", nil},
+		{"NAME=bad+zz", nil},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		got, err := ParseXClient(tt.args)
+		if (err != nil) != (tt.want == nil) || len(got) != len(tt.want) {
+			t.Errorf("ParseXClient(%q) = %v, %v, want %v", tt.args, got, err, tt.want)
+			continue
+		}
+		for name, value := range tt.want {
+			if got[name] != value {
+				t.Errorf("ParseXClient(%q)[%s] = %q, want %q", tt.args, name, got[name], value)
+			}
+		}
+	}
+}
+
+// Start a server that records the origin and data of each message, returning its address and
+// a function that returns the last origin and data.
+func startOriginServer(t *testing.T, srv *Server) (string, func() (net.Addr, string)) {
+	var origin net.Addr
+	var data []byte
+	srv.Hostname = "mx.test"
+	srv.Handler = func(o net.Addr, env Envelope, d []byte) error {
+		origin, data = o, d
+		return nil
+	}
+	return serveTest(t, srv), func() (net.Addr, string) { return origin, string(data) }
+}
+
+// Send a message over a connection that has been greeted.
+func sendTestMessage(t *testing.T, text *textproto.Conn) {
+	for _, cmd := range []string{"EHLO app.example.com", "MAIL FROM:<app@example.com>", "RCPT TO:<user@example.com>", "DATA"} {
+		text.PrintfLine("%s", cmd)
+		if code, msg, _ := text.ReadResponse(0); code >= 400 {
+			t.Fatalf("%s = %d %s", cmd, code, msg)
+		}
+	}
+	text.PrintfLine("Subject: test\r\n\r\ntest\r\n.")
+	if code, msg, _ := text.ReadResponse(0); code != 250 {
+		t.Fatalf("DATA = %d %s", code, msg)
+	}
+}
+
+func TestServerProxyProtocol(t *testing.T) {
+	tests := []struct {
+		trusted []string
+		header  string
+		want    string
+	}{
+		{[]string{"127.0.0.0/8"}, "PROXY TCP4 192.0.2.1 127.0.0.1 56324 25\r\n", "192.0.2.1:56324"},
+		{[]string{"127.0.0.0/8"}, "PROXY UNKNOWN\r\n", "127.0.0.1"},
+		{[]string{"192.0.2.0/24"}, "", "127.0.0.1"},
+	}
+	for _, tt := range tests {
+		addr, last := startOriginServer(t, &Server{TrustedProxies: testNetworks(t, tt.trusted...), ProxyProtocol: true})
+		conn, err := net.Dial("tcp", addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn.Write([]byte(tt.header))
+		text := textproto.NewConn(conn)
+		text.ReadResponse(220)
+		sendTestMessage(t, text)
+		conn.Close()
+
+		origin, data := last()
+		if got := origin.String(); got != tt.want && OriginIP(origin).String() != tt.want {
+			t.Errorf("origin with trusted proxies %v and header %q = %s, want %s", tt.trusted, tt.header, got, tt.want)
+		}
+		if want := "([" + OriginIP(origin).String() + "])"; !strings.Contains(data, want) {
+			t.Errorf("Received header = %q, want it to contain %s", data, want)
+		}
+	}
+}
+
+func TestServerProxyProtocolDenied(t *testing.T) {
+	srv := &Server{TrustedProxies: testNetworks(t, "127.0.0.1"), ProxyProtocol: true, DenyClients: testNetworks(t, "192.0.2.0/24")}
+	addr, _ := startOriginServer(t, srv)
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.Write([]byte("PROXY TCP4 192.0.2.1 127.0.0.1 56324 25\r\n"))
+	if code, _, _ := textproto.NewConn(conn).ReadResponse(0); code != 554 {
+		t.Errorf("greeting for a denied client behind a proxy = %d, want 554", code)
+	}
+}
+
+func TestServerXClient(t *testing.T) {
+	for _, trusted := range []bool{true, false} {
+		srv := &Server{TrustedProxies: testNetworks(t, "192.0.2.0/24"), XClient: true}
+		if trusted {
+			srv.TrustedProxies = testNetworks(t, "127.0.0.1")
+		}
+		addr, last := startOriginServer(t, srv)
+		conn, err := net.Dial("tcp", addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		text := textproto.NewConn(conn)
+		text.ReadResponse(220)
+		text.PrintfLine("EHLO proxy.example.com")
+		if _, msg, _ := text.ReadResponse(250); strings.Contains(msg, "XCLIENT") != trusted {
+			t.Errorf("EHLO from a trusted (%v) client = %q, want XCLIENT advertised only to trusted proxies", trusted, msg)
+		}
+
+		tests := []struct {
+			cmd  string
+			code int
+		}{
+			{"XCLIENT ADDR=192.0.2.300", 501},
+			{"XCLIENT PROTO=ESMTP", 501},
+			{"XCLIENT ADDR=IPV6:2001:db8::1 PORT=56324 NAME=app+2Eexample.com", 220},
+			{"MAIL FROM:<app@example.com>", 503},
+			{"XCLIENT ADDR=192.0.2.1", 550},
+		}
+		if !trusted {
+			tests = []struct {
+				cmd  string
+				code int
+			}{{"XCLIENT ADDR=192.0.2.1", 550}}
+		}
+		for _, tt := range tests {
+			text.PrintfLine("%s", tt.cmd)
+			if code, msg, _ := text.ReadResponse(0); code != tt.code {
+				t.Errorf("%s from a trusted (%v) client = %d %s, want %d", tt.cmd, trusted, code, msg, tt.code)
+			}
+		}
+		if trusted {
+			text.PrintfLine("EHLO app.example.com")
+			if _, msg, _ := text.ReadResponse(250); strings.Contains(msg, "XCLIENT") {
+				t.Errorf("EHLO after XCLIENT = %q, want XCLIENT not advertised to the relayed client", msg)
+			}
+			sendTestMessage(t, text)
+			origin, data := last()
+			if origin.String() != "[2001:db8::1]:56324" {
+				t.Errorf("origin after XCLIENT = %s, want [2001:db8::1]:56324", origin)
+			}
+			if !strings.Contains(data, "(app.example.com [2001:db8::1])") {
+				t.Errorf("Received header = %q, want the name and address given with XCLIENT", data)
+			}
+		}
+		conn.Close()
+	}
+}
@@ -0,0 +1,953 @@
+// Package smtpd is the SMTP server that receives mail for Mailrouter. It replaces the
+// github.com/mhale/smtpd package Mailrouter used before, whose handler is called after the
+// reply to DATA has been sent and whose recipient checks can't choose the reply, so mail could
+// not be refused during the session. Nor does it accept the DSN parameters of RFC 3461,
+// client addresses from proxies with XCLIENT or the PROXY protocol, or clients on Unix sockets.
+//
+// The server implements the protocol. Which clients, senders and recipients are accepted is
+// up to the program using it, through the fields and handlers of the Server.
+package smtpd
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limits applied to incoming SMTP sessions.
+const (
+	MaxMessageSize = 25 * 1024 * 1024 // Bytes
+	MaxRecipients  = 100
+	MaxLineLength  = 1000 // Including CRLF, from RFC 5321 section 4.5.3.1.6
+	MaxBadCommands = 10
+	SessionTimeout = 5 * time.Minute // Idle time allowed between commands
+)
+
+// Handler for a message received by the SMTP server. It is called before the reply to DATA is
+// sent, so returning an error rejects the message. An *SMTPError is sent to the client as is.
+type Handler func(origin net.Addr, env Envelope, data []byte) error
+
+// Handler for a recipient given with RCPT TO, with the envelope of the recipients accepted so far.
+// Returning an error rejects the recipient before any message data is sent. An *SMTPError is
+// sent to the client as is.
+type RcptHandler func(origin net.Addr, env Envelope, to string) error
+
+// The envelope of a message received by the SMTP server.
+type Envelope struct {
+	From     string // Empty for the null sender
+	To       []string
+	DSN      DSNParams
+	Listener string // Name of the server that received the message
+	Client   ClientInfo
+}
+
+// Details of the client that sent a message, beyond its origin.
+type ClientInfo struct {
+	Name  string // Hostname of the client, if known
+	Helo  string // Name the client gave with HELO or EHLO
+	Proto string // SMTP or ESMTP, depending on the greeting the client used
+}
+
+// An SMTP reply rejecting a command, such as "550 5.7.1 Relaying denied".
+type SMTPError struct {
+	Code    int
+	Message string // Reply text, which may start with an enhanced status code
+}
+
+func (e *SMTPError) Error() string {
+	return fmt.Sprintf("%d %s", e.Code, e.Message)
+}
+
+// Parse a reply such as "550 5.7.1 Outbound mail disabled". The code must be 4xx or 5xx.
+// If the reply has no code, defaultCode is used.
+func ParseReply(reply string, defaultCode int) (*SMTPError, error) {
+	reply = strings.TrimSpace(reply)
+	code, text := defaultCode, reply
+	if len(reply) >= 3 {
+		if n, err := strconv.Atoi(reply[:3]); err == nil && (len(reply) == 3 || reply[3] == ' ') {
+			code, text = n, strings.TrimSpace(reply[3:])
+		}
+	}
+	if code < 400 || code > 599 {
+		return nil, fmt.Errorf("reply code %d is not a 4xx or 5xx code", code)
+	}
+	if strings.ContainsAny(text, "\r\n") {
+		return nil, fmt.Errorf("reply text must be a single line")
+	}
+	if text == "" {
+		text = "Message rejected"
+		if code >= 500 {
+			text = "5.7.1 " + text
+		} else {
+			text = "4.7.1 " + text
+		}
+	}
+	return &SMTPError{Code: code, Message: text}, nil
+}
+
+// Limits on the connections and mail accepted from each client IP address. A returned error
+// refuses the connection or message, and an *SMTPError is sent to the client as is.
+type Limiter interface {
+	Connect(ip net.IP, now time.Time) error     // A client connected
+	Disconnect(ip net.IP)                       // A connection admitted by Connect closed
+	Message(ip net.IP, now time.Time) error     // A client gave MAIL
+	TooManyRecipients(ip net.IP, now time.Time) // A client gave more recipients than allowed
+}
+
+// An SMTP server that receives mail and passes it to a Handler.
+type Server struct {
+	Name             string      // Name of the listener, passed to handlers in the envelope
+	Addr             string      // TCP address to listen on
+	Socket           string      // Unix socket to listen on instead of Addr
+	SocketMode       os.FileMode // Permissions of the socket
+	Handler          Handler
+	RcptHandler      RcptHandler
+	Appname          string
+	Hostname         string
+	RecipientDomains []string     // Domains that recipients must belong to. Empty allows any domain.
+	MaxRecipients    int          // Recipients accepted per message. Zero uses the MaxRecipients default.
+	Throttle         Limiter      // Limits on connections and mail from each client. Nil means no limits.
+	AllowClients     []*net.IPNet // Networks that may connect. Empty allows any client.
+	DenyClients      []*net.IPNet // Networks that may not connect, even if allowed
+
+	// Proxies that may give the address of the client they are relaying for, so the origin
+	// is the real client rather than the proxy.
+	TrustedProxies []*net.IPNet // Networks of the proxies
+	ProxyProtocol  bool         // Read a PROXY protocol header from connections from trusted proxies
+	XClient        bool         // Offer XCLIENT to trusted proxies
+
+	TLSConfig   *tls.Config                          // Offers STARTTLS if set
+	RequireTLS  bool                                 // Refuse MAIL until the client has started TLS
+	Auth        func(username, password string) bool // Offers AUTH PLAIN and LOGIN if set
+	RequireAuth bool                                 // Refuse MAIL until the client has authenticated
+
+	mu   sync.Mutex
+	next *Server // Settings for new connections after Replace
+}
+
+// Listen on the TCP address addr and pass received mail to handler.
+func ListenAndServe(addr string, handler Handler, appname string, hostname string) error {
+	srv := &Server{Addr: addr, Handler: handler, Appname: appname, Hostname: hostname}
+	return srv.ListenAndServe()
+}
+
+// Listen on the server's TCP address or Unix socket and serve SMTP sessions until an error occurs.
+func (srv *Server) ListenAndServe() error {
+	ln, err := srv.Listen()
+	if err != nil {
+		return err
+	}
+	return srv.Serve(ln)
+}
+
+// Listen on the server's TCP address or Unix socket.
+func (srv *Server) Listen() (net.Listener, error) {
+	if srv.Socket != "" {
+		return listenUnix(srv.Socket, srv.SocketMode)
+	}
+	if srv.Addr == "" {
+		srv.Addr = ":25"
+	}
+	return net.Listen("tcp", srv.Addr)
+}
+
+// Fill in the hostname and application name if they are not set.
+func (srv *Server) setDefaults() {
+	if srv.Hostname == "" {
+		srv.Hostname, _ = os.Hostname()
+	}
+	if srv.Appname == "" {
+		srv.Appname = "smtpd"
+	}
+}
+
+// Use the settings of next for connections accepted from now on, such as after the
+// configuration is reloaded. Sessions in progress keep the settings they started with.
+func (srv *Server) Replace(next *Server) {
+	next.setDefaults()
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	srv.next = next
+}
+
+// Return the server whose settings apply to a new connection.
+func (srv *Server) current() *Server {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if srv.next != nil {
+		return srv.next
+	}
+	return srv
+}
+
+// Serve SMTP sessions on connections accepted from a listener.
+func (srv *Server) Serve(ln net.Listener) error {
+	defer ln.Close()
+	srv.setDefaults()
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				time.Sleep(100 * time.Millisecond)
+				continue
+			}
+			return err
+		}
+		s := &session{srv: srv.current(), conn: conn, origin: conn.RemoteAddr(), br: bufio.NewReader(conn), bw: bufio.NewWriter(conn)}
+		if unixConn, ok := conn.(*net.UnixConn); ok {
+			s.origin = localOrigin(unixConn, ln.Addr().String())
+		}
+		s.ip = OriginIP(s.origin)
+		s.trusted = s.srv.trustedProxy(s.ip)
+		go s.serve()
+	}
+}
+
+// The state of a single SMTP connection.
+type session struct {
+	srv    *Server
+	conn   net.Conn
+	origin net.Addr // Client address passed to handlers, a *LocalAddr for Unix sockets
+	ip     net.IP   // Nil if the client is not connected over IP
+	name   string   // Hostname of the client if a proxy gave it with XCLIENT
+	br     *bufio.Reader
+	bw     *bufio.Writer
+
+	trusted   bool // Set if the client is a trusted proxy that may give the origin
+	throttled bool // Set while the client's connection counts against its throttle limits
+
+	helo  string
+	esmtp bool    // Set if the client greeted with EHLO
+	tls   bool    // Set once STARTTLS has succeeded
+	user  string  // Set once AUTH has succeeded
+	from  *string // Nil until MAIL has been accepted. The null sender is an empty string.
+	to    []string
+	dsn   DSNParams
+}
+
+func (s *session) serve() {
+	defer s.conn.Close()
+	if s.trusted && s.srv.ProxyProtocol && !s.proxyHeader() {
+		return
+	}
+	defer s.release()
+	if !s.admit() {
+		return
+	}
+	s.reply(220, "%s %s ESMTP Service ready", s.srv.Hostname, s.srv.Appname)
+
+	bad := 0
+	for {
+		line, err := s.readLine()
+		if err == errLineTooLong {
+			s.reply(500, "5.5.2 Line too long")
+			continue
+		}
+		if err != nil {
+			return
+		}
+		verb, args := line, ""
+		if i := strings.IndexByte(line, ' '); i >= 0 {
+			verb, args = line[:i], strings.TrimSpace(line[i+1:])
+		}
+
+		switch strings.ToUpper(verb) {
+		case "HELO":
+			s.hello(args, false)
+		case "EHLO":
+			s.hello(args, true)
+		case "STARTTLS":
+			if !s.startTLS(args) {
+				return
+			}
+		case "AUTH":
+			if !s.auth(args) {
+				return
+			}
+		case "XCLIENT":
+			if !s.xclient(args) {
+				return
+			}
+		case "MAIL":
+			s.mail(args)
+		case "RCPT":
+			s.rcpt(args)
+		case "DATA":
+			if !s.data() {
+				return
+			}
+		case "RSET":
+			s.reset()
+			s.reply(250, "2.0.0 Ok")
+		case "NOOP":
+			s.reply(250, "2.0.0 Ok")
+		case "VRFY":
+			s.reply(252, "2.5.0 Cannot VRFY user, but will accept message and attempt delivery")
+		case "QUIT":
+			s.reply(221, "2.0.0 %s %s closing connection", s.srv.Hostname, s.srv.Appname)
+			return
+		default:
+			s.reply(500, "5.5.2 Syntax error, command unrecognised")
+			bad++
+			if bad >= MaxBadCommands {
+				s.reply(421, "4.7.0 %s Too many unrecognised commands", s.srv.Hostname)
+				return
+			}
+		}
+	}
+}
+
+// Check that the client may connect and is within its throttle limits, refusing it if not.
+// Returns false if the connection should be closed.
+func (s *session) admit() bool {
+	if s.ip != nil && !ClientAllowed(s.ip, s.srv.AllowClients, s.srv.DenyClients) {
+		log.Printf("Refused connection from %s: client not allowed", s.ip)
+		s.refuse()
+		return false
+	}
+	if s.srv.Throttle != nil && s.ip != nil {
+		if err := s.srv.Throttle.Connect(s.ip, time.Now()); err != nil {
+			s.replyError(err)
+			return false
+		}
+		s.throttled = true
+	}
+	return true
+}
+
+// Stop counting the connection against the client's throttle limits.
+func (s *session) release() {
+	if s.throttled {
+		s.srv.Throttle.Disconnect(s.ip)
+		s.throttled = false
+	}
+}
+
+// Report whether a client is one of the server's trusted proxies.
+func (srv *Server) trustedProxy(ip net.IP) bool {
+	return ip != nil && len(srv.TrustedProxies) > 0 && ClientAllowed(ip, srv.TrustedProxies, nil)
+}
+
+// Read the PROXY protocol header sent by a trusted proxy and make the client it gives the
+// origin. Returns false if the header is missing or invalid and the connection should be closed.
+func (s *session) proxyHeader() bool {
+	s.conn.SetReadDeadline(time.Now().Add(SessionTimeout))
+	addr, err := ReadProxyHeader(s.br)
+	if err != nil {
+		log.Printf("Closing connection from %s: %v", s.origin, err)
+		return false
+	}
+	if addr != nil {
+		s.origin, s.ip = addr, OriginIP(addr)
+		// The client behind the proxy is not trusted itself.
+		s.trusted = false
+	}
+	return true
+}
+
+// Greet a client that may not use the server with 554, then refuse its commands until it quits,
+// as RFC 5321 section 3.1 requires.
+func (s *session) refuse() {
+	s.reply(554, "5.7.1 %s No SMTP service for your address", s.srv.Hostname)
+	for bad := 0; bad < MaxBadCommands; bad++ {
+		line, err := s.readLine()
+		if err != nil && err != errLineTooLong {
+			return
+		}
+		if strings.EqualFold(strings.TrimSpace(line), "QUIT") {
+			s.reply(221, "2.0.0 %s %s closing connection", s.srv.Hostname, s.srv.Appname)
+			return
+		}
+		s.reply(503, "5.5.1 No SMTP service for your address")
+	}
+}
+
+func (s *session) hello(args string, extended bool) {
+	if args == "" {
+		verb := "HELO"
+		if extended {
+			verb = "EHLO"
+		}
+		s.reply(501, "5.5.4 Syntax: %s hostname", verb)
+		return
+	}
+	s.helo, s.esmtp = args, extended
+	s.reset()
+	if !extended {
+		s.reply(250, "%s greets %s", s.srv.Hostname, args)
+		return
+	}
+	extensions := []string{
+		fmt.Sprintf("%s greets %s", s.srv.Hostname, args),
+		fmt.Sprintf("SIZE %d", MaxMessageSize),
+		"8BITMIME",
+		"PIPELINING",
+		"ENHANCEDSTATUSCODES",
+		"DSN",
+	}
+	if s.srv.TLSConfig != nil && !s.tls {
+		extensions = append(extensions, "STARTTLS")
+	}
+	if s.authAllowed() {
+		extensions = append(extensions, "AUTH PLAIN LOGIN")
+	}
+	if s.srv.XClient && s.trusted {
+		extensions = append(extensions, "XCLIENT ADDR PORT NAME")
+	}
+	s.replyLines(250, extensions)
+}
+
+// Report whether AUTH may be used now. On a server with TLS, credentials are only accepted
+// once TLS has started, so they are never sent in the clear.
+func (s *session) authAllowed() bool {
+	return s.srv.Auth != nil && s.user == "" && (s.tls || s.srv.TLSConfig == nil)
+}
+
+// Start TLS (RFC 3207). Returns false if the connection should be closed.
+func (s *session) startTLS(args string) bool {
+	if s.srv.TLSConfig == nil {
+		s.reply(502, "5.5.1 Command not implemented")
+		return true
+	}
+	if s.tls {
+		s.reply(503, "5.5.1 TLS already started")
+		return true
+	}
+	if args != "" {
+		s.reply(501, "5.5.4 Syntax: STARTTLS")
+		return true
+	}
+	if s.br.Buffered() > 0 {
+		// Commands sent before TLS must not be treated as sent over it (RFC 3207 section 4.2).
+		log.Printf("Closing connection from %s: commands pipelined after STARTTLS", s.origin)
+		return false
+	}
+	s.reply(220, "2.0.0 Ready to start TLS")
+	conn := tls.Server(s.conn, s.srv.TLSConfig)
+	conn.SetDeadline(time.Now().Add(SessionTimeout))
+	if err := conn.Handshake(); err != nil {
+		log.Printf("TLS handshake with %s failed: %v", s.origin, err)
+		return false
+	}
+	conn.SetDeadline(time.Time{})
+	s.conn, s.br, s.bw = conn, bufio.NewReader(conn), bufio.NewWriter(conn)
+	s.tls = true
+
+	// Forget everything learned before TLS started, as the client must send EHLO again.
+	s.helo = ""
+	s.reset()
+	return true
+}
+
+// Authenticate the client with the PLAIN or LOGIN mechanism (RFC 4954).
+// Returns false if the connection should be closed.
+func (s *session) auth(args string) bool {
+	if s.srv.Auth == nil {
+		s.reply(502, "5.5.1 Command not implemented")
+		return true
+	}
+	if s.helo == "" {
+		s.reply(503, "5.5.1 Bad sequence of commands, send EHLO first")
+		return true
+	}
+	if s.user != "" {
+		s.reply(503, "5.5.1 Already authenticated")
+		return true
+	}
+	if s.from != nil {
+		s.reply(503, "5.5.1 AUTH not allowed during a mail transaction")
+		return true
+	}
+	if !s.authAllowed() {
+		s.reply(538, "5.7.11 Encryption required for requested authentication mechanism")
+		return true
+	}
+
+	mechanism, initial := args, ""
+	if i := strings.IndexByte(args, ' '); i >= 0 {
+		mechanism, initial = args[:i], strings.TrimSpace(args[i+1:])
+	}
+	var username, password string
+	switch strings.ToUpper(mechanism) {
+	case "PLAIN":
+		response, ok := s.authResponse("", initial)
+		if !ok {
+			return true
+		}
+		// The response is an authorization identity, username and password separated by NULs.
+		parts := strings.Split(response, "\x00")
+		if len(parts) != 3 {
+			s.reply(501, "5.5.2 Invalid PLAIN response")
+			return true
+		}
+		username, password = parts[1], parts[2]
+	case "LOGIN":
+		var ok bool
+		if username, ok = s.authResponse("Username:", initial); !ok {
+			return true
+		}
+		if password, ok = s.authResponse("Password:", ""); !ok {
+			return true
+		}
+	default:
+		s.reply(504, "5.5.4 Unrecognised authentication mechanism")
+		return true
+	}
+
+	if !s.srv.Auth(username, password) {
+		log.Printf("Authentication failed for %s from %s", username, s.origin)
+		s.reply(535, "5.7.8 Authentication credentials invalid")
+		return true
+	}
+	s.user = username
+	s.reply(235, "2.7.0 Authentication successful")
+	return true
+}
+
+// Replace the origin with the client a trusted proxy is relaying for (the Postfix XCLIENT
+// extension). The session starts again as if the client had connected itself, so it is
+// checked against the allow lists and throttle limits and must send EHLO again.
+// Returns false if the connection should be closed.
+func (s *session) xclient(args string) bool {
+	if !s.srv.XClient || !s.trusted {
+		s.reply(550, "5.7.0 Insufficient authorization")
+		return true
+	}
+	if s.from != nil {
+		s.reply(503, "5.5.1 XCLIENT not allowed during a mail transaction")
+		return true
+	}
+	attrs, err := ParseXClient(args)
+	if err != nil {
+		s.reply(501, "5.5.4 Syntax: XCLIENT attribute=value ...")
+		return true
+	}
+	ip, port := s.ip, 0
+	if addr, ok := s.origin.(*net.TCPAddr); ok {
+		port = addr.Port
+	}
+	for name, value := range attrs {
+		switch name {
+		case "ADDR":
+			if value == "" {
+				continue
+			}
+			if ip = net.ParseIP(strings.TrimPrefix(strings.ToUpper(value), "IPV6:")); ip == nil {
+				s.reply(501, "5.5.4 Invalid XCLIENT ADDR value")
+				return true
+			}
+		case "PORT":
+			if value == "" {
+				continue
+			}
+			n, err := strconv.ParseUint(value, 10, 16)
+			if err != nil {
+				s.reply(501, "5.5.4 Invalid XCLIENT PORT value")
+				return true
+			}
+			port = int(n)
+		case "NAME":
+			s.name = value
+		default:
+			s.reply(501, "5.5.4 Unsupported XCLIENT attribute %s", name)
+			return true
+		}
+	}
+
+	s.release()
+	s.origin, s.ip = &net.TCPAddr{IP: ip, Port: port}, ip
+	// The client the proxy relays for is not trusted itself, so can't send XCLIENT again.
+	s.trusted = false
+	s.helo, s.user = "", ""
+	s.reset()
+	if !s.admit() {
+		return false
+	}
+	s.reply(220, "%s %s ESMTP Service ready", s.srv.Hostname, s.srv.Appname)
+	return true
+}
+
+// Read a base64 response to an AUTH challenge, or decode the initial response if one was
+// given. Replies to the client and returns false if the response is invalid or cancelled.
+func (s *session) authResponse(challenge string, initial string) (string, bool) {
+	response := initial
+	if response == "" {
+		s.reply(334, "%s", base64.StdEncoding.EncodeToString([]byte(challenge)))
+		line, err := s.readLine()
+		if err != nil {
+			return "", false
+		}
+		response = strings.TrimSpace(line)
+	}
+	if response == "*" {
+		s.reply(501, "5.7.0 Authentication cancelled")
+		return "", false
+	}
+	if response == "=" {
+		// An empty initial response (RFC 4954 section 4).
+		return "", true
+	}
+	decoded, err := base64.StdEncoding.DecodeString(response)
+	if err != nil {
+		s.reply(501, "5.5.2 Invalid base64 data")
+		return "", false
+	}
+	return string(decoded), true
+}
+
+func (s *session) mail(args string) {
+	if s.helo == "" {
+		s.reply(503, "5.5.1 Bad sequence of commands, send HELO or EHLO first")
+		return
+	}
+	if s.from != nil {
+		s.reply(503, "5.5.1 Bad sequence of commands, sender already given")
+		return
+	}
+	if s.srv.RequireTLS && !s.tls {
+		s.reply(530, "5.7.0 Must issue a STARTTLS command first")
+		return
+	}
+	if s.srv.RequireAuth && s.user == "" {
+		s.reply(530, "5.7.0 Authentication required")
+		return
+	}
+	from, params, ok := parsePath(args, "FROM:")
+	if !ok {
+		s.reply(501, "5.5.4 Syntax: MAIL FROM:<address>")
+		return
+	}
+	if size, err := strconv.Atoi(params["SIZE"]); err == nil && size > MaxMessageSize {
+		s.reply(552, "5.3.4 Message size exceeds fixed limit")
+		return
+	}
+	ret := strings.ToUpper(params["RET"])
+	if ret != "" && ret != RetFull && ret != RetHeaders {
+		s.reply(501, "5.5.4 Invalid RET parameter")
+		return
+	}
+	envId, err := DecodeXtext(params["ENVID"])
+	if err != nil || len(params["ENVID"]) > MaxEnvIdLength {
+		s.reply(501, "5.5.4 Invalid ENVID parameter")
+		return
+	}
+	if s.srv.Throttle != nil && s.ip != nil {
+		if err := s.srv.Throttle.Message(s.ip, time.Now()); err != nil {
+			s.replyError(err)
+			return
+		}
+	}
+	s.from = &from
+	s.dsn.Ret, s.dsn.EnvId = ret, envId
+	s.reply(250, "2.1.0 Ok")
+}
+
+func (s *session) rcpt(args string) {
+	if s.from == nil {
+		s.reply(503, "5.5.1 Bad sequence of commands, send MAIL first")
+		return
+	}
+	to, params, ok := parsePath(args, "TO:")
+	if !ok || to == "" {
+		s.reply(501, "5.5.4 Syntax: RCPT TO:<address>")
+		return
+	}
+	notify, hasNotify := params["NOTIFY"]
+	if hasNotify {
+		if notify, ok = ParseNotify(notify); !ok {
+			s.reply(501, "5.5.4 Invalid NOTIFY parameter")
+			return
+		}
+	}
+	orcpt, hasORcpt := params["ORCPT"]
+	if hasORcpt {
+		if orcpt, ok = ParseORcpt(orcpt); !ok {
+			s.reply(501, "5.5.4 Invalid ORCPT parameter")
+			return
+		}
+	}
+	maxRecipients := s.srv.MaxRecipients
+	if maxRecipients == 0 {
+		maxRecipients = MaxRecipients
+	}
+	if len(s.to) >= maxRecipients {
+		if s.srv.Throttle != nil && s.ip != nil {
+			s.srv.Throttle.TooManyRecipients(s.ip, time.Now())
+		}
+		s.reply(452, "4.5.3 Too many recipients")
+		return
+	}
+	if !DomainAllowed(to, s.srv.RecipientDomains) {
+		log.Printf("Rejected recipient %s from %s: domain not allowed", to, s.origin)
+		s.reply(550, "5.7.1 Recipient domain not allowed")
+		return
+	}
+	if s.srv.RcptHandler != nil {
+		if err := s.srv.RcptHandler(s.origin, s.envelope(), to); err != nil {
+			s.replyError(err)
+			return
+		}
+	}
+	s.to = append(s.to, to)
+	if hasNotify {
+		if s.dsn.Notify == nil {
+			s.dsn.Notify = map[string]string{}
+		}
+		s.dsn.Notify[to] = notify
+	}
+	if hasORcpt {
+		if s.dsn.ORcpt == nil {
+			s.dsn.ORcpt = map[string]string{}
+		}
+		s.dsn.ORcpt[to] = orcpt
+	}
+	s.reply(250, "2.1.5 Ok")
+}
+
+// Receive the message data and pass it to the handler. Returns false if the connection should be closed.
+func (s *session) data() bool {
+	if s.from == nil || len(s.to) == 0 {
+		s.reply(503, "5.5.1 Bad sequence of commands, send RCPT first")
+		return true
+	}
+	s.reply(354, "End data with <CR><LF>.<CR><LF>")
+
+	data, err := s.readData()
+	if err == errMessageTooLarge {
+		s.reply(552, "5.3.4 Message size exceeds fixed limit")
+		s.reset()
+		return true
+	}
+	if err != nil {
+		return false
+	}
+
+	env := s.envelope()
+	s.reset()
+	if s.srv.Handler != nil {
+		data = append(s.receivedHeader(env.From, env.To), data...)
+		if err := s.srv.Handler(s.origin, env, data); err != nil {
+			s.replyError(err)
+			return true
+		}
+	}
+	s.reply(250, "2.0.0 Ok: queued")
+	return true
+}
+
+// Return the envelope of the current mail transaction.
+func (s *session) envelope() Envelope {
+	client := ClientInfo{Name: s.name, Helo: s.helo, Proto: "SMTP"}
+	if s.esmtp {
+		client.Proto = "ESMTP"
+	}
+	return Envelope{From: *s.from, To: s.to, DSN: s.dsn, Listener: s.srv.Name, Client: client}
+}
+
+// Build the Received header added to the top of each message (RFC 5321 section 4.4).
+func (s *session) receivedHeader(from string, to []string) []byte {
+	remoteHost := fmt.Sprintf("[%s]", s.ip)
+	if s.ip == nil {
+		remoteHost = s.origin.String()
+	} else if s.name != "" {
+		remoteHost = s.name + " " + remoteHost
+	}
+	forClause := ""
+	if len(to) == 1 {
+		forClause = fmt.Sprintf("\r\n\tfor <%s>", to[0])
+	}
+	return []byte(fmt.Sprintf("Received: from %s (%s)\r\n\tby %s (%s) with SMTP%s;\r\n\t%s\r\n",
+		s.helo, remoteHost, s.srv.Hostname, s.srv.Appname, forClause, time.Now().Format(time.RFC1123Z)))
+}
+
+// Reply with a handler's error, sending an *SMTPError as is and hiding the details of others.
+func (s *session) replyError(err error) {
+	var smtpErr *SMTPError
+	if errors.As(err, &smtpErr) {
+		s.reply(smtpErr.Code, "%s", smtpErr.Message)
+		return
+	}
+	log.Printf("Error handling mail from %s: %v", s.origin, err)
+	s.reply(451, "4.3.0 Requested action aborted: local error in processing")
+}
+
+func (s *session) reset() {
+	s.from = nil
+	s.to = nil
+	s.dsn = DSNParams{}
+}
+
+func (s *session) reply(code int, format string, args ...interface{}) {
+	s.replyLines(code, []string{fmt.Sprintf(format, args...)})
+}
+
+func (s *session) replyLines(code int, lines []string) {
+	for i, line := range lines {
+		sep := "-"
+		if i == len(lines)-1 {
+			sep = " "
+		}
+		fmt.Fprintf(s.bw, "%d%s%s\r\n", code, sep, line)
+	}
+	s.bw.Flush()
+}
+
+var (
+	errLineTooLong     = errors.New("line too long")
+	errMessageTooLarge = errors.New("message too large")
+)
+
+// Read a command line without its line ending. Overlong lines are discarded.
+func (s *session) readLine() (string, error) {
+	s.conn.SetReadDeadline(time.Now().Add(SessionTimeout))
+	var line []byte
+	for {
+		chunk, isPrefix, err := s.br.ReadLine()
+		if err != nil {
+			return "", err
+		}
+		line = append(line, chunk...)
+		if len(line) > MaxLineLength {
+			// Discard the rest of the line.
+			for isPrefix && err == nil {
+				_, isPrefix, err = s.br.ReadLine()
+			}
+			return "", errLineTooLong
+		}
+		if !isPrefix {
+			return string(line), nil
+		}
+	}
+}
+
+// Read message data up to the terminating "." line, removing dot-stuffing and
+// normalising line endings to CRLF.
+func (s *session) readData() ([]byte, error) {
+	var data bytes.Buffer
+	tooLarge := false
+	// Lines longer than the read buffer arrive in pieces. Only the first piece starts a line,
+	// so only it can be the terminating "." or be dot-stuffed. A CR at the end of a piece is
+	// held back in case the next piece starts with the LF that ends the line.
+	continued, heldCR := false, false
+	for {
+		s.conn.SetReadDeadline(time.Now().Add(SessionTimeout))
+		line, err := s.br.ReadSlice('\n')
+		if err != nil && err != bufio.ErrBufferFull {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		if heldCR && !bytes.Equal(line, []byte("\n")) {
+			line = append([]byte("\r"), line...)
+		}
+		if !continued && len(line) > 0 && line[0] == '.' {
+			if err == nil && len(bytes.TrimRight(line, "\r\n")) == 1 {
+				break
+			}
+			line = line[1:]
+		}
+		ending := "\r\n"
+		if err == bufio.ErrBufferFull {
+			heldCR = bytes.HasSuffix(line, []byte("\r"))
+			line, ending = bytes.TrimSuffix(line, []byte("\r")), ""
+		} else {
+			heldCR = false
+			line = bytes.TrimRight(line, "\r\n")
+		}
+		continued = err == bufio.ErrBufferFull
+		if !tooLarge {
+			data.Write(line)
+			data.WriteString(ending)
+			tooLarge = data.Len() > MaxMessageSize
+		}
+	}
+	if tooLarge {
+		return nil, errMessageTooLarge
+	}
+	return data.Bytes(), nil
+}
+
+// Parse the argument of a MAIL or RCPT command, e.g. "FROM:<user@example.com> SIZE=1000".
+// Returns the address and any ESMTP parameters, keyed in upper case.
+func parsePath(args string, prefix string) (string, map[string]string, bool) {
+	if len(args) < len(prefix) || !strings.EqualFold(args[:len(prefix)], prefix) {
+		return "", nil, false
+	}
+	args = strings.TrimSpace(args[len(prefix):])
+	if !strings.HasPrefix(args, "<") {
+		return "", nil, false
+	}
+	end := strings.IndexByte(args, '>')
+	if end < 0 {
+		return "", nil, false
+	}
+	addr := args[1:end]
+	// Strip any source route, e.g. <@relay.example.com:user@example.com>.
+	if strings.HasPrefix(addr, "@") {
+		if i := strings.IndexByte(addr, ':'); i >= 0 {
+			addr = addr[i+1:]
+		}
+	}
+	params := map[string]string{}
+	for _, param := range strings.Fields(args[end+1:]) {
+		key, value := param, ""
+		if i := strings.IndexByte(param, '='); i >= 0 {
+			key, value = param[:i], param[i+1:]
+		}
+		params[strings.ToUpper(key)] = value
+	}
+	return addr, params, true
+}
+
+// Report whether a client may connect: it must be in the allow list, if there is one, and must
+// not be in the deny list.
+func ClientAllowed(ip net.IP, allow []*net.IPNet, deny []*net.IPNet) bool {
+	for _, network := range deny {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	if len(allow) == 0 {
+		return true
+	}
+	for _, network := range allow {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Report whether an address belongs to one of a list of domains. A domain starting with "."
+// allows its subdomains, e.g. ".example.com" allows mail.example.com. An empty list allows
+// any address. The postmaster address is always allowed, as RFC 5321 section 4.5.1 requires.
+func DomainAllowed(addr string, domains []string) bool {
+	if len(domains) == 0 || strings.EqualFold(addr, "postmaster") {
+		return true
+	}
+	i := strings.LastIndex(addr, "@")
+	if i < 0 {
+		return false
+	}
+	domain := strings.ToLower(addr[i+1:])
+	for _, d := range domains {
+		d = strings.ToLower(strings.TrimSpace(d))
+		if d == domain || (strings.HasPrefix(d, ".") && strings.HasSuffix(domain, d)) {
+			return true
+		}
+	}
+	return false
+}
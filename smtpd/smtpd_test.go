@@ -0,0 +1,486 @@
+package smtpd
+
+import (
+	"bufio"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// A received message recorded by a test handler.
+type received struct {
+	origin net.Addr
+	from   string
+	to     []string
+	dsn    DSNParams
+	client ClientInfo
+	data   []byte
+}
+
+// Start a server on a local port with a handler that records messages and returns err.
+func startTestServer(t *testing.T, err error) (string, func() []received) {
+	var mu sync.Mutex
+	var messages []received
+	srv := &Server{Hostname: "mx.test", Appname: "Mailrouter", Handler: func(origin net.Addr, env Envelope, data []byte) error {
+		mu.Lock()
+		defer mu.Unlock()
+		messages = append(messages, received{origin, env.From, env.To, env.DSN, env.Client, data})
+		return err
+	}}
+	return serveTest(t, srv), func() []received {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]received(nil), messages...)
+	}
+}
+
+// Serve SMTP on a local port until the test ends, returning the address.
+func serveTest(t *testing.T, srv *Server) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.Serve(ln)
+	t.Cleanup(func() { ln.Close() })
+	return ln.Addr().String()
+}
+
+func TestServerReceive(t *testing.T) {
+	addr, messages := startTestServer(t, nil)
+	body := "Subject: test\r\n\r\n.leading dot\r\nbare lf\nend\r\n"
+	err := smtp.SendMail(addr, nil, "sender@example.com", []string{"a@example.com", "b@example.com"}, []byte(body))
+	if err != nil {
+		t.Fatalf("SendMail() = %v", err)
+	}
+	got := messages()
+	if len(got) != 1 {
+		t.Fatalf("received %d messages, want 1", len(got))
+	}
+	m := got[0]
+	if m.from != "sender@example.com" || strings.Join(m.to, ",") != "a@example.com,b@example.com" {
+		t.Errorf("envelope = %s to %v, want sender@example.com to a and b", m.from, m.to)
+	}
+	if m.client != (ClientInfo{Helo: "localhost", Proto: "ESMTP"}) {
+		t.Errorf("client = %+v, want HELO localhost over ESMTP", m.client)
+	}
+	data := string(m.data)
+	if !strings.HasPrefix(data, "Received: from localhost ([127.0.0.1])\r\n\tby mx.test (Mailrouter) with SMTP;") {
+		t.Errorf("message does not start with a Received header: %q", data)
+	}
+	if !strings.HasSuffix(data, "\r\n\r\n.leading dot\r\nbare lf\r\nend\r\n") {
+		t.Errorf("message data = %q, want dot-unstuffed with CRLF line endings", data)
+	}
+}
+
+func TestServerLongLines(t *testing.T) {
+	// Lines longer than the 4096 byte read buffer, split where a piece starts with a dot or
+	// ends with the CR of the line ending. Neither may end the message or lose a byte.
+	lines := []string{
+		strings.Repeat("x", 8192) + ".",
+		strings.Repeat("x", 8192) + ".\r\nRSET",
+		"." + strings.Repeat("y", 5000),
+		strings.Repeat("z", 4095),
+	}
+	for _, line := range lines {
+		addr, messages := startTestServer(t, nil)
+		body := "Subject: test\r\n\r\n" + line + "\r\nend\r\n"
+		if err := smtp.SendMail(addr, nil, "sender@example.com", []string{"a@example.com"}, []byte(body)); err != nil {
+			t.Errorf("SendMail() with a %d byte line = %v", len(line), err)
+			continue
+		}
+		if got := messages(); len(got) != 1 || !strings.HasSuffix(string(got[0].data), "\r\n\r\n"+line+"\r\nend\r\n") {
+			t.Errorf("received %d messages with a %d byte line, want one with the line intact", len(got), len(line))
+		}
+	}
+}
+
+func TestServerHandlerError(t *testing.T) {
+	tests := []struct {
+		err  error
+		code int
+		msg  string
+	}{
+		{&SMTPError{Code: 550, Message: "5.7.1 Outbound mail disabled"}, 550, "5.7.1 Outbound mail disabled"},
+		{&SMTPError{Code: 451, Message: "4.7.1 Try later"}, 451, "4.7.1 Try later"},
+		{errors.New("disk full"), 451, "4.3.0 Requested action aborted: local error in processing"},
+	}
+	for _, tt := range tests {
+		addr, _ := startTestServer(t, tt.err)
+		err := smtp.SendMail(addr, nil, "sender@example.com", []string{"a@example.com"}, []byte("Subject: test\r\n\r\ntest\r\n"))
+		var protoErr *textproto.Error
+		if !errors.As(err, &protoErr) || protoErr.Code != tt.code || protoErr.Msg != tt.msg {
+			t.Errorf("SendMail() with handler error %v = %v, want %d %s", tt.err, err, tt.code, tt.msg)
+		}
+	}
+}
+
+func TestServerCommands(t *testing.T) {
+	addr, messages := startTestServer(t, nil)
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	text := textproto.NewConn(conn)
+	if _, _, err := text.ReadResponse(220); err != nil {
+		t.Fatalf("greeting: %v", err)
+	}
+
+	tests := []struct {
+		cmd  string
+		code int
+	}{
+		{"MAIL FROM:<sender@example.com>", 503},
+		{"EHLO", 501},
+		{"EHLO client.example.com", 250},
+		{"RCPT TO:<a@example.com>", 503},
+		{"MAIL FROM:<>", 250},
+		{"MAIL FROM:<sender@example.com>", 503},
+		{"RCPT TO:<>", 501},
+		{"RCPT TO:<@relay.example.com:a@example.com> NOTIFY=NEVER", 250},
+		{"RSET", 250},
+		{"DATA", 503},
+		{"MAIL FROM:<sender@example.com> SIZE=999999999", 552},
+		{"MAIL FROM:sender@example.com", 501},
+		{"VRFY someone", 252},
+		{"NOOP", 250},
+		{"BOGUS", 500},
+		{"mail from:<sender@example.com>", 250},
+		{"rcpt to:<a@example.com>", 250},
+		{"DATA", 354},
+	}
+	for _, tt := range tests {
+		if err := text.PrintfLine("%s", tt.cmd); err != nil {
+			t.Fatal(err)
+		}
+		if code, msg, _ := text.ReadResponse(0); code != tt.code {
+			t.Errorf("%s = %d %s, want %d", tt.cmd, code, msg, tt.code)
+		}
+	}
+
+	w := bufio.NewWriter(conn)
+	w.WriteString("Subject: test\r\n\r\n..stuffed\r\n.\r\nQUIT\r\n")
+	w.Flush()
+	if code, msg, _ := text.ReadResponse(0); code != 250 {
+		t.Errorf("end of data = %d %s, want 250", code, msg)
+	}
+	if code, msg, _ := text.ReadResponse(0); code != 221 {
+		t.Errorf("QUIT = %d %s, want 221", code, msg)
+	}
+	if got := messages(); len(got) != 1 || got[0].to[0] != "a@example.com" || !strings.HasSuffix(string(got[0].data), "\r\n.stuffed\r\n") {
+		t.Errorf("received %+v, want one message with unstuffed data", got)
+	}
+}
+
+func TestParseReply(t *testing.T) {
+	tests := []struct {
+		in   string
+		code int
+		msg  string
+		fail bool
+	}{
+		{"550 5.7.1 Outbound mail disabled", 550, "5.7.1 Outbound mail disabled", false},
+		{"451 4.7.1 Greylisted", 451, "4.7.1 Greylisted", false},
+		{"Go away", 550, "Go away", false},
+		{"554", 554, "5.7.1 Message rejected", false},
+		{"421", 421, "4.7.1 Message rejected", false},
+		{"250 Ok", 0, "", true},
+		{"550 two\r\nlines", 0, "", true},
+	}
+	for _, tt := range tests {
+		reply, err := ParseReply(tt.in, 550)
+		if (err != nil) != tt.fail || (err == nil && (reply.Code != tt.code || reply.Message != tt.msg)) {
+			t.Errorf("ParseReply(%q) = %v, %v, want %d %s", tt.in, reply, err, tt.code, tt.msg)
+		}
+	}
+}
+
+func TestServerRecipients(t *testing.T) {
+	srv := &Server{
+		RecipientDomains: []string{"example.com", ".example.org"},
+		RcptHandler: func(origin net.Addr, env Envelope, to string) error {
+			if strings.HasPrefix(to, "blocked@") {
+				return &SMTPError{Code: 550, Message: "5.7.1 Blocked by filter"}
+			}
+			return nil
+		},
+	}
+	conn, err := net.Dial("tcp", serveTest(t, srv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	text := textproto.NewConn(conn)
+	text.ReadResponse(220)
+	text.PrintfLine("EHLO client.example.com")
+	text.ReadResponse(250)
+	text.PrintfLine("MAIL FROM:<sender@elsewhere.com>")
+	text.ReadResponse(250)
+
+	tests := []struct {
+		to   string
+		code int
+		msg  string
+	}{
+		{"user@example.com", 250, "2.1.5 Ok"},
+		{"user@EXAMPLE.COM", 250, "2.1.5 Ok"},
+		{"user@mail.example.org", 250, "2.1.5 Ok"},
+		{"user@example.org", 550, "5.7.1 Recipient domain not allowed"},
+		{"user@badexample.com", 550, "5.7.1 Recipient domain not allowed"},
+		{"postmaster", 250, "2.1.5 Ok"},
+		{"blocked@example.com", 550, "5.7.1 Blocked by filter"},
+	}
+	for _, tt := range tests {
+		text.PrintfLine("RCPT TO:<%s>", tt.to)
+		if code, msg, _ := text.ReadResponse(0); code != tt.code || msg != tt.msg {
+			t.Errorf("RCPT TO:<%s> = %d %s, want %d %s", tt.to, code, msg, tt.code, tt.msg)
+		}
+	}
+}
+
+func TestDomainAllowed(t *testing.T) {
+	tests := []struct {
+		addr    string
+		domains []string
+		out     bool
+	}{
+		{"user@anywhere.com", nil, true},
+		{"user@example.com", []string{"example.com"}, true},
+		{"user@Example.Com", []string{" EXAMPLE.com "}, true},
+		{"user@mail.example.com", []string{"example.com"}, false},
+		{"user@mail.example.com", []string{".example.com"}, true},
+		{"user@example.com", []string{".example.com"}, false},
+		{"user@example.net", []string{"example.com", "example.net"}, true},
+		{"user", []string{"example.com"}, false},
+		{"Postmaster", []string{"example.com"}, true},
+	}
+	for _, tt := range tests {
+		if out := DomainAllowed(tt.addr, tt.domains); out != tt.out {
+			t.Errorf("DomainAllowed(%s, %v) = %v, want %v", tt.addr, tt.domains, out, tt.out)
+		}
+	}
+}
+
+func TestServerDSN(t *testing.T) {
+	addr, messages := startTestServer(t, nil)
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	text := textproto.NewConn(conn)
+	text.ReadResponse(220)
+	text.PrintfLine("EHLO client.example.com")
+	if _, msg, _ := text.ReadResponse(250); !strings.Contains(msg, "\nDSN") {
+		t.Errorf("EHLO = %q, want DSN advertised", msg)
+	}
+
+	tests := []struct {
+		cmd  string
+		code int
+	}{
+		{"MAIL FROM:<sender@example.com> RET=BODY", 501},
+		{"MAIL FROM:<sender@example.com> ENVID=bad+zz", 501},
+		{"MAIL FROM:<sender@example.com> RET=hdrs ENVID=QQ+2B314", 250},
+		{"RCPT TO:<a@example.com> NOTIFY=NEVER,SUCCESS", 501},
+		{"RCPT TO:<a@example.com> NOTIFY=SOMETIMES", 501},
+		{"RCPT TO:<a@example.com> ORCPT=user@example.com", 501},
+		{"RCPT TO:<a@example.com> NOTIFY=success,delay ORCPT=rfc822;user+2Bbox@example.com", 250},
+		{"RCPT TO:<b@example.com>", 250},
+		{"DATA", 354},
+	}
+	for _, tt := range tests {
+		text.PrintfLine("%s", tt.cmd)
+		if code, msg, _ := text.ReadResponse(0); code != tt.code {
+			t.Errorf("%s = %d %s, want %d", tt.cmd, code, msg, tt.code)
+		}
+	}
+	text.PrintfLine("Subject: test\r\n\r\ntest\r\n.")
+	text.ReadResponse(250)
+
+	got := messages()
+	if len(got) != 1 {
+		t.Fatalf("received %d messages, want 1", len(got))
+	}
+	dsn := got[0].dsn
+	if dsn.Ret != RetHeaders || dsn.EnvId != "QQ+314" {
+		t.Errorf("RET, ENVID = %q, %q, want HDRS, QQ+314", dsn.Ret, dsn.EnvId)
+	}
+	if dsn.Notify["a@example.com"] != "SUCCESS,DELAY" || dsn.ORcpt["a@example.com"] != "rfc822;user+box@example.com" {
+		t.Errorf("NOTIFY, ORCPT = %v, %v, want SUCCESS,DELAY and rfc822;user+box@example.com for a@example.com", dsn.Notify, dsn.ORcpt)
+	}
+	if _, ok := dsn.Notify["b@example.com"]; ok {
+		t.Errorf("NOTIFY = %v, want none for b@example.com", dsn.Notify)
+	}
+}
+
+// Parse networks in CIDR notation or single addresses for a test, failing it if they are invalid.
+func testNetworks(t *testing.T, list ...string) []*net.IPNet {
+	var networks []*net.IPNet
+	for _, entry := range list {
+		entry = strings.TrimSpace(entry)
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				bits = 8 * net.IPv4len
+			}
+			entry = fmt.Sprintf("%s/%d", entry, bits)
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			t.Fatal(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+func TestClientAllowed(t *testing.T) {
+	tests := []struct {
+		ip    string
+		allow []string
+		deny  []string
+		want  bool
+	}{
+		{"192.0.2.1", nil, nil, true},
+		{"192.0.2.1", []string{"192.0.2.0/24"}, nil, true},
+		{"198.51.100.1", []string{"192.0.2.0/24", "2001:db8::/32"}, nil, false},
+		{"2001:db8::1", []string{"192.0.2.0/24", "2001:db8::/32"}, nil, true},
+		{"192.0.2.1", []string{"192.0.2.0/24"}, []string{"192.0.2.1"}, false},
+		{"192.0.2.2", []string{"192.0.2.0/24"}, []string{" 192.0.2.1"}, true},
+		{"192.0.2.1", nil, []string{"192.0.2.0/28"}, false},
+		{"::ffff:192.0.2.1", []string{"192.0.2.1"}, nil, true},
+	}
+	for _, tt := range tests {
+		if got := ClientAllowed(net.ParseIP(tt.ip), testNetworks(t, tt.allow...), testNetworks(t, tt.deny...)); got != tt.want {
+			t.Errorf("ClientAllowed(%s, %v, %v) = %v, want %v", tt.ip, tt.allow, tt.deny, got, tt.want)
+		}
+	}
+}
+
+func TestServerDenyClient(t *testing.T) {
+	srv := &Server{Hostname: "mx.test", DenyClients: testNetworks(t, "127.0.0.0/8")}
+	conn, err := net.Dial("tcp", serveTest(t, srv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	text := textproto.NewConn(conn)
+	if code, msg, _ := text.ReadResponse(0); code != 554 {
+		t.Errorf("greeting = %d %s, want 554", code, msg)
+	}
+	tests := []struct {
+		cmd  string
+		code int
+	}{
+		{"EHLO client.example.com", 503},
+		{"MAIL FROM:<sender@example.com>", 503},
+		{"QUIT", 221},
+	}
+	for _, tt := range tests {
+		text.PrintfLine("%s", tt.cmd)
+		if code, msg, _ := text.ReadResponse(0); code != tt.code {
+			t.Errorf("%s = %d %s, want %d", tt.cmd, code, msg, tt.code)
+		}
+	}
+}
+
+func TestServerUnixSocket(t *testing.T) {
+	var mu sync.Mutex
+	var origin net.Addr
+	path := filepath.Join(t.TempDir(), "smtp.sock")
+	srv := &Server{Hostname: "mx.test", Handler: func(o net.Addr, env Envelope, data []byte) error {
+		mu.Lock()
+		defer mu.Unlock()
+		origin = o
+		return nil
+	}}
+	ln, err := listenUnix(path, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.Serve(ln)
+	t.Cleanup(func() { ln.Close() })
+
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("socket mode = %v, %v, want 0600", info.Mode().Perm(), err)
+	}
+	c, err := smtp.NewClient(conn, "localhost")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	c.Mail("cron@localhost")
+	c.Rcpt("root@example.com")
+	w, _ := c.Data()
+	w.Write([]byte("Subject: cron\r\n\r\ndone\r\n"))
+	if err := w.Close(); err != nil {
+		t.Fatalf("DATA = %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	local, ok := origin.(*LocalAddr)
+	if !ok {
+		t.Fatalf("origin = %#v, want a *LocalAddr", origin)
+	}
+	if local.Path != path {
+		t.Errorf("origin path = %q, want %q", local.Path, path)
+	}
+	if runtime.GOOS == "linux" && (!local.Known || local.Uid != os.Getuid() || local.Gid != os.Getgid() || local.Pid != os.Getpid()) {
+		t.Errorf("origin = %+v, want the credentials of this process", local)
+	}
+}
+
+func TestServerAuthLogin(t *testing.T) {
+	srv := &Server{Hostname: "mx.test", Auth: func(username, password string) bool {
+		return username == "app" && password == "secret"
+	}}
+	conn, err := net.Dial("tcp", serveTest(t, srv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	text := textproto.NewConn(conn)
+	text.ReadResponse(220)
+	text.PrintfLine("EHLO client.example.com")
+	if _, msg, _ := text.ReadResponse(250); !strings.Contains(msg, "\nAUTH PLAIN LOGIN") {
+		t.Errorf("EHLO = %q, want AUTH advertised on a server without TLS", msg)
+	}
+
+	encode := func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) }
+	tests := []struct {
+		cmd  string
+		code int
+		msg  string
+	}{
+		{"AUTH CRAM-MD5", 504, "5.5.4 Unrecognised authentication mechanism"},
+		{"AUTH LOGIN", 334, encode("Username:")},
+		{"*", 501, "5.7.0 Authentication cancelled"},
+		{"AUTH LOGIN " + encode("app"), 334, encode("Password:")},
+		{encode("wrong"), 535, "5.7.8 Authentication credentials invalid"},
+		{"AUTH LOGIN " + encode("app"), 334, encode("Password:")},
+		{encode("secret"), 235, "2.7.0 Authentication successful"},
+		{"AUTH PLAIN " + encode("\x00app\x00secret"), 503, "5.5.1 Already authenticated"},
+	}
+	for _, tt := range tests {
+		text.PrintfLine("%s", tt.cmd)
+		if code, msg, _ := text.ReadResponse(0); code != tt.code || msg != tt.msg {
+			t.Errorf("%s = %d %s, want %d %s", tt.cmd, code, msg, tt.code, tt.msg)
+		}
+	}
+}
@@ -0,0 +1,410 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// Limits on DNS lookups during SPF evaluation (RFC 7208 section 4.6.4).
+const (
+	SPFMaxLookups     = 10
+	SPFMaxVoidLookups = 2
+	SPFMaxMXNames     = 10
+)
+
+// State for a single SPF evaluation. Lookup counts are shared across include and redirect.
+type spfCheck struct {
+	ip      net.IP
+	sender  string
+	local   string
+	lookups int
+	voids   int
+}
+
+// Evaluate SPF for the envelope sender against the originating IP.
+// Returns the result and the domain that was checked.
+func CheckSPF(ip net.IP, sender string) (string, string) {
+	if sender == "" || ip == nil {
+		return AuthNone, ""
+	}
+	local, domain := "postmaster", sender
+	if i := strings.LastIndex(sender, "@"); i >= 0 {
+		local, domain = sender[:i], sender[i+1:]
+		if local == "" {
+			local = "postmaster"
+		}
+	}
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+	if domain == "" {
+		return AuthNone, ""
+	}
+	c := &spfCheck{ip: ip, sender: local + "@" + domain, local: local}
+	return c.checkHost(domain), domain
+}
+
+// Fetch the single SPF record for a domain. Returns a result string if the record could not be used.
+func (c *spfCheck) lookupRecord(domain string) (string, string) {
+	txts, err := resolver.LookupTXT(domain)
+	if err != nil && !IsNotFound(err) {
+		return "", AuthTempError
+	}
+	var records []string
+	for _, txt := range txts {
+		if strings.EqualFold(txt, "v=spf1") || strings.HasPrefix(strings.ToLower(txt), "v=spf1 ") {
+			records = append(records, txt)
+		}
+	}
+	if len(records) == 0 {
+		return "", AuthNone
+	}
+	if len(records) > 1 {
+		return "", AuthPermError
+	}
+	return records[0], ""
+}
+
+// The check_host() function from RFC 7208 section 4.
+func (c *spfCheck) checkHost(domain string) string {
+	record, result := c.lookupRecord(domain)
+	if result != "" {
+		return result
+	}
+
+	var redirect string
+	for _, term := range strings.Fields(record)[1:] {
+		// Modifiers are name=value where the name contains no mechanism separators.
+		if i := strings.Index(term, "="); i > 0 && !strings.ContainsAny(term[:i], ":/") {
+			name := strings.ToLower(term[:i])
+			if name == "redirect" {
+				if redirect != "" {
+					return AuthPermError
+				}
+				redirect = term[i+1:]
+			}
+			continue
+		}
+
+		qualifier := AuthPass
+		switch term[0] {
+		case '+':
+			term = term[1:]
+		case '-':
+			qualifier = AuthFail
+			term = term[1:]
+		case '~':
+			qualifier = AuthSoftfail
+			term = term[1:]
+		case '?':
+			qualifier = AuthNeutral
+			term = term[1:]
+		}
+
+		match, result := c.mechanism(domain, term)
+		if result != "" {
+			return result
+		}
+		if match {
+			return qualifier
+		}
+	}
+
+	if redirect != "" {
+		c.lookups++
+		if c.lookups > SPFMaxLookups {
+			return AuthPermError
+		}
+		target, err := c.expand(redirect, domain)
+		if err != nil {
+			return AuthPermError
+		}
+		result := c.checkHost(target)
+		if result == AuthNone {
+			return AuthPermError
+		}
+		return result
+	}
+
+	return AuthNeutral
+}
+
+// Evaluate a single mechanism. A non-empty result aborts evaluation with that result.
+func (c *spfCheck) mechanism(domain string, term string) (bool, string) {
+	name, arg := term, ""
+	if i := strings.IndexAny(term, ":/"); i >= 0 {
+		name, arg = term[:i], term[i:]
+	}
+	name = strings.ToLower(name)
+	arg = strings.TrimPrefix(arg, ":")
+
+	switch name {
+	case "all":
+		return true, ""
+
+	case "ip4", "ip6":
+		cidr := arg
+		if !strings.Contains(cidr, "/") {
+			if name == "ip4" {
+				cidr += "/32"
+			} else {
+				cidr += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return false, AuthPermError
+		}
+		if (name == "ip4") != (c.ip.To4() != nil) {
+			return false, ""
+		}
+		return network.Contains(c.ip), ""
+
+	case "include":
+		if !c.countLookup() {
+			return false, AuthPermError
+		}
+		target, err := c.expand(arg, domain)
+		if err != nil || target == "" {
+			return false, AuthPermError
+		}
+		switch c.checkHost(target) {
+		case AuthPass:
+			return true, ""
+		case AuthTempError:
+			return false, AuthTempError
+		case AuthPermError, AuthNone:
+			return false, AuthPermError
+		}
+		return false, ""
+
+	case "a", "mx":
+		if !c.countLookup() {
+			return false, AuthPermError
+		}
+		spec, mask4, mask6, err := splitCIDR(arg)
+		if err != nil {
+			return false, AuthPermError
+		}
+		target := domain
+		if spec != "" {
+			target, err = c.expand(spec, domain)
+			if err != nil {
+				return false, AuthPermError
+			}
+		}
+		hosts := []string{target}
+		if name == "mx" {
+			mxs, err := resolver.LookupMX(target)
+			if result := c.checkLookup(len(mxs), err); result != "" {
+				return false, result
+			}
+			if len(mxs) > SPFMaxMXNames {
+				return false, AuthPermError
+			}
+			hosts = hosts[:0]
+			for _, mx := range mxs {
+				hosts = append(hosts, mx.Host)
+			}
+		}
+		for _, host := range hosts {
+			ips, err := resolver.LookupIP(host)
+			if result := c.checkLookup(len(ips), err); result != "" {
+				return false, result
+			}
+			for _, ip := range ips {
+				if matchMasked(c.ip, ip, mask4, mask6) {
+					return true, ""
+				}
+			}
+		}
+		return false, ""
+
+	case "exists":
+		if !c.countLookup() {
+			return false, AuthPermError
+		}
+		target, err := c.expand(arg, domain)
+		if err != nil || target == "" {
+			return false, AuthPermError
+		}
+		ips, err := resolver.LookupIP(target)
+		if result := c.checkLookup(len(ips), err); result != "" {
+			return false, result
+		}
+		return len(ips) > 0, ""
+
+	case "ptr":
+		// The ptr mechanism is deprecated (RFC 7208 section 5.5) and is treated as never matching.
+		if !c.countLookup() {
+			return false, AuthPermError
+		}
+		return false, ""
+	}
+
+	return false, AuthPermError
+}
+
+// Count a DNS-querying mechanism, reporting whether the lookup limit still allows it.
+func (c *spfCheck) countLookup() bool {
+	c.lookups++
+	return c.lookups <= SPFMaxLookups
+}
+
+// Check the outcome of a lookup, counting void lookups. Returns a result if evaluation must stop.
+func (c *spfCheck) checkLookup(n int, err error) string {
+	if err != nil && !IsNotFound(err) {
+		return AuthTempError
+	}
+	if n == 0 {
+		c.voids++
+		if c.voids > SPFMaxVoidLookups {
+			return AuthPermError
+		}
+	}
+	return ""
+}
+
+// Split "domain/24//64" into the domain spec and IPv4 and IPv6 prefix lengths.
+func splitCIDR(arg string) (string, int, int, error) {
+	mask4, mask6 := 32, 128
+	var err error
+	if i := strings.Index(arg, "//"); i >= 0 {
+		mask6, err = strconv.Atoi(arg[i+2:])
+		if err != nil || mask6 < 0 || mask6 > 128 {
+			return "", 0, 0, fmt.Errorf("invalid IPv6 prefix length in %q", arg)
+		}
+		arg = arg[:i]
+	}
+	if i := strings.LastIndex(arg, "/"); i >= 0 {
+		mask4, err = strconv.Atoi(arg[i+1:])
+		if err != nil || mask4 < 0 || mask4 > 32 {
+			return "", 0, 0, fmt.Errorf("invalid IPv4 prefix length in %q", arg)
+		}
+		arg = arg[:i]
+	}
+	return arg, mask4, mask6, nil
+}
+
+// Report whether two addresses of the same family share a prefix of the given length.
+func matchMasked(a net.IP, b net.IP, mask4 int, mask6 int) bool {
+	if a4, b4 := a.To4(), b.To4(); a4 != nil || b4 != nil {
+		if a4 == nil || b4 == nil {
+			return false
+		}
+		mask := net.CIDRMask(mask4, 32)
+		return a4.Mask(mask).Equal(b4.Mask(mask))
+	}
+	mask := net.CIDRMask(mask6, 128)
+	return a.Mask(mask).Equal(b.Mask(mask))
+}
+
+// Expand the macros in a domain-spec (RFC 7208 section 7).
+func (c *spfCheck) expand(spec string, domain string) (string, error) {
+	var out strings.Builder
+	for i := 0; i < len(spec); i++ {
+		if spec[i] != '%' {
+			out.WriteByte(spec[i])
+			continue
+		}
+		i++
+		if i >= len(spec) {
+			return "", fmt.Errorf("truncated macro in %q", spec)
+		}
+		switch spec[i] {
+		case '%':
+			out.WriteByte('%')
+			continue
+		case '_':
+			out.WriteByte(' ')
+			continue
+		case '-':
+			out.WriteString("%20")
+			continue
+		case '{':
+		default:
+			return "", fmt.Errorf("invalid macro in %q", spec)
+		}
+		end := strings.IndexByte(spec[i:], '}')
+		if end < 2 {
+			return "", fmt.Errorf("invalid macro in %q", spec)
+		}
+		macro := spec[i+1 : i+end]
+		i += end
+
+		var value string
+		switch strings.ToLower(macro[:1]) {
+		case "s":
+			value = c.sender
+		case "l":
+			value = c.local
+		case "o":
+			value = c.sender[strings.LastIndex(c.sender, "@")+1:]
+		case "d":
+			value = domain
+		case "i":
+			value = spfIP(c.ip)
+		case "p":
+			value = "unknown"
+		case "v":
+			value = "ip6"
+			if c.ip.To4() != nil {
+				value = "in-addr"
+			}
+		case "h":
+			value = "unknown"
+		default:
+			return "", fmt.Errorf("invalid macro letter in %q", spec)
+		}
+
+		// Apply the optional transformers: a label count, reversal, and delimiters.
+		rest := macro[1:]
+		digits := 0
+		for digits < len(rest) && rest[digits] >= '0' && rest[digits] <= '9' {
+			digits++
+		}
+		keep := 0
+		if digits > 0 {
+			keep, _ = strconv.Atoi(rest[:digits])
+			if keep == 0 {
+				return "", fmt.Errorf("invalid macro transformer in %q", spec)
+			}
+		}
+		rest = rest[digits:]
+		reverse := false
+		if strings.HasPrefix(strings.ToLower(rest), "r") {
+			reverse = true
+			rest = rest[1:]
+		}
+		delims := "."
+		if rest != "" {
+			if strings.Trim(rest, ".-+,/_=") != "" {
+				return "", fmt.Errorf("invalid macro delimiter in %q", spec)
+			}
+			delims = rest
+		}
+		parts := strings.FieldsFunc(value, func(r rune) bool { return strings.ContainsRune(delims, r) })
+		if reverse {
+			for l, r := 0, len(parts)-1; l < r; l, r = l+1, r-1 {
+				parts[l], parts[r] = parts[r], parts[l]
+			}
+		}
+		if keep > 0 && keep < len(parts) {
+			parts = parts[len(parts)-keep:]
+		}
+		out.WriteString(strings.Join(parts, "."))
+	}
+	return strings.TrimSuffix(out.String(), "."), nil
+}
+
+// Format an IP for the "i" macro: dotted quad for IPv4, dotted nibbles for IPv6.
+func spfIP(ip net.IP) string {
+	if ip4 := ip.To4(); ip4 != nil {
+		return ip4.String()
+	}
+	nibbles := make([]string, 0, 32)
+	for _, b := range ip.To16() {
+		nibbles = append(nibbles, strconv.FormatInt(int64(b>>4), 16), strconv.FormatInt(int64(b&0xf), 16))
+	}
+	return strings.Join(nibbles, ".")
+}
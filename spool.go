@@ -0,0 +1,191 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mhale/mailrouter/smtpd"
+)
+
+// Time between checks of the spool directory for messages left by sendmail mode.
+const SpoolPoll = 10 * time.Second
+
+// Extension of complete spool files. Files are written under a temporary name and renamed,
+// so a file with this extension is never read before it is fully written.
+const SpoolExt = ".json"
+
+// A message left in the spool directory by sendmail mode while mailrouter was not running.
+type SpooledMessage struct {
+	From string
+	To   []string
+	Data []byte
+}
+
+// Write a message to the spool directory, returning the name of its file.
+func SpoolMessage(dir string, m SpooledMessage) (string, error) {
+	if dir == "" {
+		return "", errors.New("no spool directory is configured")
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return "", err
+	}
+	f, err := ioutil.TempFile(dir, ".tmp-")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(f.Name())
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", err
+	}
+	// Let mailrouter read the file when it runs as a different user.
+	if err := os.Chmod(f.Name(), 0644); err != nil {
+		return "", err
+	}
+	name := strings.TrimPrefix(filepath.Base(f.Name()), ".tmp-") + SpoolExt
+	if err := os.Rename(f.Name(), filepath.Join(dir, name)); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// Create the spool directory if it does not exist. Like a sticky /tmp that cannot be listed,
+// any user may add files to it but only mailrouter can see or remove them.
+func CreateSpoolDirectory(dir string) error {
+	if _, err := os.Stat(dir); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	return os.Chmod(dir, 0733|os.ModeSticky)
+}
+
+// Read a spooled message, returning it with the origin of the user who spooled it. As any
+// user may write to the spool directory, the origin comes from the owner of the file, and
+// links, which could point at a file owned by someone else, are not followed.
+func readSpooledMessage(dir string, file string) (SpooledMessage, *smtpd.LocalAddr, error) {
+	var m SpooledMessage
+	linfo, err := os.Lstat(file)
+	if err != nil {
+		return m, nil, err
+	}
+	if !linfo.Mode().IsRegular() {
+		return m, nil, errors.New("not a regular file")
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return m, nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return m, nil, err
+	}
+	if !os.SameFile(linfo, info) {
+		return m, nil, errors.New("file was replaced while being read")
+	}
+	origin := &smtpd.LocalAddr{Path: dir}
+	origin.Uid, origin.Gid, origin.Known = FileOwner(info)
+	data, err := ioutil.ReadAll(f)
+	if err != nil {
+		return m, nil, err
+	}
+	return m, origin, json.Unmarshal(data, &m)
+}
+
+// Apply the checks the sendmail listener makes to a message submitted to it, returning the
+// envelope with only the recipients it would accept. Spooled mail never authenticates, so a
+// listener that requires authentication rejects it.
+func checkSpooledMessage(srv *smtpd.Server, origin net.Addr, env smtpd.Envelope) (smtpd.Envelope, error) {
+	if srv.RequireAuth {
+		return env, &smtpd.SMTPError{Code: 530, Message: "5.7.0 Authentication required"}
+	}
+	var to []string
+	var rejected error
+	for _, rcpt := range env.To {
+		if !smtpd.DomainAllowed(rcpt, srv.RecipientDomains) {
+			log.Printf("Rejected recipient %s from %s: domain not allowed", rcpt, origin)
+			rejected = &smtpd.SMTPError{Code: 550, Message: "5.7.1 Recipient domain not allowed"}
+			continue
+		}
+		if srv.RcptHandler != nil {
+			if err := srv.RcptHandler(origin, env, rcpt); err != nil {
+				// Try the whole message again later rather than lose a recipient to a
+				// temporary rejection.
+				var smtpErr *smtpd.SMTPError
+				if !errors.As(err, &smtpErr) || smtpErr.Code < 500 {
+					return env, err
+				}
+				rejected = err
+				continue
+			}
+		}
+		to = append(to, rcpt)
+	}
+	if len(to) == 0 {
+		return env, rejected
+	}
+	env.To = to
+	return env, nil
+}
+
+// Route the messages in the spool directory as if they had just been submitted to the
+// sendmail listener, oldest first. A message is removed once it has been routed or rejected,
+// and left for the next attempt if it could not be handled.
+func ProcessSpool(dir string) {
+	files, err := filepath.Glob(filepath.Join(dir, "*"+SpoolExt))
+	if err != nil {
+		log.Printf("Could not read spool directory %s: %v", dir, err)
+		return
+	}
+	listener := SendmailListener()
+	srv, err := listener.Server(ConfigSnapshot())
+	if err != nil {
+		log.Printf("Could not process spool directory %s: %v", dir, err)
+		return
+	}
+	hostname, _ := os.Hostname()
+	for _, file := range files {
+		m, origin, err := readSpooledMessage(dir, file)
+		if err != nil {
+			log.Printf("Removing invalid spooled message %s: %v", file, err)
+			os.Remove(file)
+			continue
+		}
+		env, err := checkSpooledMessage(srv, origin, smtpd.Envelope{From: m.From, To: m.To, Listener: listener.Name})
+		if err == nil {
+			received := fmt.Sprintf("by %s (Mailrouter) id %s;\r\n\t%s", hostname, strings.TrimSuffix(filepath.Base(file), SpoolExt), time.Now().Format(time.RFC1123Z))
+			err = mailHandler(origin, env, PrependHeader(m.Data, "Received", received))
+		}
+		var smtpErr *smtpd.SMTPError
+		if err != nil && !(errors.As(err, &smtpErr) && smtpErr.Code >= 500) {
+			log.Printf("Could not route spooled message %s, will retry: %v", file, err)
+			continue
+		}
+		if err != nil {
+			log.Printf("Spooled message %s from %s (%s) was rejected: %v", file, m.From, origin, err)
+		}
+		os.Remove(file)
+	}
+}
+
+// Check the spool directory for messages now and then every SpoolPoll.
+func WatchSpool(dir string) {
+	for {
+		ProcessSpool(dir)
+		time.Sleep(SpoolPoll)
+	}
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"log"
+	"net"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/mhale/mailrouter/smtpd"
+)
+
+// Time a refused client stays on the dashboard's list of throttled clients.
+const ThrottleMemory = 10 * time.Minute
+
+// Limits on the connections and mail accepted from one IP address or network. Zero means no limit.
+type ClientLimits struct {
+	MaxConnections       int // Connections open at once
+	ConnectionsPerMinute int
+	MessagesPerMinute    int
+}
+
+// Connections and mail accepted from one IP address or network.
+type clientUsage struct {
+	limits      ClientLimits
+	connections int
+	connRate    *TokenBucket // Nil if there is no limit
+	msgRate     *TokenBucket
+}
+
+// Report whether the client has no open connections and has used none of its rate limits,
+// so forgetting it changes nothing.
+func (u *clientUsage) idle(now time.Time) bool {
+	if u.connections > 0 {
+		return false
+	}
+	for _, bucket := range []*TokenBucket{u.connRate, u.msgRate} {
+		if bucket != nil {
+			bucket.refill(now)
+			if bucket.Tokens < bucket.Capacity {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// A client that was refused for exceeding a limit.
+type ThrottledClient struct {
+	Client  string // IP address, or network such as "192.0.2.0/24"
+	Reason  string
+	Refused int // Connections, messages or recipients refused
+	Since   time.Time
+	Last    time.Time
+}
+
+// Limits on what each client IP address and network may send, and what each has used.
+type Throttle struct {
+	sync.Mutex
+	PerIP      ClientLimits
+	PerNetwork ClientLimits
+	IPv4Prefix int // Length of the prefix that groups addresses into networks, e.g. 24
+	IPv6Prefix int
+	usage      map[string]*clientUsage
+	throttled  map[string]*ThrottledClient
+	pruned     time.Time
+}
+
+// Set the limits for each IP address and network. Usage counted under the old limits is kept.
+func (t *Throttle) SetLimits(perIP ClientLimits, perNetwork ClientLimits, ipv4Prefix int, ipv6Prefix int) {
+	t.Lock()
+	defer t.Unlock()
+	t.PerIP, t.PerNetwork = perIP, perNetwork
+	t.IPv4Prefix, t.IPv6Prefix = ipv4Prefix, ipv6Prefix
+}
+
+// The network an address belongs to, e.g. "192.0.2.0/24".
+func (t *Throttle) network(ip net.IP) string {
+	bits, prefix := 128, t.IPv6Prefix
+	if ip4 := ip.To4(); ip4 != nil {
+		ip, bits, prefix = ip4, 32, t.IPv4Prefix
+	}
+	if prefix <= 0 || prefix > bits {
+		prefix = bits
+	}
+	return ip.Mask(net.CIDRMask(prefix, bits)).String() + "/" + strconv.Itoa(prefix)
+}
+
+// The clients an address counts as, with the limits of each. The caller must hold the lock.
+func (t *Throttle) clients(ip net.IP, now time.Time) map[string]*clientUsage {
+	return map[string]*clientUsage{
+		ip.String():   t.client(ip.String(), t.PerIP, now),
+		t.network(ip): t.client(t.network(ip), t.PerNetwork, now),
+	}
+}
+
+// Return the usage of a client, starting afresh if its limits have changed. The caller must hold the lock.
+func (t *Throttle) client(key string, limits ClientLimits, now time.Time) *clientUsage {
+	if t.usage == nil {
+		t.usage = map[string]*clientUsage{}
+	}
+	u, exists := t.usage[key]
+	if exists && u.limits == limits {
+		return u
+	}
+	connections := 0
+	if exists {
+		connections = u.connections
+	}
+	u = &clientUsage{limits: limits, connections: connections}
+	if limits.ConnectionsPerMinute > 0 {
+		u.connRate = NewTokenBucket(float64(limits.ConnectionsPerMinute), float64(limits.ConnectionsPerMinute)/60, now)
+	}
+	if limits.MessagesPerMinute > 0 {
+		u.msgRate = NewTokenBucket(float64(limits.MessagesPerMinute), float64(limits.MessagesPerMinute)/60, now)
+	}
+	t.usage[key] = u
+	return u
+}
+
+// Record that a client was refused. The caller must hold the lock.
+func (t *Throttle) refuse(client string, reason string, now time.Time) {
+	if t.throttled == nil {
+		t.throttled = map[string]*ThrottledClient{}
+	}
+	c, exists := t.throttled[client]
+	if !exists || now.Sub(c.Last) > ThrottleMemory {
+		log.Printf("Throttling %s: %s.", client, reason)
+		c = &ThrottledClient{Client: client, Since: now}
+		t.throttled[client] = c
+	}
+	c.Reason = reason
+	c.Refused++
+	c.Last = now
+}
+
+// Forget clients that are idle and refusals that are too old to show. The caller must hold the lock.
+func (t *Throttle) prune(now time.Time) {
+	if now.Sub(t.pruned) < time.Minute {
+		return
+	}
+	t.pruned = now
+	for key, u := range t.usage {
+		if u.idle(now) {
+			delete(t.usage, key)
+		}
+	}
+	for key, c := range t.throttled {
+		if now.Sub(c.Last) > ThrottleMemory {
+			delete(t.throttled, key)
+		}
+	}
+}
+
+// Accept a new connection from an address, or return a 421 error if it would exceed the limits
+// of the address or its network. An accepted connection must be ended with Disconnect.
+func (t *Throttle) Connect(ip net.IP, now time.Time) error {
+	t.Lock()
+	defer t.Unlock()
+	t.prune(now)
+
+	clients := t.clients(ip, now)
+	for key, u := range clients {
+		if u.limits.MaxConnections > 0 && u.connections >= u.limits.MaxConnections {
+			t.refuse(key, "too many connections", now)
+			return &smtpd.SMTPError{Code: 421, Message: "4.7.0 Too many connections from your address, try again later"}
+		}
+		if u.connRate != nil && u.connRate.Wait(1, now) > 0 {
+			t.refuse(key, "too many connections per minute", now)
+			return &smtpd.SMTPError{Code: 421, Message: "4.7.0 Connection rate limit exceeded, try again later"}
+		}
+	}
+	for _, u := range clients {
+		u.connections++
+		if u.connRate != nil {
+			u.connRate.Take(1, now)
+		}
+	}
+	return nil
+}
+
+// End a connection accepted by Connect.
+func (t *Throttle) Disconnect(ip net.IP) {
+	t.Lock()
+	defer t.Unlock()
+	for _, key := range []string{ip.String(), t.network(ip)} {
+		if u, exists := t.usage[key]; exists && u.connections > 0 {
+			u.connections--
+		}
+	}
+}
+
+// Accept a new message from an address, or return a 452 error if it would exceed the message
+// rate of the address or its network.
+func (t *Throttle) Message(ip net.IP, now time.Time) error {
+	t.Lock()
+	defer t.Unlock()
+
+	clients := t.clients(ip, now)
+	for key, u := range clients {
+		if u.msgRate != nil && u.msgRate.Wait(1, now) > 0 {
+			t.refuse(key, "too many messages per minute", now)
+			return &smtpd.SMTPError{Code: 452, Message: "4.7.0 Message rate limit exceeded, try again later"}
+		}
+	}
+	for _, u := range clients {
+		if u.msgRate != nil {
+			u.msgRate.Take(1, now)
+		}
+	}
+	return nil
+}
+
+// Record that an address was refused a recipient over the per message limit.
+func (t *Throttle) TooManyRecipients(ip net.IP, now time.Time) {
+	t.Lock()
+	defer t.Unlock()
+	t.refuse(ip.String(), "too many recipients per message", now)
+}
+
+// List the clients refused recently, most recent first.
+func (t *Throttle) Throttled(now time.Time) []ThrottledClient {
+	t.Lock()
+	defer t.Unlock()
+	var list []ThrottledClient
+	for _, c := range t.throttled {
+		if now.Sub(c.Last) <= ThrottleMemory {
+			list = append(list, *c)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Last.After(list[j].Last) })
+	return list
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"net"
+	"net/textproto"
+	"testing"
+	"time"
+
+	"github.com/mhale/mailrouter/smtpd"
+)
+
+func TestThrottleConnect(t *testing.T) {
+	now := time.Now()
+	var throttle Throttle
+	throttle.SetLimits(ClientLimits{MaxConnections: 2, ConnectionsPerMinute: 3}, ClientLimits{MaxConnections: 3}, 24, 64)
+
+	tests := []struct {
+		ip   string
+		code int // Zero if the connection is accepted
+	}{
+		{"192.0.2.1", 0},
+		{"192.0.2.1", 0},
+		{"192.0.2.1", 421}, // Too many connections from the address
+		{"192.0.2.2", 0},
+		{"192.0.2.3", 421}, // Too many connections from the network
+		{"198.51.100.1", 0},
+		{"2001:db8::1", 0},
+	}
+	for _, tt := range tests {
+		code := 0
+		if err := throttle.Connect(net.ParseIP(tt.ip), now); err != nil {
+			code = err.(*smtpd.SMTPError).Code
+		}
+		if code != tt.code {
+			t.Errorf("Connect(%s) = %d, want %d", tt.ip, code, tt.code)
+		}
+	}
+
+	// Closing a connection frees a slot, but the address has used its connections for this minute.
+	throttle.Disconnect(net.ParseIP("192.0.2.1"))
+	throttle.Disconnect(net.ParseIP("192.0.2.1"))
+	if err := throttle.Connect(net.ParseIP("192.0.2.1"), now); err != nil {
+		t.Errorf("Connect(192.0.2.1) after Disconnect = %v, want accepted", err)
+	}
+	throttle.Disconnect(net.ParseIP("192.0.2.1"))
+	if err := throttle.Connect(net.ParseIP("192.0.2.1"), now); err == nil {
+		t.Errorf("Connect(192.0.2.1) over 3 per minute accepted, want 421")
+	}
+	if err := throttle.Connect(net.ParseIP("192.0.2.1"), now.Add(time.Minute)); err != nil {
+		t.Errorf("Connect(192.0.2.1) a minute later = %v, want accepted", err)
+	}
+
+	got := map[string]string{}
+	for _, c := range throttle.Throttled(now.Add(time.Minute)) {
+		got[c.Client] = c.Reason
+	}
+	want := map[string]string{"192.0.2.1": "too many connections per minute", "192.0.2.0/24": "too many connections"}
+	if len(got) != len(want) || got["192.0.2.1"] != want["192.0.2.1"] || got["192.0.2.0/24"] != want["192.0.2.0/24"] {
+		t.Errorf("Throttled() = %v, want %v", got, want)
+	}
+	if list := throttle.Throttled(now.Add(ThrottleMemory + 2*time.Minute)); len(list) != 0 {
+		t.Errorf("Throttled() after %v = %v, want none", ThrottleMemory, list)
+	}
+}
+
+func TestThrottleNetwork(t *testing.T) {
+	throttle := Throttle{IPv4Prefix: 24, IPv6Prefix: 48}
+	tests := []struct {
+		ip   string
+		want string
+	}{
+		{"192.0.2.77", "192.0.2.0/24"},
+		{"::ffff:192.0.2.77", "192.0.2.0/24"},
+		{"2001:db8:1:2::3", "2001:db8:1::/48"},
+	}
+	for _, tt := range tests {
+		if got := throttle.network(net.ParseIP(tt.ip)); got != tt.want {
+			t.Errorf("network(%s) = %s, want %s", tt.ip, got, tt.want)
+		}
+	}
+}
+
+func TestServerThrottle(t *testing.T) {
+	var throttle Throttle
+	throttle.SetLimits(ClientLimits{MaxConnections: 1, MessagesPerMinute: 1}, ClientLimits{}, 24, 64)
+	srv := &smtpd.Server{Addr: "127.0.0.1:0", Hostname: "mx.test", MaxRecipients: 2, Throttle: &throttle}
+	ln, err := srv.Listen()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.Serve(ln)
+	t.Cleanup(func() { ln.Close() })
+	addr := ln.Addr().String()
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	text := textproto.NewConn(conn)
+	text.ReadResponse(220)
+
+	second, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer second.Close()
+	if code, msg, _ := textproto.NewConn(second).ReadResponse(0); code != 421 {
+		t.Errorf("second connection greeting = %d %s, want 421", code, msg)
+	}
+
+	tests := []struct {
+		cmd  string
+		code int
+	}{
+		{"EHLO client.example.com", 250},
+		{"MAIL FROM:<sender@example.com>", 250},
+		{"RCPT TO:<a@example.com>", 250},
+		{"RCPT TO:<b@example.com>", 250},
+		{"RCPT TO:<c@example.com>", 452},
+		{"RSET", 250},
+		{"MAIL FROM:<sender@example.com>", 452},
+	}
+	for _, tt := range tests {
+		text.PrintfLine("%s", tt.cmd)
+		if code, msg, _ := text.ReadResponse(0); code != tt.code {
+			t.Errorf("%s = %d %s, want %d", tt.cmd, code, msg, tt.code)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Webhook body formats.
+const (
+	WebhookRaw  = "raw"
+	WebhookJSON = "json"
+)
+
+// Timeout for webhook requests when the route does not set one.
+const WebhookTimeout = 30 * time.Second
+
+// Headers carrying the HMAC signature of a webhook request and the time it was signed.
+const (
+	WebhookSignatureHeader = "X-Mailrouter-Signature"
+	WebhookTimestampHeader = "X-Mailrouter-Timestamp"
+)
+
+// Maximum length of a webhook response body included in error messages.
+const WebhookMaxResponse = 200
+
+// The JSON document posted by webhook routes.
+type WebhookPayload struct {
+	Envelope    WebhookEnvelope     `json:"envelope"`
+	Headers     map[string][]string `json:"headers"`
+	Subject     string              `json:"subject"`
+	Text        string              `json:"text"`
+	HTML        string              `json:"html"`
+	Attachments []WebhookAttachment `json:"attachments"`
+}
+
+type WebhookEnvelope struct {
+	From   string   `json:"from"`
+	To     []string `json:"to"`
+	Origin string   `json:"origin,omitempty"`
+	Filter string   `json:"filter,omitempty"`
+	Route  string   `json:"route"`
+}
+
+type WebhookAttachment struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	ContentID   string `json:"content_id,omitempty"`
+	Size        int    `json:"size"`
+	Content     []byte `json:"content"` // Encoded as base64
+}
+
+// POST a message to the route's URL, either as the raw message or as a JSON document.
+// A 2xx response is success. A 5xx or 429 response, or no response at all, is a temporary
+// failure, which is retried. Any other response is a permanent failure.
+func deliverWebhook(route Route, msg Message) error {
+	body, contentType, err := WebhookBody(route, msg)
+	if err != nil {
+		return fmt.Errorf("route %s: %w", route.Name, &DeliveryError{Msg: err.Error(), Err: err, Permanent: true})
+	}
+
+	req, err := http.NewRequest("POST", route.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("route %s: %w", route.Name, &DeliveryError{Msg: err.Error(), Err: err, Permanent: true})
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("User-Agent", "Mailrouter")
+	for name, value := range route.Headers {
+		req.Header.Set(name, value)
+	}
+	if route.Secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(WebhookTimestampHeader, timestamp)
+		req.Header.Set(WebhookSignatureHeader, WebhookSignature(route.Secret, timestamp, body))
+	}
+
+	timeout := WebhookTimeout
+	if route.Timeout > 0 {
+		timeout = time.Duration(route.Timeout) * time.Second
+	}
+	client := http.Client{Timeout: timeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("route %s: %w", route.Name, &DeliveryError{Msg: err.Error(), Err: err})
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, WebhookMaxResponse))
+	text := resp.Status
+	if s := strings.TrimSpace(string(detail)); s != "" {
+		text += ": " + s
+	}
+	return fmt.Errorf("route %s (%s): %w", route.Name, route.URL, &DeliveryError{
+		Msg:       text,
+		Permanent: resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests,
+	})
+}
+
+// Build the request body for a webhook route and return it with its content type.
+func WebhookBody(route Route, msg Message) ([]byte, string, error) {
+	if route.Format != WebhookJSON {
+		return msg.Data, "message/rfc822", nil
+	}
+
+	parsed, err := ParseMessage(msg.Data)
+	if err != nil {
+		return nil, "", fmt.Errorf("cannot parse message: %v", err)
+	}
+	payload := WebhookPayload{
+		Envelope: WebhookEnvelope{
+			From:   msg.From,
+			To:     msg.To,
+			Filter: msg.Filter,
+			Route:  route.Name,
+		},
+		Headers:     parsed.Header,
+		Subject:     DecodeHeader(parsed.Header.Get("Subject")),
+		Text:        parsed.Text,
+		HTML:        parsed.HTML,
+		Attachments: []WebhookAttachment{},
+	}
+	if msg.Origin != nil {
+		payload.Envelope.Origin = msg.Origin.String()
+	}
+	for _, a := range parsed.Attachments {
+		payload.Attachments = append(payload.Attachments, WebhookAttachment{
+			Filename:    a.Filename,
+			ContentType: a.ContentType,
+			ContentID:   a.ContentID,
+			Size:        len(a.Data),
+			Content:     a.Data,
+		})
+	}
+	body, err := json.Marshal(payload)
+	return body, "application/json", err
+}
+
+// Calculate the signature of a webhook request: the hex HMAC-SHA256 of the timestamp, a dot and the body.
+// Receivers should recompute it and reject requests with old timestamps to prevent replays.
+func WebhookSignature(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Parse request headers entered one per line as "Name: value".
+func ParseHeaders(text string) (map[string]string, error) {
+	headers := map[string]string{}
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		i := strings.Index(line, ":")
+		if i < 1 {
+			return nil, fmt.Errorf("invalid header %q", line)
+		}
+		headers[http.CanonicalHeaderKey(strings.TrimSpace(line[:i]))] = strings.TrimSpace(line[i+1:])
+	}
+	return headers, nil
+}
+
+// Format request headers one per line, sorted by name, for editing.
+func FormatHeaders(headers map[string]string) string {
+	var lines []string
+	for name, value := range headers {
+		lines = append(lines, name+": "+value)
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "\n")
+}
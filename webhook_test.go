@@ -0,0 +1,141 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDeliverWebhook(t *testing.T) {
+	var requests []*http.Request
+	var bodies [][]byte
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		requests = append(requests, req)
+		bodies = append(bodies, body)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	route := Route{Name: "hook", Type: RouteWebhook, URL: server.URL, Secret: "key", Headers: map[string]string{"Authorization": "Bearer token"}}
+	msg := Message{From: "sender@example.com", To: []string{"recipient@example.com"}, Data: []byte(testMultipart), Origin: net.ParseIP("10.0.0.1")}
+
+	if _, err := Deliver(route, msg); err != nil {
+		t.Fatalf("Deliver(raw) = %v, want success", err)
+	}
+	req, body := requests[0], bodies[0]
+	if string(body) != testMultipart || req.Header.Get("Content-Type") != "message/rfc822" {
+		t.Errorf("raw request = %s %q, want message/rfc822 with the message", req.Header.Get("Content-Type"), body)
+	}
+	if req.Header.Get("Authorization") != "Bearer token" {
+		t.Errorf("Authorization = %q, want %q", req.Header.Get("Authorization"), "Bearer token")
+	}
+	want := WebhookSignature("key", req.Header.Get(WebhookTimestampHeader), body)
+	if sig := req.Header.Get(WebhookSignatureHeader); sig != want {
+		t.Errorf("signature = %q, want %q", sig, want)
+	}
+
+	route.Format = WebhookJSON
+	if _, err := Deliver(route, msg); err != nil {
+		t.Fatalf("Deliver(json) = %v, want success", err)
+	}
+	var payload WebhookPayload
+	if err := json.Unmarshal(bodies[1], &payload); err != nil {
+		t.Fatalf("json.Unmarshal() = %v", err)
+	}
+	if payload.Envelope.From != msg.From || !reflect.DeepEqual(payload.Envelope.To, msg.To) || payload.Envelope.Origin != "10.0.0.1" {
+		t.Errorf("envelope = %+v, want %s to %v from 10.0.0.1", payload.Envelope, msg.From, msg.To)
+	}
+	if payload.Subject != "Report – June" || payload.Text != "Café report" || payload.HTML != "<p>Report</p>" {
+		t.Errorf("payload = %q %q %q, want decoded subject and bodies", payload.Subject, payload.Text, payload.HTML)
+	}
+	if len(payload.Attachments) != 1 || string(payload.Attachments[0].Content) != "%PDF-1.4\n" || payload.Attachments[0].Size != 9 {
+		t.Errorf("attachments = %+v, want report.pdf", payload.Attachments)
+	}
+	if payload.Headers["To"][0] != "recipient@example.com" {
+		t.Errorf("headers = %v, want To recipient@example.com", payload.Headers)
+	}
+
+	tests := []struct {
+		status    int
+		temporary bool
+	}{
+		{http.StatusServiceUnavailable, true},
+		{http.StatusTooManyRequests, true},
+		{http.StatusBadRequest, false},
+		{http.StatusNotFound, false},
+	}
+	for _, tt := range tests {
+		status = tt.status
+		_, err := Deliver(route, msg)
+		if err == nil || IsTemporary(err) != tt.temporary {
+			t.Errorf("Deliver() with status %d = %v, want temporary %v", tt.status, err, tt.temporary)
+		}
+	}
+
+	server.Close()
+	if _, err := Deliver(route, msg); err == nil || !IsTemporary(err) {
+		t.Errorf("Deliver() to closed server = %v, want temporary failure", err)
+	}
+}
+
+func TestRetryWebhook(t *testing.T) {
+	var mu sync.Mutex
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		// Unavailable for the first two requests, then recovers.
+		requests++
+		if requests <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+	useTestCapture(t)
+	useTestRetries(t, 10*time.Millisecond, time.Hour)
+	useTestRoutes(t,
+		Route{Id: "DROP", Name: "Drop"},
+		Route{Id: "bounces", Name: "Bounces", Type: RouteCapture},
+		Route{Id: "hook", Name: "Hook", Type: RouteWebhook, URL: server.URL},
+	)
+	useTestOptions(t, map[string]string{"BounceRoute": "bounces"})
+
+	RouteMessage(Message{From: "sender@example.com", To: []string{"rcpt@example.com"}, Data: []byte("Subject: Hello\r\n\r\nHi.\r\n")}, "hook", 0)
+	if !waitFor(func() bool { return recentLogs()[0].Status == "Sent" }) {
+		t.Fatalf("message was not delivered once the webhook recovered")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if requests != 3 {
+		t.Errorf("webhook received %d requests, want 3", requests)
+	}
+	if l := recentLogs()[1]; l.Route != "Hook" || l.Status != "Deferred" || !strings.Contains(l.Error, "503") {
+		t.Errorf("failed attempt log = %+v, want Deferred with 503", l)
+	}
+	if n := len(captured.Search("")); n != 0 {
+		t.Errorf("sent %d bounces for a message that was delivered on retry, want 0", n)
+	}
+}
+
+func TestParseHeaders(t *testing.T) {
+	headers, err := ParseHeaders("authorization: Bearer a:b\n\n  X-Env : test \n")
+	want := map[string]string{"Authorization": "Bearer a:b", "X-Env": "test"}
+	if !reflect.DeepEqual(headers, want) || err != nil {
+		t.Errorf("ParseHeaders() = %v, %v, want %v", headers, err, want)
+	}
+	if text := FormatHeaders(headers); text != "Authorization: Bearer a:b\nX-Env: test" {
+		t.Errorf("FormatHeaders() = %q", text)
+	}
+	if _, err := ParseHeaders("no colon"); err == nil {
+		t.Errorf("ParseHeaders(no colon) succeeded, want error")
+	}
+}